@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"driftive/pkg/config"
+	"driftive/pkg/config/discover"
+	"driftive/pkg/config/repo"
+	"driftive/pkg/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// listedProject is a project as printed by `driftive list`.
+type listedProject struct {
+	Dir           string   `json:"dir"`
+	Type          string   `json:"type"`
+	Workspace     string   `json:"workspace,omitempty"`
+	AllWorkspaces bool     `json:"all_workspaces,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	Owners        []string `json:"owners,omitempty"`
+	Severity      string   `json:"severity,omitempty"`
+	DependsOn     []string `json:"depends_on,omitempty"`
+	// Origin is where the project comes from, e.g. "rule *.tf".
+	Origin string `json:"origin"`
+}
+
+type listedFile struct {
+	Path     string `json:"path"`
+	Included bool   `json:"included"`
+	Reason   string `json:"reason"`
+}
+
+type listing struct {
+	Projects []listedProject `json:"projects"`
+	// Files are the files an inclusion matched, with the pattern that kept or excluded each.
+	Files []listedFile `json:"files"`
+	// UnmatchedDirs are candidate dirs no project rule matched.
+	UnmatchedDirs []string `json:"unmatched_dirs"`
+}
+
+// runList discovers the repository's projects and prints them, without planning anything.
+func runList(ctx context.Context, cfg *config.ListConfig) {
+	// Logs go to stderr so they cannot corrupt the listing.
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: ""})
+
+	repoDir, shouldDelete := determineRepositoryDir(ctx, cfg.RepositoryUrl, cfg.RepositoryPath, cfg.Branch)
+	if shouldDelete {
+		defer os.RemoveAll(repoDir)
+	}
+
+	repoConfig, err := repo.DetectRepoConfig(repoDir)
+	if err != nil && !errors.Is(err, repo.ErrMissingRepoConfig) {
+		log.Fatal().Msgf("Failed to load repository config. %v", err)
+	}
+	repoConfig = repo.RepoConfigOrDefault(repoConfig)
+	repo.ValidateRepoConfig(repoConfig)
+
+	result := newListing(repoDir, discover.Discover(repoDir, repoConfig))
+	if cfg.Output == config.OutputJSON {
+		err = writeListingJSON(os.Stdout, result)
+	} else {
+		err = writeListingTable(os.Stdout, result)
+	}
+	if err != nil {
+		log.Fatal().Msgf("Failed to print the projects. %v", err)
+	}
+}
+
+func newListing(repoDir string, discovery discover.Discovery) listing {
+	result := listing{
+		Projects:      make([]listedProject, 0, len(discovery.Projects)),
+		Files:         make([]listedFile, 0, len(discovery.Files)),
+		UnmatchedDirs: make([]string, 0, len(discovery.Unmatched)),
+	}
+	for _, dir := range discovery.Unmatched {
+		result.UnmatchedDirs = append(result.UnmatchedDirs, filepath.ToSlash(dir))
+	}
+	for _, project := range discovery.Projects {
+		dependsOn := make([]string, 0, len(project.Settings.DependsOn))
+		for _, dep := range project.Settings.DependsOn {
+			dependsOn = append(dependsOn, relativeDir(repoDir, dep))
+		}
+		result.Projects = append(result.Projects, listedProject{
+			Dir:           relativeDir(repoDir, project.Dir),
+			Type:          models.ProjectTypeToExecutable(project.Type),
+			Workspace:     project.Workspace,
+			AllWorkspaces: project.Settings.AllWorkspaces,
+			Name:          project.Name,
+			Tags:          project.Tags,
+			Owners:        project.Owners,
+			Severity:      project.Severity,
+			DependsOn:     dependsOn,
+			Origin:        discovery.Origin(project),
+		})
+	}
+	for _, file := range discovery.Files {
+		result.Files = append(result.Files, listedFile{Path: filepath.ToSlash(file.Path), Included: file.Included, Reason: file.Reason})
+	}
+	return result
+}
+
+func relativeDir(repoDir, dir string) string {
+	rel, err := filepath.Rel(repoDir, dir)
+	if err != nil {
+		return dir
+	}
+	return filepath.ToSlash(rel)
+}
+
+func writeListingJSON(w io.Writer, result listing) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+func writeListingTable(w io.Writer, result listing) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIR\tTYPE\tWORKSPACE\tNAME\tORIGIN")
+	for _, p := range result.Projects {
+		workspace := p.Workspace
+		if p.AllWorkspaces {
+			workspace = "(all)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", p.Dir, p.Type, orDash(workspace), orDash(p.Name), p.Origin)
+	}
+	fmt.Fprintf(tw, "%d projects\n", len(result.Projects))
+
+	if len(result.Files) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "FILE\tDECISION")
+		for _, f := range result.Files {
+			fmt.Fprintf(tw, "%s\t%s\n", f.Path, f.Reason)
+		}
+	}
+	if len(result.UnmatchedDirs) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Candidate dirs matching no project rule:")
+		for _, dir := range result.UnmatchedDirs {
+			fmt.Fprintf(tw, "  - %s\n", dir)
+		}
+	}
+	return tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
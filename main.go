@@ -88,6 +88,15 @@ func startLiveReporter(ctx context.Context, cfg *config.DriftiveConfig, detector
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "list" {
+		runList(context.Background(), config.ParseListConfig(os.Args[2:]))
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		runValidate(config.ParseValidateConfig(os.Args[2:]))
+		return
+	}
+
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: ""})
 	cfg := config.ParseConfig(version)
 	ctx := context.Background()
@@ -116,10 +125,12 @@ func main() {
 	projects := discover.AutoDiscoverProjects(repoDir, repoConfig)
 	log.Info().Msgf("Projects detected: %d", len(projects))
 	driftDetector := drift.NewDriftDetector(repoDir, projects, cfg, repoConfig, openIssues, changedFiles)
+	// Expanded up front so the live reporter is told the final project count.
+	driftDetector.ExpandWorkspaces(ctx)
 
 	// One key identifies this run to the API across every progress post and the terminal upload.
 	runKey := uuid.NewString()
-	liveReporter := startLiveReporter(ctx, cfg, &driftDetector, runKey, len(projects))
+	liveReporter := startLiveReporter(ctx, cfg, &driftDetector, runKey, len(driftDetector.Projects))
 
 	analysisResult := driftDetector.DetectDrift(ctx)
 
@@ -128,7 +139,7 @@ func main() {
 		liveReporter.Stop()
 	}
 
-	notification.NewNotificationHandler(cfg, repoConfig, scmOps, runKey).
+	notification.NewNotificationHandler(cfg, repoConfig, scmOps, repoDir, runKey).
 		HandleNotifications(ctx, analysisResult)
 
 	if analysisResult.TotalDrifted <= 0 {
@@ -147,10 +158,14 @@ func parseOnOff(enabled bool) string {
 
 func showInitMessage(cfg *config.DriftiveConfig, repoConfig *repo.DriftiveRepoConfig) {
 	log.Info().Msg("Starting driftive...")
-	log.Info().Msgf("Options: concurrency: %d. github issues: %s. slack: %s. close resolved issues: %s. max opened issues: %d",
+	log.Info().Msgf("Options: concurrency: %d. github issues: %s. slack: %s. teams: %s. discord: %s. mattermost: %s. webhooks: %d. close resolved issues: %s. max opened issues: %d",
 		cfg.Concurrency,
 		parseOnOff(repoConfig.GitHub.Issues.Enabled),
 		parseOnOff(cfg.SlackWebhookUrl != ""),
+		parseOnOff(cfg.TeamsWebhookUrl != ""),
+		parseOnOff(cfg.DiscordWebhookUrl != ""),
+		parseOnOff(cfg.MattermostWebhookUrl != ""),
+		len(cfg.WebhookUrls),
 		parseOnOff(repoConfig.GitHub.Issues.CloseResolved),
 		repoConfig.GitHub.Issues.MaxOpenIssues)
 
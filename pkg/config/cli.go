@@ -3,6 +3,7 @@ package config
 import (
 	"driftive/pkg/gh"
 	"driftive/pkg/utils"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -36,6 +37,18 @@ func parseDriftiveToken() string {
 	return token
 }
 
+// stringList is a flag that can be repeated, collecting every value.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 // resolvedVersion returns the version to display. The compile-time value wins;
 // otherwise we fall back to module info populated by `go install`.
 func resolvedVersion(compileTime string) string {
@@ -58,18 +71,23 @@ func setUsage() {
 		fmt.Fprintln(out, "Usage:")
 		fmt.Fprintln(out, "  driftive --repo-path <path> [flags]")
 		fmt.Fprintln(out, "  driftive --repo-url <url> --branch <branch> [flags]")
+		fmt.Fprintln(out, "  driftive list --repo-path <path> [--output table|json]")
+		fmt.Fprintln(out, "  driftive validate [--repo-path <path>] [--config <file>] [--schema]")
 		fmt.Fprintln(out, "  driftive --version")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Flags:")
 		flag.PrintDefaults()
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Environment variables:")
-		fmt.Fprintln(out, "  DRIFTIVE_TOKEN   Bearer token for reporting results to Driftive Cloud.")
-		fmt.Fprintln(out, "  GITHUB_CONTEXT   GitHub Actions context JSON (auto-set inside Actions).")
+		fmt.Fprintln(out, "  DRIFTIVE_TOKEN           Bearer token for reporting results to Driftive Cloud.")
+		fmt.Fprintln(out, "  DRIFTIVE_WEBHOOK_SECRET  Key of the HMAC-SHA256 signature of --webhook-url requests.")
+		fmt.Fprintln(out, "  GITHUB_CONTEXT           GitHub Actions context JSON (auto-set inside Actions).")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Examples:")
 		fmt.Fprintln(out, "  driftive --repo-path ./my-tf-repo")
 		fmt.Fprintln(out, "  driftive --repo-url https://token@github.com/org/repo --branch main")
+		fmt.Fprintln(out, "  driftive list --repo-path ./my-tf-repo --output json")
+		fmt.Fprintln(out, "  driftive validate --repo-path ./my-tf-repo")
 		fmt.Fprintln(out, "  driftive --version")
 		fmt.Fprintln(out)
 		fmt.Fprintln(out, "Project discovery and notification routing are configured via driftive.yml")
@@ -80,6 +98,10 @@ func setUsage() {
 func ParseConfig(version string) *DriftiveConfig {
 	var repositoryUrl string
 	var slackWebhookUrl string
+	var teamsWebhookUrl string
+	var discordWebhookUrl string
+	var mattermostWebhookUrl string
+	var webhookUrls stringList
 	var branch string
 	var repositoryPath string
 	var concurrency int
@@ -96,6 +118,10 @@ func ParseConfig(version string) *DriftiveConfig {
 	flag.StringVar(&repositoryUrl, "repo-url", "", "e.g. https://<token>@github.com/<org>/<repo>. If repo-path is provided, this is ignored.")
 	flag.StringVar(&branch, "branch", "", "Repository branch")
 	flag.StringVar(&slackWebhookUrl, "slack-url", "", "Slack webhook URL")
+	flag.StringVar(&teamsWebhookUrl, "teams-url", "", "Microsoft Teams webhook URL")
+	flag.StringVar(&discordWebhookUrl, "discord-url", "", "Discord webhook URL")
+	flag.StringVar(&mattermostWebhookUrl, "mattermost-url", "", "Mattermost incoming webhook URL")
+	flag.Var(&webhookUrls, "webhook-url", "URL to POST the JSON results of every run to. Can be repeated.")
 	flag.IntVar(&concurrency, "concurrency", 4, "Number of concurrent projects to check. Defaults to 4.")
 	flag.StringVar(&logLevel, "log-level", "info", "Log level. Options: trace, debug, info, warn, error, fatal, panic")
 	flag.BoolVar(&enableStdoutResult, "stdout", true, "Enable printing drift results to stdout")
@@ -129,17 +155,93 @@ func ParseConfig(version string) *DriftiveConfig {
 	driftiveToken := parseDriftiveToken()
 
 	return &DriftiveConfig{
-		RepositoryUrl:      repositoryUrl,
-		Branch:             branch,
-		RepositoryPath:     strings.TrimSuffix(repositoryPath, utils.PathSeparator),
-		Concurrency:        concurrency,
-		LogLevel:           logLevel,
-		EnableStdoutResult: enableStdoutResult,
-		SlackWebhookUrl:    slackWebhookUrl,
-		GithubToken:        githubToken,
-		GithubContext:      ghContext,
-		ExitCode:           exitCode,
-		DriftiveApiUrl:     driftiveApiUrl,
-		DriftiveToken:      driftiveToken,
+		RepositoryUrl:        repositoryUrl,
+		Branch:               branch,
+		RepositoryPath:       strings.TrimSuffix(repositoryPath, utils.PathSeparator),
+		Concurrency:          concurrency,
+		LogLevel:             logLevel,
+		EnableStdoutResult:   enableStdoutResult,
+		SlackWebhookUrl:      slackWebhookUrl,
+		TeamsWebhookUrl:      teamsWebhookUrl,
+		DiscordWebhookUrl:    discordWebhookUrl,
+		MattermostWebhookUrl: mattermostWebhookUrl,
+		WebhookUrls:          webhookUrls,
+		WebhookSecret:        os.Getenv("DRIFTIVE_WEBHOOK_SECRET"),
+		GithubToken:          githubToken,
+		GithubContext:        ghContext,
+		ExitCode:             exitCode,
+		DriftiveApiUrl:       driftiveApiUrl,
+		DriftiveToken:        driftiveToken,
+	}
+}
+
+// ParseListConfig parses the flags of `driftive list`, args being the arguments after "list".
+func ParseListConfig(args []string) *ListConfig {
+	var cfg ListConfig
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintln(out, "driftive list — print the projects driftive would analyze, without planning anything.")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintln(out, "  driftive list --repo-path <path> [flags]")
+		fmt.Fprintln(out, "  driftive list --repo-url <url> --branch <branch> [flags]")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Flags:")
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&cfg.RepositoryPath, "repo-path", "", "Path to the repository. If provided, the repository will not be cloned.")
+	flags.StringVar(&cfg.RepositoryUrl, "repo-url", "", "e.g. https://<token>@github.com/<org>/<repo>. If repo-path is provided, this is ignored.")
+	flags.StringVar(&cfg.Branch, "branch", "", "Repository branch")
+	flags.StringVar(&cfg.LogLevel, "log-level", "warn", "Log level. Options: trace, debug, info, warn, error, fatal, panic")
+	flags.StringVar(&cfg.Output, "output", OutputTable, "Output format. Options: table, json")
+	_ = flags.Parse(args)
+
+	if err := validateListArgs(&cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "driftive list: %s\n\n", err)
+		flags.Usage()
+		os.Exit(2)
+	}
+	zerolog.SetGlobalLevel(utils.ParseLogLevel(cfg.LogLevel))
+	cfg.RepositoryPath = strings.TrimSuffix(cfg.RepositoryPath, utils.PathSeparator)
+	return &cfg
+}
+
+func validateListArgs(cfg *ListConfig) error {
+	switch {
+	case cfg.RepositoryUrl == "" && cfg.RepositoryPath == "":
+		return errors.New("either --repo-path or --repo-url is required")
+	case cfg.Branch == "" && cfg.RepositoryPath == "":
+		return errors.New("--branch is required when --repo-url is provided")
+	case cfg.Output != OutputTable && cfg.Output != OutputJSON:
+		return fmt.Errorf("invalid --output %q: use table or json", cfg.Output)
 	}
+	return nil
+}
+
+// ParseValidateConfig parses the flags of `driftive validate`, args being the arguments after
+// "validate".
+func ParseValidateConfig(args []string) *ValidateConfig {
+	var cfg ValidateConfig
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintln(out, "driftive validate — check driftive.yml and list every problem in it.")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Usage:")
+		fmt.Fprintln(out, "  driftive validate [--repo-path <path>] [--config <file>]")
+		fmt.Fprintln(out, "  driftive validate --schema")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Flags:")
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&cfg.RepositoryPath, "repo-path", ".", "Path to the repository whose driftive.yml is validated")
+	flags.StringVar(&cfg.ConfigFile, "config", "", "Config file to validate. Defaults to driftive.yml or driftive.yaml in --repo-path.")
+	flags.BoolVar(&cfg.PrintSchema, "schema", false, "Print the JSON Schema of driftive.yml and exit")
+	_ = flags.Parse(args)
+
+	cfg.RepositoryPath = strings.TrimSuffix(cfg.RepositoryPath, utils.PathSeparator)
+	return &cfg
 }
@@ -32,3 +32,24 @@ func TestResolvedVersion(t *testing.T) {
 		}
 	})
 }
+
+func TestValidateListArgs(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     ListConfig
+		wantErr bool
+	}{
+		{"repo path", ListConfig{RepositoryPath: ".", Output: OutputTable}, false},
+		{"repo url with branch", ListConfig{RepositoryUrl: "https://github.com/org/repo", Branch: "main", Output: OutputJSON}, false},
+		{"no repository", ListConfig{Output: OutputTable}, true},
+		{"repo url without branch", ListConfig{RepositoryUrl: "https://github.com/org/repo", Output: OutputTable}, true},
+		{"unknown output", ListConfig{RepositoryPath: ".", Output: "yaml"}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateListArgs(&tc.cfg); (err != nil) != tc.wantErr {
+				t.Errorf("validateListArgs() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package discover
+
+import (
+	"driftive/pkg/models"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// atlantisFiles are the names Atlantis reads its repo config from, in order.
+var atlantisFiles = []string{"atlantis.yaml", "atlantis.yml"}
+
+// atlantisConfig is the subset of an Atlantis repo config driftive relies on.
+type atlantisConfig struct {
+	Projects []atlantisProject `yaml:"projects"`
+}
+
+type atlantisProject struct {
+	Name                  string `yaml:"name"`
+	Dir                   string `yaml:"dir"`
+	Workspace             string `yaml:"workspace"`
+	TerraformVersion      string `yaml:"terraform_version"`
+	TerraformDistribution string `yaml:"terraform_distribution"`
+	Autoplan              struct {
+		WhenModified []string `yaml:"when_modified"`
+	} `yaml:"autoplan"`
+}
+
+// atlantisProjects reads the projects declared in the atlantis.yaml at the root of rootDir.
+// Each entry becomes a project keyed by its dir, and by its workspace unless it is Atlantis'
+// default one.
+func atlantisProjects(rootDir string) ([]models.TypedProject, error) {
+	content, path, err := readAtlantisFile(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	var cfg atlantisConfig
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s. %w", path, err)
+	}
+
+	projects := make([]models.TypedProject, 0, len(cfg.Projects))
+	for _, p := range cfg.Projects {
+		if p.Dir == "" {
+			return nil, fmt.Errorf("%s: project '%s' has no dir", path, p.Name)
+		}
+		workspace := p.Workspace
+		if workspace == "default" {
+			workspace = ""
+		}
+		projectType := models.Terraform
+		if p.TerraformDistribution == "opentofu" {
+			projectType = models.Tofu
+		}
+		projects = append(projects, models.TypedProject{
+			Dir:       filepath.Join(rootDir, p.Dir),
+			Type:      projectType,
+			Name:      p.Name,
+			Workspace: workspace,
+			Settings: models.ProjectSettings{
+				ToolVersion:  p.TerraformVersion,
+				WhenModified: p.Autoplan.WhenModified,
+			},
+		})
+	}
+	log.Info().Msgf("Read %d project(s) from %s", len(projects), path)
+	return projects, nil
+}
+
+func readAtlantisFile(rootDir string) ([]byte, string, error) {
+	for _, name := range atlantisFiles {
+		path := filepath.Join(rootDir, name)
+		content, err := os.ReadFile(path)
+		if err == nil {
+			return content, path, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, path, err
+		}
+	}
+	return nil, "", fmt.Errorf("no atlantis.yaml found in %s", rootDir)
+}
@@ -0,0 +1,79 @@
+package discover
+
+import (
+	"driftive/pkg/config/repo"
+	"driftive/pkg/models"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const atlantisYAML = `version: 3
+automerge: true
+projects:
+  - name: network-prod
+    dir: infra/network
+    workspace: prod
+    terraform_version: v1.5.7
+    autoplan:
+      when_modified: ["*.tf", "../../modules/**/*.tf"]
+      enabled: true
+  - dir: infra/network
+    workspace: default
+  - name: dns
+    dir: infra/dns
+    terraform_distribution: opentofu
+    apply_requirements: [approved]
+`
+
+func TestAtlantisProjects(t *testing.T) {
+	root := t.TempDir()
+	writeContent(t, filepath.Join(root, "atlantis.yaml"), atlantisYAML)
+	writeFile(t, filepath.Join(root, "infra", "shared", "main.tf"))
+
+	cfg := repo.DefaultRepoConfig()
+	cfg.AutoDiscover.Source = repo.DiscoverySourceAtlantis
+
+	projects := AutoDiscoverProjects(root, cfg)
+
+	if len(projects) != 3 {
+		t.Fatalf("expected the 3 atlantis projects only, got %+v", projects)
+	}
+	dns, network, networkProd := projects[0], projects[1], projects[2]
+	if dns.Dir != filepath.Join(root, "infra", "dns") || dns.Type != models.Tofu || dns.Name != "dns" {
+		t.Errorf("unexpected dns project: %+v", dns)
+	}
+	if network.Workspace != "" || network.Type != models.Terraform {
+		t.Errorf("expected the default workspace to plan without TF_WORKSPACE, got %+v", network)
+	}
+	if networkProd.Workspace != "prod" || networkProd.Name != "network-prod" || networkProd.Settings.ToolVersion != "v1.5.7" {
+		t.Errorf("unexpected network-prod project: %+v", networkProd)
+	}
+	if want := []string{"*.tf", "../../modules/**/*.tf"}; !slices.Equal(networkProd.Settings.WhenModified, want) {
+		t.Errorf("WhenModified = %v, want %v", networkProd.Settings.WhenModified, want)
+	}
+}
+
+func TestAtlantisProjectsAreOverriddenByExplicitEntries(t *testing.T) {
+	root := t.TempDir()
+	writeContent(t, filepath.Join(root, "atlantis.yml"), atlantisYAML)
+
+	cfg := repo.DefaultRepoConfig()
+	cfg.AutoDiscover.Source = repo.DiscoverySourceAtlantis
+	cfg.Projects = []repo.ProjectConfig{{Dir: "infra/network", Executable: "terragrunt"}}
+
+	projects := AutoDiscoverProjects(root, cfg)
+
+	if len(projects) != 2 {
+		t.Fatalf("expected dns and the explicit network project, got %+v", projects)
+	}
+	if projects[1].Type != models.Terragrunt || projects[1].Workspace != "" {
+		t.Errorf("expected the explicit entry to replace both atlantis workspaces, got %+v", projects[1])
+	}
+}
+
+func TestAtlantisProjectsWithoutFile(t *testing.T) {
+	if _, err := atlantisProjects(t.TempDir()); err == nil {
+		t.Error("expected an error without atlantis.yaml")
+	}
+}
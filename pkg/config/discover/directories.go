@@ -0,0 +1,95 @@
+package discover
+
+import (
+	"driftive/pkg/config/repo"
+	"driftive/pkg/models"
+	"maps"
+	"path/filepath"
+	"slices"
+)
+
+// applyDirectoryConfigs applies the driftive.yml fragments of the dirs containing each project,
+// parents first so the deepest fragment wins. A fragment overrides what a project rule or
+// atlantis.yaml set, but not the settings an explicit projects entry sets itself. Labels, tags
+// and ignore rules add up. The fragments applied are added to the project's origin.
+func applyDirectoryConfigs(rootDir string, discovery *Discovery, dirs []repo.DirectoryConfig) {
+	if len(dirs) == 0 {
+		return
+	}
+	applied := make(map[string]bool)
+	for i := range discovery.Projects {
+		project := &discovery.Projects[i]
+		key := filepath.Clean(project.Dir)
+		explicit := discovery.Origins[key] == originProjectsEntry
+		rel := relativeTo(rootDir, project.Dir)
+		for _, dir := range dirs {
+			if !dir.Contains(rel) {
+				continue
+			}
+			applyDirectoryConfig(rootDir, project, dir, explicit)
+			if !applied[key] {
+				// Workspaces of one project share its origin.
+				discovery.Origins[key] += " + " + filepath.Join(dir.Dir, "driftive.yml")
+			}
+		}
+		applied[key] = true
+	}
+}
+
+func applyDirectoryConfig(rootDir string, project *models.TypedProject, dir repo.DirectoryConfig, explicit bool) {
+	settings := &project.Settings
+	if dir.Executable != "" && !explicit {
+		project.Type = executableToProjectType(dir.Executable)
+		settings.AllWorkspaces = project.Type == models.Pulumi && project.Workspace == ""
+	}
+	if dir.DriftMode != "" && (!explicit || settings.DriftMode == "") {
+		settings.DriftMode = models.DriftMode(dir.DriftMode)
+	}
+	if dir.Timeout != 0 && (!explicit || settings.Timeout == 0) {
+		settings.Timeout = dir.Timeout
+	}
+	args := projectArgsSettings(rootDir, project.Dir, dir.ProjectArgs)
+	settings.InitArgs = overrideArgs(settings.InitArgs, args.InitArgs, explicit)
+	settings.PlanArgs = overrideArgs(settings.PlanArgs, args.PlanArgs, explicit)
+	settings.VarFiles = overrideArgs(settings.VarFiles, args.VarFiles, explicit)
+	settings.BackendConfig = overrideArgs(settings.BackendConfig, args.BackendConfig, explicit)
+	settings.EnvPassthrough = overrideArgs(settings.EnvPassthrough, dir.EnvPassthrough, explicit)
+	if len(dir.Env) > 0 {
+		env := maps.Clone(settings.Env)
+		if env == nil {
+			env = make(map[string]string, len(dir.Env))
+		}
+		for name, value := range dir.Env {
+			if _, ok := settings.Env[name]; !ok || !explicit {
+				env[name] = value
+			}
+		}
+		settings.Env = env
+	}
+
+	if len(dir.Owners) > 0 && (!explicit || len(project.Owners) == 0) {
+		project.Owners = slices.Clone(dir.Owners)
+	}
+	if dir.Severity != "" && (!explicit || project.Severity == "") {
+		project.Severity = dir.Severity
+	}
+	for _, tag := range dir.Tags {
+		if !slices.Contains(project.Tags, tag) {
+			project.Tags = append(project.Tags, tag)
+		}
+	}
+
+	settings.Labels = append(settings.Labels, dir.Labels...)
+	settings.IgnoreResources = append(settings.IgnoreResources, dir.Ignore.Resources...)
+	settings.IgnoreResourceTypes = append(settings.IgnoreResourceTypes, dir.Ignore.ResourceTypes...)
+	settings.IgnoreAttributes = append(settings.IgnoreAttributes, dir.Ignore.Attributes...)
+}
+
+// overrideArgs returns the fragment's values when it sets any, unless the project's own entry
+// does.
+func overrideArgs(current, fragment []string, explicit bool) []string {
+	if len(fragment) == 0 || (explicit && len(current) > 0) {
+		return current
+	}
+	return fragment
+}
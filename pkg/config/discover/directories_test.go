@@ -0,0 +1,104 @@
+package discover
+
+import (
+	"driftive/pkg/config/repo"
+	"driftive/pkg/models"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDirectoryConfigsOverrideTheProjectsUnderThem(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "teams", "a", "vpc", "main.tf"))
+	writeFile(t, filepath.Join(root, "teams", "a", "dns", "main.tf"))
+	writeFile(t, filepath.Join(root, "teams", "b", "main.tf"))
+
+	cfg := repo.DefaultRepoConfig()
+	cfg.Projects = []repo.ProjectConfig{{Dir: "teams/a/dns", Executable: "terraform", ProjectArgs: repo.ProjectArgs{PlanArgs: []string{"-lock=false"}}}}
+	cfg.Directories = []repo.DirectoryConfig{
+		{
+			Dir:         "teams",
+			Labels:      []string{"platform"},
+			ProjectArgs: repo.ProjectArgs{PlanArgs: []string{"-parallelism=5"}},
+			Timeout:     time.Hour,
+		},
+		{
+			Dir:        filepath.Join("teams", "a"),
+			Executable: "tofu",
+			Labels:     []string{"team-a"},
+			Ignore:     repo.DriftiveRepoConfigDriftIgnore{ResourceTypes: []string{"aws_autoscaling_group"}},
+			ProjectEnv: repo.ProjectEnv{Env: map[string]string{"AWS_PROFILE": "team-a"}},
+		},
+	}
+
+	discovery := Discover(root, cfg)
+	if len(discovery.Projects) != 3 {
+		t.Fatalf("expected 3 projects, got %+v", discovery.Projects)
+	}
+	dns, vpc, b := discovery.Projects[0], discovery.Projects[1], discovery.Projects[2]
+
+	if vpc.Type != models.Tofu || !slices.Equal(vpc.Settings.PlanArgs, []string{"-parallelism=5"}) || vpc.Settings.Timeout != time.Hour {
+		t.Errorf("expected both fragments to apply to teams/a/vpc, got %+v", vpc)
+	}
+	if !slices.Equal(vpc.Settings.Labels, []string{"platform", "team-a"}) || vpc.Settings.Env["AWS_PROFILE"] != "team-a" {
+		t.Errorf("expected labels and env to add up, got %+v", vpc.Settings)
+	}
+	if !slices.Equal(vpc.Settings.IgnoreResourceTypes, []string{"aws_autoscaling_group"}) {
+		t.Errorf("IgnoreResourceTypes = %v", vpc.Settings.IgnoreResourceTypes)
+	}
+	if want := "rule *.tf + teams/driftive.yml + teams/a/driftive.yml"; discovery.Origin(vpc) != filepath.FromSlash(want) {
+		t.Errorf("Origin(teams/a/vpc) = %q, want %q", discovery.Origin(vpc), want)
+	}
+
+	if dns.Type != models.Terraform || !slices.Equal(dns.Settings.PlanArgs, []string{"-lock=false"}) {
+		t.Errorf("expected the explicit entry's own settings to win, got %+v", dns)
+	}
+	if dns.Settings.Timeout != time.Hour || !slices.Equal(dns.Settings.Labels, []string{"platform", "team-a"}) {
+		t.Errorf("expected the settings the entry leaves unset to come from the fragments, got %+v", dns.Settings)
+	}
+
+	if b.Type != models.Terraform || !slices.Equal(b.Settings.Labels, []string{"platform"}) {
+		t.Errorf("expected only the teams fragment to apply to teams/b, got %+v", b)
+	}
+}
+
+func TestProjectMetadataComesFromRulesEntriesAndFragments(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "prod", "payments", "main.tf"))
+	writeFile(t, filepath.Join(root, "prod", "search", "main.tf"))
+
+	cfg := repo.DefaultRepoConfig()
+	cfg.AutoDiscover.ProjectRules = []repo.AutoDiscoverRule{{
+		Pattern:         "*.tf",
+		Executable:      "terraform",
+		Name:            "${dir}",
+		ProjectMetadata: repo.ProjectMetadata{Owners: []string{"@platform"}, Severity: "low", Tags: []string{"tf"}},
+	}}
+	cfg.Projects = []repo.ProjectConfig{{
+		Dir: "prod/search", Executable: "terraform", Name: "search",
+		ProjectMetadata: repo.ProjectMetadata{Owners: []string{"@search-team"}},
+	}}
+	cfg.Directories = []repo.DirectoryConfig{{
+		Dir:             "prod",
+		ProjectMetadata: repo.ProjectMetadata{Owners: []string{"@payments-team"}, Severity: "high", Tags: []string{"prod"}},
+	}}
+
+	projects := AutoDiscoverProjects(root, cfg)
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %+v", projects)
+	}
+	payments, search := projects[0], projects[1]
+
+	if payments.Name != "prod/payments" || !slices.Equal(payments.Owners, []string{"@payments-team"}) ||
+		payments.Severity != "high" || !slices.Equal(payments.Tags, []string{"tf", "prod"}) {
+		t.Errorf("expected the fragment to override the rule's owners and severity, got %+v", payments)
+	}
+	if search.Name != "search" || !slices.Equal(search.Owners, []string{"@search-team"}) || search.Severity != "high" {
+		t.Errorf("expected the entry's own owners to win and its severity to come from the fragment, got %+v", search)
+	}
+	if !slices.Equal(cfg.AutoDiscover.ProjectRules[0].Tags, []string{"tf"}) {
+		t.Errorf("the rule's tags were modified: %v", cfg.AutoDiscover.ProjectRules[0].Tags)
+	}
+}
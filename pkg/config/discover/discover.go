@@ -9,6 +9,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -20,6 +22,8 @@ func executableToProjectType(executable string) models.ProjectType {
 		return models.Tofu
 	case "terragrunt":
 		return models.Terragrunt
+	case "pulumi":
+		return models.Pulumi
 	default:
 		log.Warn().Msgf("Unknown executable type %v", executable)
 		return models.Terraform
@@ -35,7 +39,7 @@ func DetectTerragruntProjects(dir string) []string {
 			return err
 		}
 		// Check if the file is a terragrunt file. Ignore root terragrunt files.
-		if !info.IsDir() && info.Name() == targetFileName && path != filepath.Join(dir, targetFileName) && !isPartOfCacheFolder(path) {
+		if !info.IsDir() && info.Name() == targetFileName && path != filepath.Join(dir, targetFileName) && !utils.IsPartOfCacheFolder(path) {
 			folder := filepath.Dir(path)
 			foldersContainingFile = append(foldersContainingFile, folder)
 		}
@@ -50,12 +54,69 @@ func DetectTerragruntProjects(dir string) []string {
 	return foldersContainingFile
 }
 
-func isPartOfCacheFolder(dir string) bool {
-	return strings.Contains(dir, ".terragrunt-cache") || strings.Contains(dir, ".terraform")
+// Discovery is the outcome of project discovery, with the reasons behind it.
+type Discovery struct {
+	Projects []models.TypedProject
+	// Origins tells, by project dir, where each project comes from: the project rule that
+	// matched it, an explicit projects entry or atlantis.yaml, followed by the driftive.yml
+	// fragments applied to it.
+	Origins map[string]string
+	// Files are the files an inclusion matched, with why each was kept or excluded. Files no
+	// inclusion matches are left out.
+	Files []FileDecision
+	// Unmatched are the candidate dirs, relative to the root, that no project rule matched.
+	Unmatched []string
 }
 
+// FileDecision tells why a file made its dir a candidate project, or why it did not.
+type FileDecision struct {
+	// Path is relative to the repository root.
+	Path     string
+	Included bool
+	// Reason names the inclusion or exclusion pattern that decided, e.g. "excluded by **/modules/**".
+	Reason string
+}
+
+// Origin is where the project comes from. See Discovery.Origins.
+func (d Discovery) Origin(project models.TypedProject) string {
+	return d.Origins[filepath.Clean(project.Dir)]
+}
+
+// AutoDiscoverProjects lists the projects to analyze: those read from the configured discovery
+// source, plus the explicit project entries.
 func AutoDiscoverProjects(rootDir string, config *repo.DriftiveRepoConfig) []models.TypedProject {
-	projs := getAllPossibleProjectPaths(rootDir, config)
+	return Discover(rootDir, config).Projects
+}
+
+// Discover runs project discovery like AutoDiscoverProjects, and records why each project and
+// file ended up where it did.
+func Discover(rootDir string, config *repo.DriftiveRepoConfig) Discovery {
+	discovery := Discovery{Origins: make(map[string]string)}
+	if config.AutoDiscover.Source == repo.DiscoverySourceAtlantis {
+		discovered, err := atlantisProjects(rootDir)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to read the projects of auto_discover.source atlantis")
+		}
+		for _, project := range discovered {
+			discovery.Origins[filepath.Clean(project.Dir)] = "atlantis.yaml"
+		}
+		discovery.Projects = mergeExplicitProjects(rootDir, discovered, config.Projects)
+		addExplicitOrigins(rootDir, config.Projects, discovery.Origins)
+		applyDirectoryConfigs(rootDir, &discovery, config.Directories)
+		return discovery
+	}
+
+	projs, files := getAllPossibleProjectPaths(rootDir, config)
+	discovery.Files = files
+	var stacks []terramateStack
+	if config.AutoDiscover.Terramate.Enabled {
+		stacks = terramateStacks(rootDir, config.AutoDiscover.Exclusions)
+		for _, stack := range stacks {
+			if !utils.Contains(projs, stack.Dir) {
+				projs = append(projs, stack.Dir)
+			}
+		}
+	}
 	mapProjects := make(map[string]*models.TypedProject)
 	rules := config.AutoDiscover.ProjectRules
 
@@ -80,11 +141,21 @@ func AutoDiscoverProjects(rootDir string, config *repo.DriftiveRepoConfig) []mod
 				}
 				if match {
 					projectType := executableToProjectType(rule.Executable)
+					settings := projectArgsSettings(rootDir, proj, rule.ProjectArgs)
+					settings.DriftMode = models.DriftMode(rule.DriftMode)
+					settings.Env = rule.Env
+					settings.EnvPassthrough = rule.EnvPassthrough
+					settings.Timeout = rule.Timeout
+					settings.AllWorkspaces = projectType == models.Pulumi
 					project := &models.TypedProject{
-						Dir:  proj,
-						Type: projectType,
+						Dir:      proj,
+						Type:     projectType,
+						Name:     argsTemplate(rootDir, proj).Replace(rule.Name),
+						Settings: settings,
 					}
+					setMetadata(project, rule.ProjectMetadata)
 					mapProjects[proj] = project
+					discovery.Origins[filepath.Clean(proj)] = "rule " + rule.Pattern
 					return filepath.SkipAll
 				}
 				return nil
@@ -98,45 +169,202 @@ func AutoDiscoverProjects(rootDir string, config *repo.DriftiveRepoConfig) []mod
 				break
 			}
 		}
+		if _, ok := mapProjects[proj]; !ok {
+			discovery.Unmatched = append(discovery.Unmatched, relativeTo(rootDir, proj))
+		}
 	}
 
-	driftiveProjects := make([]models.TypedProject, 0, len(mapProjects))
+	discovered := make([]models.TypedProject, 0, len(mapProjects))
 	for _, project := range mapProjects {
-		driftiveProjects = append(driftiveProjects, *project)
+		discovered = append(discovered, *project)
+	}
+	discovery.Projects = mergeExplicitProjects(rootDir, discovered, config.Projects)
+	addExplicitOrigins(rootDir, config.Projects, discovery.Origins)
+	applyDirectoryConfigs(rootDir, &discovery, config.Directories)
+	addStackMetadata(discovery.Projects, stacks)
+	addTerragruntDependencies(discovery.Projects)
+	return discovery
+}
+
+// originProjectsEntry is the origin of the projects declared under projects:.
+const originProjectsEntry = "projects entry"
+
+// addExplicitOrigins records the dirs of the explicit project entries, which win over any other
+// origin.
+func addExplicitOrigins(rootDir string, explicit []repo.ProjectConfig, origins map[string]string) {
+	for _, p := range explicit {
+		origins[filepath.Join(rootDir, p.Dir)] = originProjectsEntry
+	}
+}
+
+// relativeTo returns path relative to rootDir, or path itself when it is not under it.
+func relativeTo(rootDir, path string) string {
+	rel, err := filepath.Rel(rootDir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// projectArgsSettings resolves the templated CLI arguments of the project in dir.
+func projectArgsSettings(rootDir, dir string, args repo.ProjectArgs) models.ProjectSettings {
+	expand := argsTemplate(rootDir, dir)
+	return models.ProjectSettings{
+		InitArgs:      expandAll(expand, args.InitArgs),
+		PlanArgs:      expandAll(expand, args.PlanArgs),
+		VarFiles:      expandAll(expand, args.VarFiles),
+		BackendConfig: expandAll(expand, args.BackendConfig),
+	}
+}
+
+// setMetadata gives the project the owners, tags and severity set in driftive.yml. The lists are
+// copied, as they are added to later.
+func setMetadata(project *models.TypedProject, metadata repo.ProjectMetadata) {
+	project.Owners = slices.Clone(metadata.Owners)
+	project.Tags = slices.Clone(metadata.Tags)
+	project.Severity = metadata.Severity
+}
+
+// argsTemplate replaces ${dir}, ${dir_name} and ${root} for the project in dir. Anything else,
+// including shell-style $VARS, is left alone.
+func argsTemplate(rootDir, dir string) *strings.Replacer {
+	root, err := filepath.Abs(rootDir)
+	if err != nil {
+		root = rootDir
+	}
+	rel, err := filepath.Rel(rootDir, dir)
+	if err != nil {
+		rel = dir
+	}
+	return strings.NewReplacer(
+		"${dir}", filepath.ToSlash(rel),
+		"${dir_name}", filepath.Base(rel),
+		"${root}", root,
+	)
+}
+
+func expandAll(r *strings.Replacer, values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	expanded := make([]string, 0, len(values))
+	for _, v := range values {
+		expanded = append(expanded, r.Replace(v))
+	}
+	return expanded
+}
+
+// mergeExplicitProjects adds the projects declared under `projects:` to the auto-discovered
+// ones. An explicit entry wins over an auto-discovered project in the same dir. Projects are
+// sorted by dir, then workspace, so runs are reproducible.
+func mergeExplicitProjects(rootDir string, discovered []models.TypedProject, explicit []repo.ProjectConfig) []models.TypedProject {
+	explicitDirs := make(map[string]bool, len(explicit))
+	projects := make([]models.TypedProject, 0, len(discovered)+len(explicit))
+	for _, p := range explicit {
+		dir := filepath.Join(rootDir, p.Dir)
+		explicitDirs[dir] = true
+		settings := projectArgsSettings(rootDir, dir, p.ProjectArgs)
+		settings.DriftMode = models.DriftMode(p.DriftMode)
+		settings.Env = p.Env
+		settings.EnvPassthrough = p.EnvPassthrough
+		settings.Timeout = p.Timeout
+		settings.AllWorkspaces = p.Workspaces.All
+		if p.Executable == "pulumi" && p.Workspace == "" && p.Workspaces.IsZero() {
+			// A Pulumi project always previews a stack; with none named, preview them all.
+			settings.AllWorkspaces = true
+		}
+		project := models.TypedProject{
+			Dir:       dir,
+			Type:      executableToProjectType(p.Executable),
+			Name:      p.Name,
+			Workspace: p.Workspace,
+			Settings:  settings,
+		}
+		setMetadata(&project, p.ProjectMetadata)
+		if len(p.Workspaces.Names) == 0 {
+			projects = append(projects, project)
+			continue
+		}
+		for _, workspace := range p.Workspaces.Names {
+			project.Workspace = workspace
+			projects = append(projects, project)
+		}
+	}
+
+	for _, project := range discovered {
+		if explicitDirs[filepath.Clean(project.Dir)] {
+			log.Debug().Msgf("Auto-discovered project %s is overridden by an explicit project entry", project.Dir)
+			continue
+		}
+		projects = append(projects, project)
 	}
 
-	return driftiveProjects
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Dir != projects[j].Dir {
+			return projects[i].Dir < projects[j].Dir
+		}
+		return projects[i].Workspace < projects[j].Workspace
+	})
+	return projects
 }
 
-// FilterPaths filters the paths based on inclusion and exclusion patterns
-func filterPaths(paths []string, inclusions, exclusions []string) ([]string, error) {
+// explainPaths keeps the paths an inclusion matches and no exclusion does. Every path an
+// inclusion matches gets a decision naming the pattern that kept or excluded it.
+func explainPaths(rootDir string, paths []string, inclusions, exclusions []string) ([]string, []FileDecision, error) {
 	var filteredPaths []string
+	var decisions []FileDecision
 	inclPM, err := patternmatcher.New(inclusions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	exclPM, err := patternmatcher.New(exclusions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	for _, path := range paths {
 		inclMatches, err := inclPM.MatchesOrParentMatches(path)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		if !inclMatches {
+			continue
 		}
 
 		exclMatches, err := exclPM.MatchesOrParentMatches(path)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		if inclMatches && !exclMatches {
+		decision := FileDecision{Path: relativeTo(rootDir, path), Included: !exclMatches}
+		if exclMatches {
+			decision.Reason = "excluded by " + matchingPattern(exclusions, path)
+		} else {
+			decision.Reason = "included by " + matchingPattern(inclusions, path)
 			filteredPaths = append(filteredPaths, path)
 		}
+		decisions = append(decisions, decision)
 	}
 
-	return filteredPaths, nil
+	return filteredPaths, decisions, nil
+}
+
+// matchingPattern returns the pattern that decided a match of path. Patterns are evaluated in
+// order and the last match wins, so the last matching one is returned.
+func matchingPattern(patterns []string, path string) string {
+	for i := len(patterns) - 1; i >= 0; i-- {
+		if strings.HasPrefix(patterns[i], "!") {
+			continue
+		}
+		pm, err := patternmatcher.New(patterns[i : i+1])
+		if err != nil {
+			continue
+		}
+		if match, err := pm.MatchesOrParentMatches(path); err == nil && match {
+			return patterns[i]
+		}
+	}
+	return ""
 }
 
 // GetAllFiles returns a list of all files in the directory
@@ -159,17 +387,17 @@ func getAllFiles(root string) ([]string, error) {
 	return files, nil
 }
 
-func getAllPossibleProjectPaths(root string, config *repo.DriftiveRepoConfig) []string {
+func getAllPossibleProjectPaths(root string, config *repo.DriftiveRepoConfig) ([]string, []FileDecision) {
 	allFiles, err := getAllFiles(root)
 	if err != nil {
 		log.Error().Msgf("Error getting all files in %v: %v", root, err)
-		return nil
+		return nil, nil
 	}
 
-	filteredFiles, err := filterPaths(allFiles, config.AutoDiscover.Inclusions, config.AutoDiscover.Exclusions)
+	filteredFiles, decisions, err := explainPaths(root, allFiles, config.AutoDiscover.Inclusions, config.AutoDiscover.Exclusions)
 	if err != nil {
 		log.Error().Msgf("Error filtering files: %v\n", err)
-		return nil
+		return nil, nil
 	}
 
 	var projectDirs []string
@@ -186,5 +414,5 @@ func getAllPossibleProjectPaths(root string, config *repo.DriftiveRepoConfig) []
 		}
 	}
 
-	return projectDirs
+	return projectDirs, decisions
 }
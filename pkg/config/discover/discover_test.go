@@ -0,0 +1,153 @@
+package discover
+
+import (
+	"driftive/pkg/config/repo"
+	"driftive/pkg/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutoDiscoverProjectsMergesExplicitProjects(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "infra", "vpc", "main.tf"))
+	writeFile(t, filepath.Join(root, "infra", "dns", "main.tf"))
+
+	cfg := repo.DefaultRepoConfig()
+	cfg.Projects = []repo.ProjectConfig{
+		{Dir: "infra/vpc", Executable: "tofu", Name: "Core network",
+			ProjectArgs: repo.ProjectArgs{PlanArgs: []string{"-parallelism=5"}}},
+		{Dir: "stacks/legacy", Executable: "terraform", Workspace: "prod"},
+	}
+
+	projects := AutoDiscoverProjects(root, cfg)
+
+	if len(projects) != 3 {
+		t.Fatalf("expected 3 projects, got %d: %+v", len(projects), projects)
+	}
+	want := []string{"infra/dns", "infra/vpc", "stacks/legacy"}
+	for i, p := range projects {
+		if p.Dir != filepath.Join(root, want[i]) {
+			t.Errorf("projects[%d].Dir = %q, want %q", i, p.Dir, filepath.Join(root, want[i]))
+		}
+	}
+
+	vpc := projects[1]
+	if vpc.Type != models.Tofu || vpc.Name != "Core network" {
+		t.Errorf("explicit entry should win over the discovered project, got %+v", vpc)
+	}
+	if len(vpc.Settings.PlanArgs) != 1 || vpc.Settings.PlanArgs[0] != "-parallelism=5" {
+		t.Errorf("PlanArgs = %v", vpc.Settings.PlanArgs)
+	}
+	if projects[2].Workspace != "prod" {
+		t.Errorf("Workspace = %q, want prod", projects[2].Workspace)
+	}
+}
+
+func TestProjectRuleArgsAreTemplatedPerProject(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "envs", "prod", "main.tf"))
+
+	cfg := repo.DefaultRepoConfig()
+	cfg.AutoDiscover.ProjectRules = []repo.AutoDiscoverRule{{
+		Pattern:    "*.tf",
+		Executable: "terraform",
+		ProjectArgs: repo.ProjectArgs{
+			VarFiles:      []string{"${root}/vars/${dir_name}.tfvars"},
+			BackendConfig: []string{"key=${dir}/terraform.tfstate"},
+			PlanArgs:      []string{"-var=price=$5"},
+		},
+	}}
+
+	projects := AutoDiscoverProjects(root, cfg)
+
+	if len(projects) != 1 {
+		t.Fatalf("expected 1 project, got %d", len(projects))
+	}
+	settings := projects[0].Settings
+	if want := root + "/vars/prod.tfvars"; len(settings.VarFiles) != 1 || settings.VarFiles[0] != want {
+		t.Errorf("VarFiles = %v, want [%s]", settings.VarFiles, want)
+	}
+	if want := "key=envs/prod/terraform.tfstate"; len(settings.BackendConfig) != 1 || settings.BackendConfig[0] != want {
+		t.Errorf("BackendConfig = %v, want [%s]", settings.BackendConfig, want)
+	}
+	if len(settings.PlanArgs) != 1 || settings.PlanArgs[0] != "-var=price=$5" {
+		t.Errorf("PlanArgs = %v, want other $ references left alone", settings.PlanArgs)
+	}
+}
+
+func TestPulumiProjectsPreviewEveryStackByDefault(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "pulumi", "app", "Pulumi.yaml"))
+
+	cfg := repo.DefaultRepoConfig()
+	cfg.AutoDiscover.Inclusions = append(cfg.AutoDiscover.Inclusions, "**/Pulumi.yaml")
+	cfg.AutoDiscover.ProjectRules = append(cfg.AutoDiscover.ProjectRules, repo.AutoDiscoverRule{Pattern: "Pulumi.yaml", Executable: "pulumi"})
+	cfg.Projects = []repo.ProjectConfig{
+		{Dir: "pulumi/network", Executable: "pulumi", Workspace: "prod"},
+	}
+
+	projects := AutoDiscoverProjects(root, cfg)
+
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d: %+v", len(projects), projects)
+	}
+	if app := projects[0]; app.Type != models.Pulumi || !app.Settings.AllWorkspaces {
+		t.Errorf("expected the discovered project to preview every stack, got %+v", app)
+	}
+	if network := projects[1]; network.Workspace != "prod" || network.Settings.AllWorkspaces {
+		t.Errorf("expected the explicit project to preview its stack, got %+v", network)
+	}
+}
+
+func TestDiscoverExplainsProjectsAndFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "infra", "vpc", "main.tf"))
+	writeFile(t, filepath.Join(root, "infra", "dns", "main.tf"))
+	writeFile(t, filepath.Join(root, "modules", "net", "main.tf"))
+	writeFile(t, filepath.Join(root, "live", "app", "terragrunt.hcl"))
+
+	cfg := repo.DefaultRepoConfig()
+	cfg.AutoDiscover.ProjectRules = cfg.AutoDiscover.ProjectRules[1:]
+	cfg.Projects = []repo.ProjectConfig{{Dir: "infra/dns", Executable: "tofu"}}
+
+	discovery := Discover(root, cfg)
+
+	if len(discovery.Projects) != 2 {
+		t.Fatalf("expected 2 projects, got %+v", discovery.Projects)
+	}
+	if got := discovery.Origin(discovery.Projects[0]); got != "projects entry" {
+		t.Errorf("Origin(infra/dns) = %q, want the explicit entry", got)
+	}
+	if got := discovery.Origin(discovery.Projects[1]); got != "rule *.tf" {
+		t.Errorf("Origin(infra/vpc) = %q, want the matching rule", got)
+	}
+	if want := []string{filepath.Join("live", "app")}; !slices.Equal(discovery.Unmatched, want) {
+		t.Errorf("Unmatched = %v, want %v", discovery.Unmatched, want)
+	}
+
+	decisions := make(map[string]FileDecision, len(discovery.Files))
+	for _, f := range discovery.Files {
+		decisions[filepath.ToSlash(f.Path)] = f
+	}
+	if d := decisions["modules/net/main.tf"]; d.Included || d.Reason != "excluded by **/modules/**" {
+		t.Errorf("modules/net/main.tf decision = %+v", d)
+	}
+	if d := decisions["infra/vpc/main.tf"]; !d.Included || d.Reason != "included by **/*.tf" {
+		t.Errorf("infra/vpc/main.tf decision = %+v", d)
+	}
+	if d := decisions["live/app/terragrunt.hcl"]; !d.Included || d.Reason != "included by **/terragrunt.hcl" {
+		t.Errorf("live/app/terragrunt.hcl decision = %+v", d)
+	}
+}
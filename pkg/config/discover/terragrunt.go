@@ -0,0 +1,144 @@
+package discover
+
+import (
+	"driftive/pkg/models"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	dependencyBlockPattern   = regexp.MustCompile(`\bdependency\s+"[^"]*"\s*\{`)
+	dependenciesBlockPattern = regexp.MustCompile(`\bdependencies\s*\{`)
+	configPathPattern        = regexp.MustCompile(`\bconfig_path\s*=\s*"([^"]*)"`)
+	pathsPattern             = regexp.MustCompile(`\bpaths\s*=\s*\[([^\]]*)\]`)
+	quotedPattern            = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// terragruntDependencies returns the dirs the terragrunt.hcl in dir depends on, from its
+// `dependency` blocks' config_path and its `dependencies` block's paths. Paths are resolved
+// against dir. A path built with a function other than get_terragrunt_dir() cannot be resolved
+// without terragrunt and is skipped.
+func terragruntDependencies(dir string) []string {
+	content, err := os.ReadFile(filepath.Join(dir, "terragrunt.hcl"))
+	if err != nil {
+		return nil
+	}
+	hcl := stripHCLComments(string(content))
+
+	var paths []string
+	for _, body := range blockBodies(hcl, dependencyBlockPattern) {
+		if match := configPathPattern.FindStringSubmatch(body); match != nil {
+			paths = append(paths, match[1])
+		}
+	}
+	for _, body := range blockBodies(hcl, dependenciesBlockPattern) {
+		if match := pathsPattern.FindStringSubmatch(body); match != nil {
+			for _, quoted := range quotedPattern.FindAllStringSubmatch(match[1], -1) {
+				paths = append(paths, quoted[1])
+			}
+		}
+	}
+
+	deps := make([]string, 0, len(paths))
+	for _, path := range paths {
+		path = strings.ReplaceAll(path, "${get_terragrunt_dir()}", dir)
+		if strings.Contains(path, "${") {
+			log.Debug().Msgf("Skipping dependency %s of %s: only get_terragrunt_dir() is resolved", path, dir)
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		deps = append(deps, filepath.Clean(path))
+	}
+	return deps
+}
+
+// blockBodies returns the bodies of the blocks whose header matches pattern, up to the brace
+// closing each block.
+func blockBodies(hcl string, pattern *regexp.Regexp) []string {
+	var bodies []string
+	for _, loc := range pattern.FindAllStringIndex(hcl, -1) {
+		open := loc[1] - 1
+		if end := closingBrace(hcl, open); end > open {
+			bodies = append(bodies, hcl[open+1:end])
+		}
+	}
+	return bodies
+}
+
+// closingBrace returns the index of the brace closing the one at open, skipping braces inside
+// strings, or -1 when the block is not closed.
+func closingBrace(hcl string, open int) int {
+	depth := 0
+	inString := false
+	for i := open; i < len(hcl); i++ {
+		switch c := hcl[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// stripHCLComments removes #, // and /* */ comments, leaving strings alone, so a
+// commented-out dependency is not picked up.
+func stripHCLComments(hcl string) string {
+	var b strings.Builder
+	inString := false
+	for i := 0; i < len(hcl); i++ {
+		c := hcl[i]
+		switch {
+		case inString:
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(hcl) {
+				i++
+				b.WriteByte(hcl[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			b.WriteByte(c)
+		case c == '#' || (c == '/' && strings.HasPrefix(hcl[i:], "//")):
+			for i < len(hcl) && hcl[i] != '\n' {
+				i++
+			}
+			if i < len(hcl) {
+				b.WriteByte('\n')
+			}
+		case c == '/' && strings.HasPrefix(hcl[i:], "/*"):
+			end := strings.Index(hcl[i+2:], "*/")
+			if end == -1 {
+				return b.String()
+			}
+			i += end + 3
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// addTerragruntDependencies records the dependencies of every terragrunt project.
+func addTerragruntDependencies(projects []models.TypedProject) {
+	for i := range projects {
+		if projects[i].Type == models.Terragrunt {
+			projects[i].Settings.DependsOn = terragruntDependencies(projects[i].Dir)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package discover
+
+import (
+	"driftive/pkg/config/repo"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestTerragruntDependencies(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "live", "prod", "app")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	hcl := `
+include "root" {
+  path = find_in_parent_folders()
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+
+  mock_outputs = {
+    vpc_id = "vpc-{mock}"
+  }
+}
+
+# dependency "old" {
+#   config_path = "../old"
+# }
+
+/*
+dependency "legacy" {
+  config_path = "../legacy"
+}
+*/
+
+dependency "iam" {
+  config_path = "${get_terragrunt_dir()}/../../global/iam" // shared roles
+}
+
+dependency "dynamic" {
+  config_path = "${find_in_parent_folders("dns")}"
+}
+
+dependencies {
+  paths = ["../db", "../cache"]
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "terragrunt.hcl"), []byte(hcl), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got := terragruntDependencies(dir)
+
+	want := []string{
+		filepath.Join(root, "live", "prod", "vpc"),
+		filepath.Join(root, "live", "global", "iam"),
+		filepath.Join(root, "live", "prod", "db"),
+		filepath.Join(root, "live", "prod", "cache"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("terragruntDependencies() = %v, want %v", got, want)
+	}
+}
+
+func TestAutoDiscoverProjectsRecordsTerragruntDependencies(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "live", "vpc", "terragrunt.hcl"))
+	app := filepath.Join(root, "live", "app", "terragrunt.hcl")
+	writeFile(t, app)
+	if err := os.WriteFile(app, []byte(`dependency "vpc" { config_path = "../vpc" }`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	projects := AutoDiscoverProjects(root, repo.DefaultRepoConfig())
+
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %+v", projects)
+	}
+	if got := projects[0].Settings.DependsOn; !slices.Equal(got, []string{filepath.Join(root, "live", "vpc")}) {
+		t.Errorf("live/app DependsOn = %v, want live/vpc", got)
+	}
+	if got := projects[1].Settings.DependsOn; len(got) != 0 {
+		t.Errorf("live/vpc DependsOn = %v, want none", got)
+	}
+}
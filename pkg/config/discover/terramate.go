@@ -0,0 +1,133 @@
+package discover
+
+import (
+	"driftive/pkg/models"
+	"driftive/pkg/utils"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/moby/patternmatcher"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	stackBlockPattern = regexp.MustCompile(`(?m)^\s*stack\s*\{`)
+	stackNamePattern  = regexp.MustCompile(`(?m)^\s*name\s*=\s*"([^"]*)"`)
+	stackTagsPattern  = regexp.MustCompile(`(?m)^\s*tags\s*=\s*\[([^\]]*)\]`)
+)
+
+// terramateStack is a directory declared a stack by a Terramate `stack` block.
+type terramateStack struct {
+	Dir string
+	// Name is the stack's name attribute. Empty when unset, Terramate then names the stack
+	// after its dir.
+	Name string
+	Tags []string
+}
+
+// isTerramateFile reports whether name is a Terramate configuration file.
+func isTerramateFile(name string) bool {
+	return strings.HasSuffix(name, ".tm") || strings.HasSuffix(name, ".tm.hcl")
+}
+
+// terramateStacks finds the stacks under rootDir, skipping the dirs matched by exclusions like
+// auto-discovered files are. A stack's name and tags are read from its first stack block.
+func terramateStacks(rootDir string, exclusions []string) []terramateStack {
+	excluded, err := patternmatcher.New(exclusions)
+	if err != nil {
+		log.Error().Msgf("Error parsing exclusions: %v", err)
+		return nil
+	}
+
+	var stacks []terramateStack
+	seen := make(map[string]bool)
+	err = filepath.WalkDir(rootDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != rootDir && (entry.Name() == ".git" || utils.IsPartOfCacheFolder(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		dir := filepath.Dir(path)
+		if !isTerramateFile(entry.Name()) || seen[dir] {
+			return nil
+		}
+		if skip, err := excluded.MatchesOrParentMatches(path); err != nil || skip {
+			return err
+		}
+		stack, ok := readTerramateStack(path)
+		if !ok {
+			return nil
+		}
+		seen[dir] = true
+		stacks = append(stacks, stack)
+		return nil
+	})
+	if err != nil {
+		log.Error().Msgf("Error looking for terramate stacks in %v: %v", rootDir, err)
+		return nil
+	}
+	return stacks
+}
+
+// readTerramateStack reads the stack declared in the Terramate file at path, if any.
+func readTerramateStack(path string) (terramateStack, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Warn().Msgf("Error reading %s: %v", path, err)
+		return terramateStack{}, false
+	}
+	bodies := blockBodies(stripHCLComments(string(content)), stackBlockPattern)
+	if len(bodies) == 0 {
+		return terramateStack{}, false
+	}
+
+	stack := terramateStack{Dir: filepath.Dir(path)}
+	if match := stackNamePattern.FindStringSubmatch(bodies[0]); match != nil {
+		stack.Name = match[1]
+	}
+	if match := stackTagsPattern.FindStringSubmatch(bodies[0]); match != nil {
+		for _, quoted := range quotedPattern.FindAllStringSubmatch(match[1], -1) {
+			stack.Tags = append(stack.Tags, quoted[1])
+		}
+	}
+	return stack, true
+}
+
+// addStackMetadata adds the stack's tags to the projects in a stack dir, and gives them its name
+// unless they are already named. A stack no project rule matched is reported, as it is not analyzed.
+func addStackMetadata(projects []models.TypedProject, stacks []terramateStack) {
+	byDir := make(map[string]terramateStack, len(stacks))
+	for _, stack := range stacks {
+		byDir[filepath.Clean(stack.Dir)] = stack
+	}
+	matched := make(map[string]bool, len(stacks))
+	for i := range projects {
+		dir := filepath.Clean(projects[i].Dir)
+		stack, ok := byDir[dir]
+		if !ok {
+			continue
+		}
+		matched[dir] = true
+		for _, tag := range stack.Tags {
+			if !slices.Contains(projects[i].Tags, tag) {
+				projects[i].Tags = append(projects[i].Tags, tag)
+			}
+		}
+		if projects[i].Name == "" {
+			projects[i].Name = stack.Name
+		}
+	}
+	for _, stack := range stacks {
+		if !matched[filepath.Clean(stack.Dir)] {
+			log.Warn().Msgf("Terramate stack %s matches no project rule and is not analyzed", stack.Dir)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package discover
+
+import (
+	"driftive/pkg/config/repo"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeContent(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadTerramateStack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stack.tm.hcl")
+	writeContent(t, path, `
+# stack {
+#   name = "commented"
+# }
+stack {
+  name        = "network-prod"
+  description = "Core network, see {docs}"
+  tags        = ["prod", "network"]
+  after       = ["/stacks/iam"]
+}
+`)
+
+	stack, ok := readTerramateStack(path)
+
+	if !ok {
+		t.Fatal("expected a stack")
+	}
+	if stack.Name != "network-prod" || !slices.Equal(stack.Tags, []string{"prod", "network"}) {
+		t.Errorf("readTerramateStack() = %+v", stack)
+	}
+}
+
+func TestReadTerramateFileWithoutStack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terramate.tm.hcl")
+	writeContent(t, path, `terramate {
+  config {
+    git {
+      default_branch = "main"
+    }
+  }
+}
+`)
+
+	if _, ok := readTerramateStack(path); ok {
+		t.Error("expected no stack in a file with only terramate config")
+	}
+}
+
+// TestTerramateStacksAreDiscovered covers a repository whose stacks sit next to shared .tf files
+// that must not become projects: with no inclusions, only the stacks are analyzed.
+func TestTerramateStacksAreDiscovered(t *testing.T) {
+	root := t.TempDir()
+	writeContent(t, filepath.Join(root, "stacks", "vpc", "stack.tm.hcl"), `stack {
+  name = "vpc"
+  tags = ["prod"]
+}`)
+	writeFile(t, filepath.Join(root, "stacks", "vpc", "main.tf"))
+	writeContent(t, filepath.Join(root, "stacks", "dns", "stack.tm"), `stack {}`)
+	writeFile(t, filepath.Join(root, "stacks", "dns", "main.tf"))
+	writeContent(t, filepath.Join(root, "stacks", "empty", "stack.tm.hcl"), `stack {}`)
+	writeFile(t, filepath.Join(root, "shared", "providers.tf"))
+	writeContent(t, filepath.Join(root, "terramate.tm.hcl"), `terramate {}`)
+
+	cfg := repo.DefaultRepoConfig()
+	cfg.AutoDiscover.Inclusions = nil
+	cfg.AutoDiscover.Terramate.Enabled = true
+
+	projects := AutoDiscoverProjects(root, cfg)
+
+	if len(projects) != 2 {
+		t.Fatalf("expected the 2 stacks with terraform files, got %+v", projects)
+	}
+	dns, vpc := projects[0], projects[1]
+	if dns.Dir != filepath.Join(root, "stacks", "dns") || dns.Name != "" || dns.Tags != nil {
+		t.Errorf("unexpected dns project: %+v", dns)
+	}
+	if vpc.Dir != filepath.Join(root, "stacks", "vpc") || vpc.Name != "vpc" || !slices.Equal(vpc.Tags, []string{"prod"}) {
+		t.Errorf("unexpected vpc project: %+v", vpc)
+	}
+}
+
+func TestTerramateStacksHonorExclusions(t *testing.T) {
+	root := t.TempDir()
+	writeContent(t, filepath.Join(root, "stacks", "sandbox", "stack.tm.hcl"), `stack {}`)
+	writeFile(t, filepath.Join(root, "stacks", "sandbox", "main.tf"))
+
+	stacks := terramateStacks(root, []string{"**/sandbox/**"})
+
+	if len(stacks) != 0 {
+		t.Errorf("expected the excluded stack to be skipped, got %+v", stacks)
+	}
+}
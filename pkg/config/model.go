@@ -14,10 +14,17 @@ type DriftiveConfig struct {
 	LogLevel string `json:"log_level" yaml:"log_level"`
 	ExitCode bool   `json:"exit_code" yaml:"exit_code"`
 
-	EnableStdoutResult bool   `json:"stdout_result" yaml:"stdout_result"`
-	SlackWebhookUrl    string `json:"slack_webhook_url" yaml:"slack_webhook_url"`
-	GithubToken        string `json:"github_token" yaml:"github_token"`
-	GithubContext      *gh.GithubActionContext
+	EnableStdoutResult   bool   `json:"stdout_result" yaml:"stdout_result"`
+	SlackWebhookUrl      string `json:"slack_webhook_url" yaml:"slack_webhook_url"`
+	TeamsWebhookUrl      string `json:"teams_webhook_url" yaml:"teams_webhook_url"`
+	DiscordWebhookUrl    string `json:"discord_webhook_url" yaml:"discord_webhook_url"`
+	MattermostWebhookUrl string `json:"mattermost_webhook_url" yaml:"mattermost_webhook_url"`
+	GithubToken          string `json:"github_token" yaml:"github_token"`
+	GithubContext        *gh.GithubActionContext
+
+	// WebhookUrls receive the JSON results of every run, signed with WebhookSecret
+	WebhookUrls   []string `json:"webhook_urls" yaml:"webhook_urls"`
+	WebhookSecret string   `json:"webhook_secret" yaml:"webhook_secret"`
 
 	DriftiveApiUrl string `json:"api_url" yaml:"api_url"`
 	DriftiveToken  string `json:"token" yaml:"token"`
@@ -28,3 +35,29 @@ type DriftiveConfig struct {
 func (c *DriftiveConfig) DriftiveAPIEnabled() bool {
 	return c.DriftiveToken != "" && c.DriftiveApiUrl != ""
 }
+
+// Output formats accepted by `driftive list --output`.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// ListConfig is the configuration for `driftive list`
+type ListConfig struct {
+	RepositoryUrl  string `json:"repository_url" yaml:"repository_url"`
+	Branch         string `json:"branch" yaml:"branch"`
+	RepositoryPath string `json:"repository_path" yaml:"repository_path"`
+
+	LogLevel string `json:"log_level" yaml:"log_level"`
+	// Output is OutputTable or OutputJSON
+	Output string `json:"output" yaml:"output"`
+}
+
+// ValidateConfig is the configuration for `driftive validate`
+type ValidateConfig struct {
+	RepositoryPath string `json:"repository_path" yaml:"repository_path"`
+	// ConfigFile is the file to validate. Defaults to the driftive.yml in RepositoryPath.
+	ConfigFile string `json:"config_file" yaml:"config_file"`
+	// PrintSchema prints the JSON Schema of driftive.yml instead of validating anything
+	PrintSchema bool `json:"print_schema" yaml:"print_schema"`
+}
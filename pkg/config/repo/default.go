@@ -1,5 +1,27 @@
 package repo
 
+import "time"
+
+// DefaultRetryBackoff is the wait before the first retry when settings.retry.backoff is unset.
+const DefaultRetryBackoff = 10 * time.Second
+
+// DefaultRetryPatterns match the transient failures retried when settings.retry.patterns is
+// unset: registry and network timeouts, state locks held by another run, and throttled cloud
+// APIs.
+var DefaultRetryPatterns = []string{
+	`Error acquiring the state lock`,
+	`TLS handshake timeout`,
+	`i/o timeout`,
+	`connection reset by peer`,
+	`Client\.Timeout exceeded`,
+	`context deadline exceeded`,
+	`Failed to query available provider packages`,
+	`could not connect to registry`,
+	`(?i)status ?code:? ?429`,
+	`429 Too Many Requests`,
+	`(?i)rate exceeded|throttling`,
+}
+
 func DefaultRepoConfig() *DriftiveRepoConfig {
 	return &DriftiveRepoConfig{
 		GitHub: DriftiveRepoConfigGitHub{
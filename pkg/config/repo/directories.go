@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"driftive/pkg/utils"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/patternmatcher"
+)
+
+// repoConfigFiles are the names of the repository config, and of the fragments in
+// subdirectories, in order of precedence.
+var repoConfigFiles = []string{"driftive.yml", "driftive.yaml"}
+
+// loadDirectoryConfigs reads the driftive.yml fragments in the subdirectories of repoDir,
+// parents before their children. Like auto-discovery, it does not search .git, dependency caches
+// and the dirs matched by exclusions.
+func loadDirectoryConfigs(repoDir string, exclusions []string) ([]DirectoryConfig, []Problem) {
+	excluded, err := patternmatcher.New(exclusions)
+	if err != nil {
+		return nil, []Problem{{Err: ErrInvalidYAML, Message: "cannot parse auto_discover.exclusions: " + err.Error()}}
+	}
+
+	var dirs []DirectoryConfig
+	var problems []Problem
+	err = filepath.WalkDir(repoDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() || path == repoDir {
+			return nil
+		}
+		if entry.Name() == ".git" || utils.IsPartOfCacheFolder(path) {
+			return filepath.SkipDir
+		}
+		file := findConfigFile(path)
+		if file == "" {
+			return nil
+		}
+		// Matched against the file, as auto-discovery matches the files it finds.
+		if skip, err := excluded.MatchesOrParentMatches(file); err != nil || skip {
+			return err
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		dir, fileProblems := decodeDirectoryConfig(content, file)
+		problems = append(problems, fileProblems...)
+		if rel, err := filepath.Rel(repoDir, path); err == nil {
+			dir.Dir = rel
+		}
+		dirs = append(dirs, dir)
+		return nil
+	})
+	if err != nil {
+		problems = append(problems, Problem{Err: ErrInvalidYAML, Message: "cannot read the driftive.yml fragments: " + err.Error()})
+	}
+	return dirs, problems
+}
+
+// decodeDirectoryConfig strictly decodes the fragment read from file.
+func decodeDirectoryConfig(content []byte, file string) (DirectoryConfig, []Problem) {
+	dir := DirectoryConfig{file: file}
+	root, problems := parseDocument(content, file, &dir)
+	if root == nil {
+		return dir, problems
+	}
+	dir.root = root
+	return dir, append(problems, decodeNode(root, &dir, file)...)
+}
+
+// findConfigFile returns the driftive.yml or driftive.yaml in dir, or "" when there is none.
+func findConfigFile(dir string) string {
+	for _, name := range repoConfigFiles {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// Contains reports whether the project in projectDir, relative to the repository root, is
+// under the fragment's directory.
+func (d DirectoryConfig) Contains(projectDir string) bool {
+	rel, err := filepath.Rel(d.Dir, projectDir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,125 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is a repository config parsed from a file laid over the files it extends.
+type document struct {
+	root *yaml.Node
+	// files maps every node to the file it was read from.
+	files map[*yaml.Node]string
+}
+
+// load parses content, read from file, and lays it over the config its extends key points to.
+// It returns nil when content cannot be parsed.
+func (d *document) load(content []byte, file, dir string, seen map[string]bool) (*yaml.Node, []Problem) {
+	root, problems := parseDocument(content, file, &DriftiveRepoConfig{})
+	if root == nil {
+		return nil, problems
+	}
+	d.markFile(root, file)
+
+	extends := mappingValue(root, "extends")
+	if extends == nil || extends.Value == "" {
+		return root, problems
+	}
+	path := extends.Value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	extendsProblem := func(format string, args ...any) Problem {
+		return Problem{Err: ErrInvalidExtends, File: file, Path: "extends", Message: fmt.Sprintf(format, args...),
+			Line: extends.Line, Column: extends.Column}
+	}
+	if seen[path] {
+		return root, append(problems, extendsProblem("%s is extended more than once in a chain of extends", path))
+	}
+	seen[path] = true
+	baseContent, err := os.ReadFile(path)
+	if err != nil {
+		return root, append(problems, extendsProblem("cannot read %s: %v", path, err))
+	}
+	base, baseProblems := d.load(baseContent, path, filepath.Dir(path), seen)
+	problems = append(baseProblems, problems...)
+	if base == nil {
+		return root, problems
+	}
+	return d.merge(base, root), problems
+}
+
+// merge lays override over base: mappings are merged key by key, and any other value in override
+// replaces the one in base, so a list is replaced rather than appended to.
+func (d *document) merge(base, override *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || override.Kind != yaml.MappingNode {
+		return override
+	}
+	merged := *base
+	merged.Content = slices.Clone(base.Content)
+	d.files[&merged] = d.files[base]
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], override.Content[i+1]
+		j := keyIndex(&merged, key.Value)
+		if j < 0 {
+			merged.Content = append(merged.Content, key, value)
+			continue
+		}
+		merged.Content[j] = key
+		merged.Content[j+1] = d.merge(merged.Content[j+1], value)
+	}
+	return &merged
+}
+
+func (d *document) markFile(node *yaml.Node, file string) {
+	d.files[node] = file
+	for _, child := range node.Content {
+		d.markFile(child, file)
+	}
+}
+
+// singleFile returns the file the document was read from, or "" when it extends others.
+func (d *document) singleFile() string {
+	file := ""
+	for _, f := range d.files {
+		if file != "" && f != file {
+			return ""
+		}
+		file = f
+	}
+	return file
+}
+
+// locate fills in where in the document the value at p.Path is.
+func (d *document) locate(p *Problem) {
+	if p.Line > 0 || d.root == nil {
+		return
+	}
+	node := locateNode(d.root, p.Path)
+	p.File, p.Line, p.Column = d.files[node], node.Line, node.Column
+}
+
+// mappingValue returns the value of key in the mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if i := keyIndex(node, key); i >= 0 {
+		return node.Content[i+1]
+	}
+	return nil
+}
+
+// keyIndex returns the index of key among the contents of the mapping node, or -1.
+func keyIndex(node *yaml.Node, key string) int {
+	if node.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,191 @@
+package repo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRepoConfigLaysTheFileOverTheOneItExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "org", "baseline.yml"), `github:
+  issues:
+    enabled: true
+    labels: [drift]
+    max_open_issues: 3
+settings:
+  timeout: 30m
+`)
+	writeFile(t, filepath.Join(dir, "repo", "driftive.yml"), `extends: ../org/baseline.yml
+github:
+  issues:
+    labels: [drift, team-a]
+`)
+
+	cfg, err := loadRepoConfig(filepath.Join(dir, "repo", "driftive.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.GitHub.Issues.Enabled || cfg.GitHub.Issues.MaxOpenIssues != 3 || cfg.Settings.Timeout.String() != "30m0s" {
+		t.Errorf("settings of the extended file should be kept, got %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.GitHub.Issues.Labels, []string{"drift", "team-a"}) {
+		t.Errorf("Labels = %v, want the list of the extending file", cfg.GitHub.Issues.Labels)
+	}
+}
+
+func TestExtendsProblemsNameTheirFile(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yml")
+	writeFile(t, base, "drift:\n  mode: sometimes\nsetings: {}\n")
+	writeFile(t, filepath.Join(dir, "driftive.yml"), "extends: base.yml\ndrift:\n  ignore:\n    resources: [aws_instance.*]\n")
+
+	problems, err := ValidateFile(filepath.Join(dir, "driftive.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Problem{
+		{Err: ErrInvalidDriftMode, File: base, Path: "drift.mode", Line: 2, Column: 3},
+		{Err: ErrUnknownKey, File: base, Path: "setings", Line: 3, Column: 1},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("problems = %+v, want %d", problems, len(want))
+	}
+	for i, w := range want {
+		p := problems[i]
+		if p.Err != w.Err || p.File != w.File || p.Path != w.Path || p.Line != w.Line || p.Column != w.Column {
+			t.Errorf("problems[%d] = %+v, want %+v", i, p, w)
+		}
+	}
+}
+
+func TestExtendsCycleIsAProblem(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yml"), "extends: b.yml\n")
+	writeFile(t, filepath.Join(dir, "b.yml"), "extends: a.yml\n")
+
+	_, err := loadRepoConfig(filepath.Join(dir, "a.yml"))
+	configErr, ok := err.(*ConfigError)
+	if !ok || len(configErr.Problems) != 1 || configErr.Problems[0].Err != ErrInvalidExtends {
+		t.Fatalf("loadRepoConfig() error = %v, want an extends cycle", err)
+	}
+}
+
+func TestExtendsMissingFileIsAProblem(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "driftive.yml"), "auto_discover:\n  enabled: true\nextends: nowhere.yml\n")
+
+	problems, err := ValidateFile(filepath.Join(dir, "driftive.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || problems[0].Err != ErrInvalidExtends || problems[0].Line != 3 {
+		t.Errorf("problems = %+v, want one invalid extends on line 3", problems)
+	}
+}
+
+func TestDetectRepoConfigLoadsDirectoryFragments(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "driftive.yml"), "auto_discover:\n  enabled: true\n")
+	writeFile(t, filepath.Join(dir, "teams", "a", "driftive.yml"), "executable: tofu\nlabels: [team-a]\n")
+	writeFile(t, filepath.Join(dir, "teams", "driftive.yaml"), "timeout: 10m\n")
+	writeFile(t, filepath.Join(dir, ".terraform", "driftive.yml"), "nonsense: true\n")
+
+	cfg, err := DetectRepoConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Directories) != 2 {
+		t.Fatalf("Directories = %+v, want 2", cfg.Directories)
+	}
+	teams, teamA := cfg.Directories[0], cfg.Directories[1]
+	if teams.Dir != "teams" || teams.Timeout.String() != "10m0s" {
+		t.Errorf("Directories[0] = %+v, want the teams fragment first", teams)
+	}
+	if teamA.Dir != filepath.Join("teams", "a") || teamA.Executable != "tofu" || !reflect.DeepEqual(teamA.Labels, []string{"team-a"}) {
+		t.Errorf("Directories[1] = %+v", teamA)
+	}
+}
+
+func TestDetectRepoConfigSkipsExcludedFragments(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "driftive.yml"), "auto_discover:\n  exclusions: ['**/vendor/**']\n")
+	writeFile(t, filepath.Join(dir, "infra", "driftive.yml"), "timeout: 10m\n")
+	writeFile(t, filepath.Join(dir, "infra", "vendor", "driftive.yml"), "nonsense: true\n")
+	writeFile(t, filepath.Join(dir, "infra", ".terragrunt-cache", "x", "driftive.yml"), "nonsense: true\n")
+
+	cfg, err := DetectRepoConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Directories) != 1 || cfg.Directories[0].Dir != "infra" {
+		t.Errorf("Directories = %+v, want only the infra fragment", cfg.Directories)
+	}
+}
+
+func TestDetectRepoConfigAppliesFragmentsWithoutRootConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "infra", "driftive.yml"), "executable: tofu\n")
+
+	cfg, err := DetectRepoConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Directories) != 1 || cfg.Directories[0].Executable != "tofu" {
+		t.Errorf("Directories = %+v, want the infra fragment", cfg.Directories)
+	}
+	if !reflect.DeepEqual(cfg.AutoDiscover, DefaultRepoConfig().AutoDiscover) {
+		t.Errorf("AutoDiscover = %+v, want the default", cfg.AutoDiscover)
+	}
+
+	if _, err := DetectRepoConfig(t.TempDir()); !errors.Is(err, ErrMissingRepoConfig) {
+		t.Errorf("DetectRepoConfig() error = %v, want ErrMissingRepoConfig without any config", err)
+	}
+}
+
+func TestValidateDirectoriesLocatesProblems(t *testing.T) {
+	dir := t.TempDir()
+	fragment := filepath.Join(dir, "infra", "driftive.yml")
+	writeFile(t, fragment, "labels: [ok]\nexecutable: terrafrom\nlabelz: []\n")
+
+	_, problems := ValidateDirectories(dir, filepath.Join(dir, "driftive.yml"))
+	want := []Problem{
+		{Err: ErrInvalidProject, File: fragment, Path: "executable", Line: 2, Column: 1},
+		{Err: ErrUnknownKey, File: fragment, Path: "labelz", Line: 3, Column: 1},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("problems = %+v, want %d", problems, len(want))
+	}
+	for i, w := range want {
+		p := problems[i]
+		if p.Err != w.Err || p.File != w.File || p.Path != w.Path || p.Line != w.Line || p.Column != w.Column {
+			t.Errorf("problems[%d] = %+v, want %+v", i, p, w)
+		}
+	}
+}
+
+func TestDirectoryConfigContains(t *testing.T) {
+	dir := DirectoryConfig{Dir: "teams"}
+	for projectDir, want := range map[string]bool{
+		"teams":                     true,
+		filepath.Join("teams", "a"): true,
+		"teams-b":                   false,
+		"other":                     false,
+	} {
+		if got := dir.Contains(projectDir); got != want {
+			t.Errorf("Contains(%q) = %v, want %v", projectDir, got, want)
+		}
+	}
+}
@@ -1,10 +1,11 @@
 package repo
 
 import (
-	"driftive/pkg/utils"
+	"errors"
 	"github.com/rs/zerolog/log"
-	"gopkg.in/yaml.v3"
 	"os"
+	"path/filepath"
+	"sort"
 )
 
 func loadRepoConfig(filePath string) (*DriftiveRepoConfig, error) {
@@ -18,7 +19,7 @@ func loadRepoConfig(filePath string) (*DriftiveRepoConfig, error) {
 			SkipIfOpenPR: false,
 		},
 	}
-	err = yaml.Unmarshal(fileContent, cfg)
+	_, err = decodeStrict(fileContent, filePath, filepath.Dir(filePath), cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -38,23 +39,136 @@ func loadRepoConfig(filePath string) (*DriftiveRepoConfig, error) {
 	return cfg, nil
 }
 
+// DetectRepoConfig loads the repository config from the DRIFTIVE_REPO_CONFIG environment
+// variable or the driftive.yml in repoDir, along with the files it extends and the driftive.yml
+// fragments in repoDir's subdirectories. Without a root config, fragments apply over the default
+// config; ErrMissingRepoConfig is only returned when there are no fragments either.
 func DetectRepoConfig(repoDir string) (*DriftiveRepoConfig, error) {
+	cfg, err := detectRootConfig(repoDir)
+	missing := errors.Is(err, ErrMissingRepoConfig)
+	if err != nil && !missing {
+		return nil, err
+	}
+	if missing {
+		cfg = DefaultRepoConfig()
+	}
+	dirs, problems := loadDirectoryConfigs(repoDir, cfg.AutoDiscover.Exclusions)
+	if len(problems) > 0 {
+		return nil, &ConfigError{Problems: problems}
+	}
+	if missing {
+		if len(dirs) == 0 {
+			return nil, ErrMissingRepoConfig
+		}
+		log.Info().Msgf("No driftive.yml in %s. Applying the driftive.yml fragments over the default config.", repoDir)
+	}
+	for _, dir := range dirs {
+		log.Info().Msgf("Loaded driftive.yml fragment of %s", dir.Dir)
+	}
+	cfg.Directories = dirs
+	return cfg, nil
+}
+
+func detectRootConfig(repoDir string) (*DriftiveRepoConfig, error) {
 	if os.Getenv("DRIFTIVE_REPO_CONFIG") != "" {
 		log.Info().Msg("Loading repo config from DRIFTIVE_REPO_CONFIG environment variable")
 		envConfigStr := os.Getenv("DRIFTIVE_REPO_CONFIG")
 		cfg := &DriftiveRepoConfig{}
-		err := yaml.Unmarshal([]byte(envConfigStr), cfg)
+		_, err := decodeStrict([]byte(envConfigStr), "DRIFTIVE_REPO_CONFIG", repoDir, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return cfg, nil
 	}
 
-	if _, err := os.Stat(repoDir + utils.PathSeparator + "driftive.yml"); err == nil {
-		return loadRepoConfig(repoDir + utils.PathSeparator + "driftive.yml")
-	}
-	if _, err := os.Stat(repoDir + utils.PathSeparator + "driftive.yaml"); err == nil {
-		return loadRepoConfig(repoDir + utils.PathSeparator + "driftive.yaml")
+	if path := FindRepoConfig(repoDir); path != "" {
+		return loadRepoConfig(path)
 	}
 	return nil, ErrMissingRepoConfig
 }
+
+// FindRepoConfig returns the path of the repository config in repoDir, driftive.yml or
+// driftive.yaml. Empty when there is none.
+func FindRepoConfig(repoDir string) string {
+	return findConfigFile(repoDir)
+}
+
+// ValidateFile decodes the repository config at path, with the files it extends, and validates
+// it, returning every problem with its position. The error is only set when the file cannot be
+// read.
+func ValidateFile(path string) ([]Problem, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return sortProblems(validateContent(content, path, filepath.Dir(path))), nil
+}
+
+// ValidateDirectories decodes and validates the driftive.yml fragments in the subdirectories of
+// repoDir, returning how many there are and every problem with its position. The dirs excluded
+// from auto-discovery by the repository config at configPath, or by the default config when
+// configPath is empty, are not searched.
+func ValidateDirectories(repoDir, configPath string) (int, []Problem) {
+	dirs, problems := loadDirectoryConfigs(repoDir, configExclusions(configPath))
+	v := &validator{}
+	for _, dir := range dirs {
+		if dir.root != nil {
+			v.validateDirectory(dir)
+		}
+	}
+	return len(dirs), sortProblems(append(problems, v.problems...))
+}
+
+// configExclusions returns the auto_discover exclusions of the repository config at path, or
+// the default ones when there is none or it cannot be decoded. Its problems are reported by
+// ValidateFile.
+func configExclusions(path string) []string {
+	cfg := &DriftiveRepoConfig{}
+	if path == "" {
+		return DefaultRepoConfig().AutoDiscover.Exclusions
+	}
+	if content, err := os.ReadFile(path); err == nil {
+		if doc, _ := decodeStrict(content, path, filepath.Dir(path), cfg); doc != nil {
+			return cfg.AutoDiscover.Exclusions
+		}
+	}
+	return DefaultRepoConfig().AutoDiscover.Exclusions
+}
+
+func validateContent(content []byte, file, dir string) []Problem {
+	cfg := &DriftiveRepoConfig{}
+	doc, err := decodeStrict(content, file, dir, cfg)
+	var problems []Problem
+	if err != nil {
+		var configErr *ConfigError
+		if !errors.As(err, &configErr) {
+			return []Problem{{Err: ErrInvalidYAML, File: file, Message: err.Error()}}
+		}
+		problems = configErr.Problems
+		if doc == nil {
+			return problems
+		}
+	}
+	// Unknown keys leave the known ones decoded, so their values are still worth checking.
+	for _, problem := range Validate(cfg) {
+		doc.locate(&problem)
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// sortProblems orders problems by file, then line. Problems without a position go last in
+// their file.
+func sortProblems(problems []Problem) []Problem {
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i], problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line == 0 || b.Line == 0 {
+			return b.Line == 0 && a.Line != 0
+		}
+		return a.Line < b.Line
+	})
+	return problems
+}
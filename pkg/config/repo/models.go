@@ -1,8 +1,136 @@
 package repo
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
 type AutoDiscoverRule struct {
 	Pattern    string `json:"pattern" yaml:"pattern"`
-	Executable string `json:"executable" yaml:"executable" validate:"omitempty,oneof=terraform tofu terragrunt"`
+	Executable string `json:"executable" yaml:"executable" validate:"omitempty,oneof=terraform tofu terragrunt pulumi"`
+	// DriftMode overrides drift.mode for projects matching this rule
+	DriftMode string `json:"drift_mode,omitempty" yaml:"drift_mode,omitempty" validate:"omitempty,oneof=plan refresh-only both"`
+	// ProjectArgs are templated per matched project. See ProjectArgs.
+	ProjectArgs `yaml:",inline"`
+	// ProjectEnv is the environment of the matched projects' commands
+	ProjectEnv `yaml:",inline"`
+	// Timeout overrides settings.timeout for projects matching this rule
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Name is the matched projects' name, templated like ProjectArgs, e.g. prod/${dir_name}
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// ProjectMetadata describes the matched projects
+	ProjectMetadata `yaml:",inline"`
+}
+
+// Severities are the values severity accepts, from the least to the most severe.
+var Severities = []string{"low", "medium", "high", "critical"}
+
+// Executables are the values executable accepts.
+var Executables = []string{"terraform", "tofu", "terragrunt", "pulumi"}
+
+// DriftModes are the values drift.mode and drift_mode accept.
+var DriftModes = []string{"plan", "refresh-only", "both"}
+
+// ProjectMetadata describes projects to the people notified about them. It is shown in issues
+// and notifications, and sent to the Driftive API.
+type ProjectMetadata struct {
+	// Owners are the teams or people responsible for the projects, e.g. @org/payments
+	Owners []string `json:"owners,omitempty" yaml:"owners,omitempty"`
+	// Tags label the projects
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Severity ranks the projects' drift: low, medium, high or critical
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty" validate:"omitempty,oneof=low medium high critical"`
+}
+
+// ProjectEnv is the environment of a project's commands.
+type ProjectEnv struct {
+	// Env is extra environment, e.g. AWS_PROFILE or TF_VAR_region. It overrides the host's
+	// variables and settings.env.
+	Env map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	// EnvPassthrough, when set, restricts the host variables passed through to the named ones
+	// (PATH and HOME are always passed), so a project cannot pick up another account's
+	// credentials. Unset inherits the whole host environment.
+	EnvPassthrough []string `json:"env_passthrough,omitempty" yaml:"env_passthrough,omitempty"`
+}
+
+// ProjectArgs are extra CLI arguments for a project. Values may reference ${dir} (the project
+// dir relative to the repository root), ${dir_name} (its last element) and ${root} (the
+// absolute repository root), so one rule can pick e.g. ${root}/envs/${dir_name}.tfvars.
+type ProjectArgs struct {
+	// InitArgs are extra arguments appended to init
+	InitArgs []string `json:"init_args,omitempty" yaml:"init_args,omitempty"`
+	// PlanArgs are extra arguments appended to plan
+	PlanArgs []string `json:"plan_args,omitempty" yaml:"plan_args,omitempty"`
+	// VarFiles are passed to plan as -var-file, relative to the project dir
+	VarFiles []string `json:"var_files,omitempty" yaml:"var_files,omitempty"`
+	// BackendConfig entries are passed to init as -backend-config: a file or a key=value pair
+	BackendConfig []string `json:"backend_config,omitempty" yaml:"backend_config,omitempty"`
+}
+
+// ProjectConfig declares a project explicitly, for stacks that no auto-discovery glob matches
+// cleanly. An explicit project replaces any auto-discovered project in the same dir.
+type ProjectConfig struct {
+	// Dir is the project directory, relative to the repository root
+	Dir string `json:"dir" yaml:"dir" validate:"required"`
+	// Executable is the tool that plans the project
+	Executable string `json:"executable" yaml:"executable" validate:"required,oneof=terraform tofu terragrunt pulumi"`
+	// Name is a human-readable name shown instead of the dir
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Workspace is the Terraform workspace to plan, passed as TF_WORKSPACE, or the Pulumi stack
+	// to preview. A Pulumi project that sets neither this nor Workspaces previews every stack.
+	Workspace string `json:"workspace,omitempty" yaml:"workspace,omitempty"`
+	// Workspaces plans the project once per listed workspace, or once per workspace the
+	// backend has with `all`. Mutually exclusive with Workspace.
+	Workspaces WorkspaceList `json:"workspaces,omitempty" yaml:"workspaces,omitempty"`
+	// DriftMode overrides drift.mode for this project
+	DriftMode string `json:"drift_mode,omitempty" yaml:"drift_mode,omitempty" validate:"omitempty,oneof=plan refresh-only both"`
+	// ProjectArgs are extra CLI arguments, templated like a rule's
+	ProjectArgs `yaml:",inline"`
+	// ProjectEnv is the environment of the project's commands
+	ProjectEnv `yaml:",inline"`
+	// Timeout overrides settings.timeout for this project
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// ProjectMetadata describes the project
+	ProjectMetadata `yaml:",inline"`
+}
+
+// WorkspaceList is either a list of workspace names or the scalar `all`.
+type WorkspaceList struct {
+	Names []string
+	All   bool
+}
+
+func (w *WorkspaceList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value != "all" {
+			return fmt.Errorf("line %d: workspaces must be a list or 'all', got '%s'", node.Line, node.Value)
+		}
+		w.All = true
+		return nil
+	}
+	return node.Decode(&w.Names)
+}
+
+func (w WorkspaceList) MarshalYAML() (interface{}, error) {
+	if w.All {
+		return "all", nil
+	}
+	return w.Names, nil
+}
+
+func (w WorkspaceList) MarshalJSON() ([]byte, error) {
+	if w.All {
+		return json.Marshal("all")
+	}
+	return json.Marshal(w.Names)
+}
+
+// IsZero lets omitempty drop an unset list.
+func (w WorkspaceList) IsZero() bool {
+	return !w.All && len(w.Names) == 0
 }
 
 type DriftiveRepoConfigGitHubIssuesErrors struct {
@@ -44,25 +172,144 @@ type DriftiveRepoConfigGitHub struct {
 // DriftiveRepoConfig is used to configure driftive for a repository.
 // It may be defined in a .driftive.yaml file in the repository or passed via environment variable.
 type DriftiveRepoConfig struct {
+	// Extends is a config file this one is laid over, e.g. an organization-wide baseline.
+	// A relative path is resolved against the directory of the file that sets it.
+	Extends      string                         `json:"extends,omitempty" yaml:"extends,omitempty"`
 	AutoDiscover DriftiveRepoConfigAutoDiscover `json:"auto_discover" yaml:"auto_discover"`
+	Projects     []ProjectConfig                `json:"projects" yaml:"projects"`
 	GitHub       DriftiveRepoConfigGitHub       `json:"github" yaml:"github"`
+	Drift        DriftiveRepoConfigDrift        `json:"drift" yaml:"drift"`
 	Settings     DriftiveRepoConfigSettings     `json:"settings" yaml:"settings"`
+	// Directories are the driftive.yml fragments found in subdirectories of the repository,
+	// parents before their children. They are not part of the root file.
+	Directories []DirectoryConfig `json:"-" yaml:"-"`
+}
+
+// DirectoryConfig overrides settings for the projects under a subdirectory. It is read from a
+// driftive.yml in that subdirectory.
+type DirectoryConfig struct {
+	// Dir is the subdirectory, relative to the repository root
+	Dir string `json:"dir" yaml:"-"`
+	// Executable overrides the executable project rules picked for the projects
+	Executable string `json:"executable,omitempty" yaml:"executable,omitempty" validate:"omitempty,oneof=terraform tofu terragrunt pulumi"`
+	// DriftMode overrides drift.mode for the projects
+	DriftMode string `json:"drift_mode,omitempty" yaml:"drift_mode,omitempty" validate:"omitempty,oneof=plan refresh-only both"`
+	// ProjectArgs override the projects' arguments, templated like a rule's
+	ProjectArgs `yaml:",inline"`
+	// ProjectEnv is added to the projects' environment
+	ProjectEnv `yaml:",inline"`
+	// Timeout overrides settings.timeout for the projects
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Labels are added to the labels of the projects' drift issues
+	Labels []string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Ignore lists changes of the projects that never count as drift, on top of drift.ignore
+	Ignore DriftiveRepoConfigDriftIgnore `json:"ignore,omitempty" yaml:"ignore,omitempty"`
+	// ProjectMetadata describes the projects. Owners and severity override a rule's, tags are
+	// added to them
+	ProjectMetadata `yaml:",inline"`
+
+	// file and root locate validation problems in the fragment.
+	file string
+	root *yaml.Node
+}
+
+// DriftiveRepoConfigDrift is used to configure how drift is decided from a plan
+type DriftiveRepoConfigDrift struct {
+	// Mode selects the plans run to detect drift: plan (default), refresh-only or both
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty" validate:"omitempty,oneof=plan refresh-only both"`
+	// Ignore lists changes that never count as drift
+	Ignore DriftiveRepoConfigDriftIgnore `json:"ignore" yaml:"ignore"`
+}
+
+// DriftiveRepoConfigDriftIgnore lists resource changes to drop before deciding whether a project drifted.
+// Globs support '*' (any sequence) and '?' (any character), and also match everything nested under
+// the matched address or attribute: 'module.legacy' covers every resource in that module, 'tags'
+// covers every tag.
+type DriftiveRepoConfigDriftIgnore struct {
+	// Resources list of resource address globs, e.g. aws_autoscaling_group.*
+	Resources []string `json:"resources" yaml:"resources"`
+	// ResourceTypes list of resource types whose changes are ignored, e.g. aws_autoscaling_group
+	ResourceTypes []string `json:"resource_types" yaml:"resource_types"`
+	// Attributes list of attribute path globs, e.g. tags.LastModified. An update whose changed
+	// attributes all match is ignored.
+	Attributes []string `json:"attributes" yaml:"attributes"`
 }
 
 // DriftiveRepoConfigSettings is used to configure driftive settings for a repository
 type DriftiveRepoConfigSettings struct {
 	// SkipIfOpenPR is used to skip drift notifications if there are open PRs modifying the drifted files
 	SkipIfOpenPR bool `json:"skip_if_open_pr,omitempty" yaml:"skip_if_open_pr,omitempty"`
+	// ProjectEnv is the environment of every project's commands. A project's own env is added
+	// on top, and its env_passthrough replaces this one.
+	ProjectEnv `yaml:",inline"`
+	// Timeout bounds each project's analysis, e.g. 30m. A project running longer is killed
+	// and reported as timed out. Zero means no timeout.
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Retry reruns projects that failed with a transient error
+	Retry DriftiveRepoConfigRetry `json:"retry,omitempty" yaml:"retry,omitempty"`
+	// PluginCache shares downloaded providers between projects
+	PluginCache DriftiveRepoConfigPluginCache `json:"plugin_cache,omitempty" yaml:"plugin_cache,omitempty"`
+	// HonorLockFile runs init without -upgrade, so providers stay at the versions pinned in
+	// .terraform.lock.hcl
+	HonorLockFile bool `json:"honor_lock_file,omitempty" yaml:"honor_lock_file,omitempty"`
+	// BinariesDir holds installed terraform and tofu versions as <dir>/<tool>/<version>/<tool>.
+	// When set, each project runs the installed version it asks for in its version files or
+	// required_version.
+	BinariesDir string `json:"binaries_dir,omitempty" yaml:"binaries_dir,omitempty"`
 }
 
+// DriftiveRepoConfigPluginCache is used to share a provider plugin cache (TF_PLUGIN_CACHE_DIR)
+// between projects, so each provider is downloaded once per run rather than once per project
+type DriftiveRepoConfigPluginCache struct {
+	// Enabled is used to enable or disable the shared plugin cache
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// Dir is the cache directory. Defaults to driftive/plugin-cache under the user cache dir.
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty"`
+}
+
+// DriftiveRepoConfigRetry is used to retry projects whose init or plan failed with a transient
+// error, e.g. a registry timeout or a throttled cloud API
+type DriftiveRepoConfigRetry struct {
+	// Attempts is the maximum number of runs per project, the first one included. 0 or 1
+	// disables retries.
+	Attempts int `json:"attempts,omitempty" yaml:"attempts,omitempty"`
+	// Backoff is the wait before the first retry, doubled before each further one up to 5
+	// minutes. Defaults to DefaultRetryBackoff.
+	Backoff time.Duration `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	// Patterns list of regular expressions matched against the failure output. Only a failure
+	// matching one of them is retried. Defaults to DefaultRetryPatterns.
+	Patterns []string `json:"patterns,omitempty" yaml:"patterns,omitempty"`
+}
+
+// Discovery sources accepted by auto_discover.source.
+const (
+	// DiscoverySourceFiles discovers projects from the files matching the inclusions. The default.
+	DiscoverySourceFiles = "files"
+	// DiscoverySourceAtlantis reads the projects declared in the repository's atlantis.yaml.
+	DiscoverySourceAtlantis = "atlantis"
+)
+
 // DriftiveRepoConfigAutoDiscover is used to configure auto discovery of projects in a repository
 type DriftiveRepoConfigAutoDiscover struct {
 	// Enabled is used to enable or disable auto discovery
 	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Source is where projects are discovered from: files (default) or atlantis. With atlantis,
+	// inclusions, exclusions, project rules and terramate are not used.
+	Source string `json:"source,omitempty" yaml:"source,omitempty" validate:"omitempty,oneof=files atlantis"`
 	// Inclusions list of glob patterns to include in auto discovery
 	Inclusions []string `json:"inclusions" yaml:"inclusions"`
 	// Exclusions list of glob patterns to exclude in auto discovery
 	Exclusions []string `json:"exclusions" yaml:"exclusions"`
 	// ProjectRules list of rules to apply to auto discovered projects
 	ProjectRules []AutoDiscoverRule `json:"project_rules" yaml:"project_rules"`
+	// Terramate discovers the stacks declared by Terramate stack blocks, on top of inclusions
+	Terramate DriftiveRepoConfigTerramate `json:"terramate,omitempty" yaml:"terramate,omitempty"`
+}
+
+// DriftiveRepoConfigTerramate is used to discover projects from Terramate stacks. Each stack dir
+// is a candidate project, matched against the project rules like the dirs of included files, and
+// its projects get the stack's name and tags
+type DriftiveRepoConfigTerramate struct {
+	// Enabled is used to enable or disable Terramate stack discovery
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
 }
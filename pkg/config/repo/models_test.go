@@ -0,0 +1,33 @@
+package repo
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWorkspaceListUnmarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    WorkspaceList
+		wantErr bool
+	}{
+		{name: "list", yaml: "workspaces: [staging, prod]", want: WorkspaceList{Names: []string{"staging", "prod"}}},
+		{name: "all", yaml: "workspaces: all", want: WorkspaceList{All: true}},
+		{name: "other scalar", yaml: "workspaces: prod", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ProjectConfig
+			err := yaml.Unmarshal([]byte(tt.yaml), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got.Workspaces, tt.want) {
+				t.Errorf("Workspaces = %+v, want %+v", got.Workspaces, tt.want)
+			}
+		})
+	}
+}
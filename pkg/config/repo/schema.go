@@ -0,0 +1,122 @@
+package repo
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// durationPattern matches the durations time.ParseDuration accepts, e.g. 30m or 1h30m.
+const durationPattern = `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+
+// schemaProvider is implemented by config types whose yaml form does not follow their fields.
+type schemaProvider interface {
+	jsonSchema() map[string]any
+}
+
+var (
+	durationType       = reflect.TypeOf(time.Duration(0))
+	schemaProviderType = reflect.TypeOf((*schemaProvider)(nil)).Elem()
+)
+
+func (WorkspaceList) jsonSchema() map[string]any {
+	return map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			map[string]any{"const": "all"},
+		},
+	}
+}
+
+// JSONSchema returns the JSON Schema of driftive.yml, generated from DriftiveRepoConfig. Like
+// the loader, it rejects unknown keys.
+func JSONSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(DriftiveRepoConfig{}), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "driftive.yml"
+	schema["description"] = "Repository configuration of driftive"
+	content, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// typeSchema returns the schema of a value of type t. validate is the value's validate tag,
+// whose oneof becomes an enum.
+func typeSchema(t reflect.Type, validate string) map[string]any {
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(schemaProvider).jsonSchema()
+	}
+	if t == durationType {
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), validate)
+	case reflect.String:
+		schema := map[string]any{"type": "string"}
+		if values := oneOf(validate); values != nil {
+			schema["enum"] = values
+		}
+		return schema
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), "")}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), "")}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	fields := yamlFields(t)
+	properties := make(map[string]any, len(fields))
+	var required []string
+	for name, field := range fields {
+		validate := field.Tag.Get("validate")
+		properties[name] = typeSchema(field.Type, validate)
+		if hasRule(validate, "required") {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// oneOf returns the values of the oneof rule in a validate tag, nil when it has none.
+func oneOf(validate string) []string {
+	for _, rule := range strings.Split(validate, ",") {
+		if values, ok := strings.CutPrefix(rule, "oneof="); ok {
+			return strings.Fields(values)
+		}
+	}
+	return nil
+}
+
+func hasRule(validate, rule string) bool {
+	for _, r := range strings.Split(validate, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
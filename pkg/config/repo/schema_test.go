@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"driftive/pkg/utils"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// TestCommittedSchemaIsUpToDate fails when a config field changes without the committed schema
+// being regenerated with `driftive validate --schema > driftive.schema.json`.
+func TestCommittedSchemaIsUpToDate(t *testing.T) {
+	committed, err := os.ReadFile(filepath.Join(utils.GetBasePath(), "driftive.schema.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(committed) != string(generated) {
+		t.Error("driftive.schema.json is out of date. Run `driftive validate --schema > driftive.schema.json`.")
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	content, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema struct {
+		AdditionalProperties bool `json:"additionalProperties"`
+		Properties           struct {
+			Projects struct {
+				Items struct {
+					Required   []string `json:"required"`
+					Properties struct {
+						Executable struct {
+							Enum []string `json:"enum"`
+						} `json:"executable"`
+						Workspaces struct {
+							OneOf []json.RawMessage `json:"oneOf"`
+						} `json:"workspaces"`
+						Timeout struct {
+							Pattern string `json:"pattern"`
+						} `json:"timeout"`
+					} `json:"properties"`
+				} `json:"items"`
+			} `json:"projects"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema.AdditionalProperties {
+		t.Error("unknown top-level keys should be rejected")
+	}
+	project := schema.Properties.Projects.Items
+	if len(project.Required) != 2 || project.Required[0] != "dir" || project.Required[1] != "executable" {
+		t.Errorf("required = %v, want [dir executable]", project.Required)
+	}
+	if len(project.Properties.Executable.Enum) != 4 {
+		t.Errorf("executable enum = %v", project.Properties.Executable.Enum)
+	}
+	if len(project.Properties.Workspaces.OneOf) != 2 {
+		t.Errorf("workspaces should accept a list or all")
+	}
+	if project.Properties.Timeout.Pattern != durationPattern {
+		t.Errorf("timeout pattern = %q", project.Properties.Timeout.Pattern)
+	}
+}
+
+// TestOneOfTagsMatchValidation keeps the enums of the schema, taken from the oneof tags, in
+// step with the values Validate accepts.
+func TestOneOfTagsMatchValidation(t *testing.T) {
+	want := map[string][]string{
+		"executable": Executables,
+		"drift_mode": DriftModes,
+		"mode":       DriftModes,
+		"severity":   Severities,
+		"source":     {DiscoverySourceFiles, DiscoverySourceAtlantis},
+	}
+
+	checked := 0
+	seen := map[reflect.Type]bool{}
+	var walk func(typ reflect.Type, path string)
+	walk = func(typ reflect.Type, path string) {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || seen[typ] {
+			return
+		}
+		seen[typ] = true
+		for name, field := range yamlFields(typ) {
+			fieldPath := joinPath(path, name)
+			if values := oneOf(field.Tag.Get("validate")); values != nil {
+				checked++
+				if !slices.Equal(values, want[name]) {
+					t.Errorf("%s: oneof = %v, want %v", fieldPath, values, want[name])
+				}
+			}
+			walk(field.Type, fieldPath)
+		}
+	}
+	walk(reflect.TypeOf(DriftiveRepoConfig{}), "")
+	walk(reflect.TypeOf(DirectoryConfig{}), "")
+
+	if checked == 0 {
+		t.Error("found no oneof tag to check")
+	}
+}
@@ -0,0 +1,293 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is one thing wrong with a repository config.
+type Problem struct {
+	// Err is the kind of problem, one of the Err* messages.
+	Err string
+	// File is the file the problem is in. Empty when unknown.
+	File string
+	// Path locates the offending value, e.g. projects[2].executable. Empty when unknown.
+	Path    string
+	Message string
+	// Line and Column locate the value in the file. Zero when unknown.
+	Line   int
+	Column int
+}
+
+func (p Problem) String() string {
+	var position []string
+	if p.File != "" {
+		position = append(position, p.File)
+	}
+	if p.Line > 0 {
+		position = append(position, fmt.Sprintf("line %d", p.Line))
+		if p.Column > 0 {
+			position = append(position, fmt.Sprintf("column %d", p.Column))
+		}
+	}
+	var b strings.Builder
+	if len(position) > 0 {
+		b.WriteString(strings.Join(position, ", ") + ": ")
+	}
+	if p.Path != "" {
+		b.WriteString(p.Path + ": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ConfigError lists every problem found while decoding or validating a repository config.
+type ConfigError struct {
+	Problems []Problem
+}
+
+func (e *ConfigError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		lines = append(lines, p.String())
+	}
+	return fmt.Sprintf("%d problem(s) in the repository config:\n  %s", len(e.Problems), strings.Join(lines, "\n  "))
+}
+
+// decodeStrict decodes content, read from file, into cfg on top of the configs it extends,
+// rejecting keys that do not exist in the config structs rather than ignoring them. A relative
+// extends path is resolved against dir. The returned document is used to locate later
+// validation problems. On a problem, the error is a *ConfigError.
+func decodeStrict(content []byte, file, dir string, cfg *DriftiveRepoConfig) (*document, error) {
+	doc := &document{files: make(map[*yaml.Node]string)}
+	root, problems := doc.load(content, file, dir, map[string]bool{})
+	if root == nil {
+		return nil, &ConfigError{Problems: problems}
+	}
+	doc.root = root
+	problems = append(problems, decodeNode(root, cfg, doc.singleFile())...)
+	if len(problems) > 0 {
+		return doc, &ConfigError{Problems: problems}
+	}
+	return doc, nil
+}
+
+// parseDocument parses content, read from file, and reports the keys the type of out has no field
+// for. An empty document is an empty mapping.
+func parseDocument(content []byte, file string, out any) (*yaml.Node, []Problem) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		problem := yamlProblem(ErrInvalidYAML, err.Error())
+		problem.File = file
+		return nil, []Problem{problem}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	root := doc.Content[0]
+	problems := unknownKeys(root, reflect.TypeOf(out).Elem(), "")
+	for i := range problems {
+		problems[i].File = file
+	}
+	return root, problems
+}
+
+// decodeNode decodes root into out, turning type errors into problems in file.
+func decodeNode(root *yaml.Node, out any, file string) []Problem {
+	err := root.Decode(out)
+	if err == nil {
+		return nil
+	}
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		problem := yamlProblem(ErrInvalidValue, err.Error())
+		problem.File = file
+		return []Problem{problem}
+	}
+	problems := make([]Problem, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		problem := yamlProblem(ErrInvalidValue, msg)
+		problem.File = file
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+var yamlLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlProblem turns a yaml error message, which may start with "line N:", into a Problem.
+func yamlProblem(kind, msg string) Problem {
+	if match := yamlLinePattern.FindStringSubmatch(msg); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return Problem{Err: kind, Message: match[2], Line: line}
+	}
+	return Problem{Err: kind, Message: strings.TrimPrefix(msg, "yaml: ")}
+}
+
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// unknownKeys walks node alongside t and reports the mapping keys t has no field for. Values of
+// the wrong kind are left to the decoder, which reports them.
+func unknownKeys(node *yaml.Node, t reflect.Type, path string) []Problem {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return unknownKeys(node.Content[0], t, path)
+	case yaml.AliasNode:
+		return unknownKeys(node.Alias, t, path)
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	var problems []Problem
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				problems = append(problems, unknownKeyProblem(key, path, fields))
+				continue
+			}
+			problems = append(problems, unknownKeys(value, field.Type, joinPath(path, key.Value))...)
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			problems = append(problems, unknownKeys(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))...)
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			problems = append(problems, unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return problems
+}
+
+func unknownKeyProblem(key *yaml.Node, path string, fields map[string]reflect.StructField) Problem {
+	msg := "unknown key"
+	if suggestion := closestKey(key.Value, fields); suggestion != "" {
+		msg += fmt.Sprintf(" (did you mean %s?)", suggestion)
+	}
+	return Problem{Err: ErrUnknownKey, Path: joinPath(path, key.Value), Message: msg, Line: key.Line, Column: key.Column}
+}
+
+// closestKey returns the known key nearest to key, when it is close enough to be a typo.
+func closestKey(key string, fields map[string]reflect.StructField) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", len(key)/3+1
+	for _, name := range names {
+		if d := editDistance(key, name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// yamlFields maps the yaml keys of struct t to their fields, flattening inline fields like
+// yaml.v3 does.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			for key, inlined := range yamlFields(field.Type) {
+				fields[key] = inlined
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+var pathSegmentPattern = regexp.MustCompile(`[^.\[\]]+|\[\d+\]`)
+
+// locateNode returns the node of the value at path, e.g. projects[2].executable, in root. A
+// mapping key is located at the key itself. When path does not exist in full, the deepest
+// existing part is located.
+func locateNode(root *yaml.Node, path string) *yaml.Node {
+	node, located := root, root
+	for _, segment := range pathSegmentPattern.FindAllString(path, -1) {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		var next *yaml.Node
+		if strings.HasPrefix(segment, "[") {
+			i, _ := strconv.Atoi(strings.Trim(segment, "[]"))
+			if node.Kind == yaml.SequenceNode && i < len(node.Content) {
+				next = node.Content[i]
+				located = next
+			}
+		} else if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					next = node.Content[i+1]
+					located = node.Content[i]
+					break
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return located
+}
@@ -0,0 +1,143 @@
+package repo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeStrictRejectsUnknownKeys(t *testing.T) {
+	content := `github:
+  issues:
+    enabled: true
+    close_resloved: true
+projects:
+  - dir: app
+    executable: terraform
+    workpsaces: [prod]
+`
+	cfg := &DriftiveRepoConfig{}
+	_, err := decodeStrict([]byte(content), "driftive.yml", ".", cfg)
+	configErr, ok := err.(*ConfigError)
+	if !ok {
+		t.Fatalf("decodeStrict() error = %v, want a *ConfigError", err)
+	}
+	want := []Problem{
+		{Err: ErrUnknownKey, File: "driftive.yml", Path: "github.issues.close_resloved", Message: "unknown key (did you mean close_resolved?)", Line: 4, Column: 5},
+		{Err: ErrUnknownKey, File: "driftive.yml", Path: "projects[0].workpsaces", Message: "unknown key (did you mean workspaces?)", Line: 8, Column: 5},
+	}
+	if len(configErr.Problems) != len(want) {
+		t.Fatalf("Problems = %+v, want %+v", configErr.Problems, want)
+	}
+	for i := range want {
+		if configErr.Problems[i] != want[i] {
+			t.Errorf("Problems[%d] = %+v, want %+v", i, configErr.Problems[i], want[i])
+		}
+	}
+	if !cfg.GitHub.Issues.Enabled || cfg.Projects[0].Dir != "app" {
+		t.Errorf("known keys should still be decoded, got %+v", cfg)
+	}
+}
+
+func TestDecodeStrictReportsInvalidValues(t *testing.T) {
+	_, err := decodeStrict([]byte("settings:\n  timeout: soon\n"), "driftive.yml", ".", &DriftiveRepoConfig{})
+	configErr, ok := err.(*ConfigError)
+	if !ok || len(configErr.Problems) != 1 {
+		t.Fatalf("decodeStrict() error = %v, want one problem", err)
+	}
+	if p := configErr.Problems[0]; p.Err != ErrInvalidValue || p.Line != 2 {
+		t.Errorf("problem = %+v, want an invalid value on line 2", p)
+	}
+}
+
+func TestValidateContentListsEveryProblem(t *testing.T) {
+	content := `drift:
+  mode: sometimes
+projects:
+  - dir: app
+    executable: terrafrom
+  - dir: app
+    executable: terraform
+  - dir: payments
+    executable: terraform
+    severity: urgent
+settings:
+  retri:
+    attempts: 3
+`
+	problems := sortProblems(validateContent([]byte(content), "driftive.yml", "."))
+	want := []struct {
+		err  string
+		path string
+		line int
+	}{
+		{ErrInvalidDriftMode, "drift.mode", 2},
+		{ErrInvalidProject, "projects[0].executable", 5},
+		{ErrInvalidProject, "projects[1].dir", 6},
+		{ErrInvalidSeverity, "projects[2].severity", 10},
+		{ErrUnknownKey, "settings.retri", 12},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("problems = %+v, want %d", problems, len(want))
+	}
+	for i, w := range want {
+		if problems[i].Err != w.err || problems[i].Path != w.path || problems[i].Line != w.line {
+			t.Errorf("problems[%d] = %+v, want %s at %s on line %d", i, problems[i], w.err, w.path, w.line)
+		}
+	}
+}
+
+func TestValidateContentRejectsProjectDirsOutsideTheRepository(t *testing.T) {
+	content := `projects:
+  - dir: ../other
+    executable: terraform
+  - dir: /srv/app
+    executable: terraform
+  - dir: ./app/../infra
+    executable: terraform
+`
+	problems := sortProblems(validateContent([]byte(content), "driftive.yml", "."))
+	want := []struct {
+		path string
+		line int
+	}{
+		{"projects[0].dir", 2},
+		{"projects[1].dir", 4},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("problems = %+v, want %d", problems, len(want))
+	}
+	for i, w := range want {
+		if problems[i].Err != ErrInvalidProject || problems[i].Path != w.path || problems[i].Line != w.line {
+			t.Errorf("problems[%d] = %+v, want %s at %s on line %d", i, problems[i], ErrInvalidProject, w.path, w.line)
+		}
+	}
+}
+
+func TestValidateContentAcceptsValidConfig(t *testing.T) {
+	content := `auto_discover:
+  enabled: true
+  inclusions: ["**/*.tf"]
+projects:
+  - dir: app
+    executable: terraform
+    workspaces: all
+    timeout: 15m
+`
+	if problems := validateContent([]byte(content), "driftive.yml", "."); len(problems) != 0 {
+		t.Errorf("problems = %+v, want none", problems)
+	}
+}
+
+func TestConfigErrorListsProblems(t *testing.T) {
+	err := &ConfigError{Problems: []Problem{
+		{Path: "drift.mode", Message: "bad", Line: 3, Column: 9},
+		{Message: "worse"},
+	}}
+	want := "2 problem(s) in the repository config:\n  line 3, column 9: drift.mode: bad\n  worse"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !strings.Contains(err.Error(), "drift.mode") {
+		t.Errorf("Error() should name the path")
+	}
+}
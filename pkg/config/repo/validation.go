@@ -4,34 +4,228 @@ import (
 	"errors"
 	"fmt"
 	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 )
 
 var ErrMissingRepoConfig = fmt.Errorf("driftive.yml not found")
 var ErrMsgMissingRepoConfig = "missing repository config"
 var ErrInvalidLabelName = "invalid label name"
 var ErrConflictingLabels = "conflicting drift and error labels"
+var ErrInvalidDriftMode = "invalid drift mode"
+var ErrInvalidProject = "invalid project"
+var ErrInvalidTimeout = "invalid timeout"
+var ErrInvalidRetry = "invalid retry policy"
+var ErrInvalidDiscoverySource = "invalid discovery source"
+var ErrInvalidYAML = "invalid yaml"
+var ErrUnknownKey = "unknown key"
+var ErrInvalidValue = "invalid value"
+var ErrInvalidExtends = "invalid extends"
+var ErrInvalidSeverity = "invalid severity"
+var ErrInvalidOwner = "invalid owner"
 
+// ValidateRepoConfig exits when the repository config has problems, after logging every one of
+// them.
 func ValidateRepoConfig(repoConfig *DriftiveRepoConfig) {
 	//nolint:staticcheck
 	if nil == repoConfig {
 		log.Fatal().Err(errors.New(ErrMsgMissingRepoConfig)).Msg("Repository config is required. Please create a .driftive.y(a)ml file in the root of the repository.")
 	}
-	//nolint:staticcheck
-	if nil != repoConfig.GitHub.Issues.Labels {
-		for _, label := range repoConfig.GitHub.Issues.Labels {
-			if label == "" {
-				log.Fatal().Err(errors.New(ErrInvalidLabelName)).Msgf("Invalid label name: %s", label)
-			}
-			if repoConfig.GitHub.Issues.Errors.Enabled && repoConfig.GitHub.Issues.Errors.Labels != nil {
-				for _, errorLabel := range repoConfig.GitHub.Issues.Errors.Labels {
-					if errorLabel == "" {
-						log.Fatal().Err(errors.New(ErrInvalidLabelName)).Msgf("Invalid label name: %s", errorLabel)
-					}
-					if errorLabel == label {
-						log.Fatal().Err(errors.New(ErrConflictingLabels)).Msgf("Label '%s' is used for both drift and error issues", errorLabel)
-					}
-				}
+	problems := Validate(repoConfig)
+	if len(problems) == 0 {
+		return
+	}
+	for _, problem := range problems {
+		log.Error().Err(errors.New(problem.Err)).Msg(problem.String())
+	}
+	log.Fatal().Err(errors.New(problems[0].Err)).Msgf("Invalid repository config: %d problem(s)", len(problems))
+}
+
+// Validate checks the values of a decoded repository config and returns every problem found.
+func Validate(repoConfig *DriftiveRepoConfig) []Problem {
+	v := &validator{}
+	v.validateDriftMode("drift.mode", "", repoConfig.Drift.Mode)
+	if repoConfig.Settings.Timeout < 0 {
+		v.add(ErrInvalidTimeout, "settings.timeout", "Invalid timeout: %s", repoConfig.Settings.Timeout)
+	}
+	v.validateRetry(repoConfig.Settings.Retry)
+	switch repoConfig.AutoDiscover.Source {
+	case "", DiscoverySourceFiles, DiscoverySourceAtlantis:
+	default:
+		v.add(ErrInvalidDiscoverySource, "auto_discover.source", "Invalid auto_discover.source: %s. Supported sources: files, atlantis", repoConfig.AutoDiscover.Source)
+	}
+	for i, rule := range repoConfig.AutoDiscover.ProjectRules {
+		path := fmt.Sprintf("auto_discover.project_rules[%d]", i)
+		subject := fmt.Sprintf(" for project rule '%s'", rule.Pattern)
+		if rule.Executable != "" {
+			v.validateExecutable(path+".executable", subject, rule.Executable)
+		}
+		v.validateDriftMode(path+".drift_mode", subject, rule.DriftMode)
+		if rule.Timeout < 0 {
+			v.add(ErrInvalidTimeout, path+".timeout", "Invalid timeout for project rule '%s': %s", rule.Pattern, rule.Timeout)
+		}
+		if rule.Executable == "pulumi" {
+			v.validatePulumiProject(path, rule.Pattern, rule.DriftMode, rule.ProjectArgs)
+		}
+		v.validateMetadata(path, rule.ProjectMetadata)
+	}
+	v.validateProjects(repoConfig.Projects)
+	v.validateLabels(repoConfig.GitHub.Issues)
+	for _, dir := range repoConfig.Directories {
+		v.validateDirectory(dir)
+	}
+	return v.problems
+}
+
+// validator collects the problems found by Validate.
+type validator struct {
+	problems []Problem
+	// file and root, when set, locate the problems added: they are in a driftive.yml fragment.
+	file string
+	root *yaml.Node
+}
+
+func (v *validator) add(kind, path, format string, args ...any) {
+	problem := Problem{Err: kind, File: v.file, Path: path, Message: fmt.Sprintf(format, args...)}
+	if v.root != nil {
+		node := locateNode(v.root, path)
+		problem.Line, problem.Column = node.Line, node.Column
+	}
+	v.problems = append(v.problems, problem)
+}
+
+// validateDirectory checks the values of a driftive.yml fragment.
+func (v *validator) validateDirectory(dir DirectoryConfig) {
+	v.file, v.root = dir.file, dir.root
+	defer func() { v.file, v.root = "", nil }()
+
+	subject := fmt.Sprintf(" for directory '%s'", dir.Dir)
+	if dir.Executable != "" {
+		v.validateExecutable("executable", subject, dir.Executable)
+	}
+	v.validateDriftMode("drift_mode", subject, dir.DriftMode)
+	if dir.Executable == "pulumi" {
+		v.validatePulumiProject("", dir.Dir, dir.DriftMode, dir.ProjectArgs)
+	}
+	if dir.Timeout < 0 {
+		v.add(ErrInvalidTimeout, "timeout", "Invalid timeout for directory '%s': %s", dir.Dir, dir.Timeout)
+	}
+	for i, label := range dir.Labels {
+		if label == "" {
+			v.add(ErrInvalidLabelName, fmt.Sprintf("labels[%d]", i), "Invalid label name: %s", label)
+		}
+	}
+	v.validateMetadata("", dir.ProjectMetadata)
+}
+
+// validateExecutable checks an executable. subject, e.g. " for project 'infra'", tells whose
+// it is in the message.
+func (v *validator) validateExecutable(path, subject, executable string) {
+	if !slices.Contains(Executables, executable) {
+		v.add(ErrInvalidProject, path, "Invalid executable%s: %s. Supported executables: %s", subject, executable, strings.Join(Executables, ", "))
+	}
+}
+
+// validateDriftMode checks a drift mode, empty meaning the default. subject is as for
+// validateExecutable.
+func (v *validator) validateDriftMode(path, subject, mode string) {
+	if mode != "" && !slices.Contains(DriftModes, mode) {
+		v.add(ErrInvalidDriftMode, path, "Invalid drift mode%s: %s. Supported modes: %s", subject, mode, strings.Join(DriftModes, ", "))
+	}
+}
+
+func (v *validator) validateMetadata(path string, metadata ProjectMetadata) {
+	if metadata.Severity != "" && !slices.Contains(Severities, metadata.Severity) {
+		v.add(ErrInvalidSeverity, joinPath(path, "severity"), "Invalid severity: %s. Supported severities: %s", metadata.Severity, strings.Join(Severities, ", "))
+	}
+	for i, owner := range metadata.Owners {
+		if strings.TrimSpace(owner) == "" {
+			v.add(ErrInvalidOwner, fmt.Sprintf("%s[%d]", joinPath(path, "owners"), i), "Owners cannot be empty")
+		}
+	}
+}
+
+func (v *validator) validateRetry(retry DriftiveRepoConfigRetry) {
+	if retry.Attempts < 0 {
+		v.add(ErrInvalidRetry, "settings.retry.attempts", "Invalid retry attempts: %d", retry.Attempts)
+	}
+	if retry.Backoff < 0 {
+		v.add(ErrInvalidRetry, "settings.retry.backoff", "Invalid retry backoff: %s", retry.Backoff)
+	}
+	for i, pattern := range retry.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			v.add(ErrInvalidRetry, fmt.Sprintf("settings.retry.patterns[%d]", i), "Invalid retry pattern '%s': %v", pattern, err)
+		}
+	}
+}
+
+func (v *validator) validateProjects(projects []ProjectConfig) {
+	seen := make(map[string]bool, len(projects))
+	for i, project := range projects {
+		path := fmt.Sprintf("projects[%d]", i)
+		if project.Dir == "" {
+			v.add(ErrInvalidProject, path, "Every entry under projects needs a dir")
+		} else if !filepath.IsLocal(project.Dir) {
+			// Init and plan run in the dir, so it must not escape the checkout.
+			v.add(ErrInvalidProject, path+".dir", "Invalid dir for project '%s': must be relative to the repository root and stay inside it", project.Dir)
+		}
+		subject := fmt.Sprintf(" for project '%s'", project.Dir)
+		v.validateExecutable(path+".executable", subject, project.Executable)
+		v.validateDriftMode(path+".drift_mode", subject, project.DriftMode)
+		if project.Executable == "pulumi" {
+			v.validatePulumiProject(path, project.Dir, project.DriftMode, project.ProjectArgs)
+		}
+		if project.Timeout < 0 {
+			v.add(ErrInvalidTimeout, path+".timeout", "Invalid timeout for project '%s': %s", project.Dir, project.Timeout)
+		}
+		v.validateMetadata(path, project.ProjectMetadata)
+		if project.Workspace != "" && !project.Workspaces.IsZero() {
+			v.add(ErrInvalidProject, path+".workspaces", "Project '%s' sets both workspace and workspaces", project.Dir)
+		}
+		workspaces := project.Workspaces.Names
+		if len(workspaces) == 0 {
+			workspaces = []string{project.Workspace}
+		}
+		for _, workspace := range workspaces {
+			key := filepath.Clean(project.Dir) + "@" + workspace
+			if seen[key] {
+				v.add(ErrInvalidProject, path+".dir", "Project '%s' is declared more than once for workspace '%s'", project.Dir, workspace)
 			}
+			seen[key] = true
+		}
+	}
+}
+
+// validatePulumiProject rejects the settings Pulumi has no equivalent for. name is the project
+// dir or the rule pattern.
+func (v *validator) validatePulumiProject(path, name, driftMode string, args ProjectArgs) {
+	if driftMode != "" && driftMode != "plan" {
+		v.add(ErrInvalidDriftMode, joinPath(path, "drift_mode"), "Invalid drift mode for pulumi project '%s': %s. Pulumi projects only support plan", name, driftMode)
+	}
+	if len(args.VarFiles) > 0 || len(args.BackendConfig) > 0 {
+		v.add(ErrInvalidProject, path, "Pulumi project '%s' sets var_files or backend_config, which only apply to terraform, tofu and terragrunt", name)
+	}
+}
+
+func (v *validator) validateLabels(issues DriftiveRepoConfigGitHubIssues) {
+	for i, label := range issues.Labels {
+		if label == "" {
+			v.add(ErrInvalidLabelName, fmt.Sprintf("github.issues.labels[%d]", i), "Invalid label name: %s", label)
+		}
+	}
+	if !issues.Errors.Enabled {
+		return
+	}
+	for i, errorLabel := range issues.Errors.Labels {
+		path := fmt.Sprintf("github.issues.errors.labels[%d]", i)
+		if errorLabel == "" {
+			v.add(ErrInvalidLabelName, path, "Invalid label name: %s", errorLabel)
+		}
+		if errorLabel != "" && slices.Contains(issues.Labels, errorLabel) {
+			v.add(ErrConflictingLabels, path, "Label '%s' is used for both drift and error issues", errorLabel)
 		}
 	}
 }
@@ -2,32 +2,52 @@ package drift
 
 import (
 	"context"
+	"driftive/pkg/exec"
 	"driftive/pkg/models"
+	"driftive/pkg/models/plan"
+	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
-func (d *DriftDetector) detectDriftConcurrently(ctx context.Context, project models.TypedProject, projectDir string) {
+// planFileName and refreshPlanFileName are the binary plans saved by each project's plans,
+// inside a per-project temp dir.
+const (
+	planFileName        = "driftive.tfplan"
+	refreshPlanFileName = "driftive-refresh.tfplan"
+)
+
+// projectDone is a finished project's result and its index in DriftDetector.Projects.
+type projectDone struct {
+	index  int
+	result DriftProjectResult
+}
+
+func (d *DriftDetector) detectDriftConcurrently(ctx context.Context, index int, project models.TypedProject, projectDir string, done chan<- projectDone) {
 	defer func() {
 		<-d.semaphore
 	}()
-	defer d.workerWg.Done()
 
 	// Reported from inside the worker, after the semaphore is acquired, so "running" reflects
 	// actual concurrency rather than the whole backlog.
+	reported := project
+	reported.Dir = projectDir
 	if d.OnProjectStart != nil {
-		d.OnProjectStart(projectDir)
+		d.OnProjectStart(reported.Key())
 	}
 
-	result, err := d.detectDrift(ctx, project)
+	result, err := d.detectDriftWithRetry(ctx, project)
 	if err != nil {
-		log.Info().Msgf("Error checking drift in %s: %v", project.Dir, err)
+		log.Info().Msgf("Error checking drift in %s: %v", project.Key(), err)
 	}
 	if result.Drifted {
-		log.Info().Msgf("Drift detected in project %s", projectDir)
+		log.Info().Msgf("Drift detected in project %s", reported.Key())
 	}
 	// Report the repo-relative dir rather than the discovered path, which carries whatever
 	// prefix --repo-path had (or the temp clone dir under --repo-url). Must happen after
@@ -39,7 +59,17 @@ func (d *DriftDetector) detectDriftConcurrently(ctx context.Context, project mod
 		d.OnProjectDone(result)
 	}
 
-	d.results <- result
+	done <- projectDone{index: index, result: result}
+}
+
+// blockedResult reports a project that was not analyzed because upstream, a project it depends
+// on, failed.
+func (d *DriftDetector) blockedResult(project models.TypedProject, upstream string) DriftProjectResult {
+	project.Dir = relativeProjectDir(d.RepoDir, project.Dir)
+	log.Info().Msgf("Skipping %s: blocked by an upstream error in %s", project.Key(), upstream)
+	return DriftProjectResult{Project: project, Drifted: false, Succeeded: false,
+		FailureReason: ReasonBlocked, BlockedBy: upstream,
+		InitOutput: fmt.Sprintf("Not analyzed: blocked by an upstream error in %s.", upstream)}
 }
 
 // relativeProjectDir returns proj relative to the repository root. The repo root itself is
@@ -63,35 +93,55 @@ func (d *DriftDetector) DetectDrift(ctx context.Context) DriftDetectionResult {
 	}
 
 	log.Info().Msgf("Starting drift analysis in %s. Concurrency: %d", absolutePath, d.Config.Concurrency)
-	d.results = make(chan DriftProjectResult, len(d.Projects))
+	d.ExpandWorkspaces(ctx)
 	var totalChecked = 0
 	startTime := time.Now()
 
-	for idx, proj := range d.Projects {
-		projectDir := relativeProjectDir(d.RepoDir, proj.Dir)
-
+	// Projects run once the projects they depend on have finished. done is buffered so workers
+	// never wait on the dispatcher.
+	sched := newSchedule(d.Projects)
+	done := make(chan projectDone, len(d.Projects))
+	projectResults := make([]DriftProjectResult, 0, len(d.Projects))
+	cancelled := false
+	for !sched.done() {
 		// Honor cancellation between projects so an interrupted run doesn't keep
 		// spinning up new terraform/tofu processes after Ctrl-C.
-		if ctx.Err() != nil {
-			log.Info().Msgf("Drift analysis cancelled after %d/%d projects: %v", idx, len(d.Projects), ctx.Err())
+		if !cancelled && ctx.Err() != nil {
+			log.Info().Msgf("Drift analysis cancelled after %d/%d projects: %v", totalChecked, len(d.Projects), ctx.Err())
+			cancelled = true
+		}
+		if !cancelled {
+			if idx, ok := sched.next(); ok {
+				proj := d.Projects[idx]
+				projectDir := relativeProjectDir(d.RepoDir, proj.Dir)
+				totalChecked++
+				log.Info().Msgf("Checking drift in project %d/%d: %s (%s)", totalChecked, len(d.Projects), models.Project{Dir: projectDir, Workspace: proj.Workspace}.Key(), models.ProjectTypeToStr(proj.Type))
+				d.semaphore <- struct{}{}
+				go d.detectDriftConcurrently(ctx, idx, proj, projectDir, done)
+				continue
+			}
+		}
+		if sched.inFlight == 0 {
 			break
 		}
 
-		totalChecked++
-		log.Info().Msgf("Checking drift in project %d/%d: %s (%s)", idx+1, len(d.Projects), projectDir, models.ProjectTypeToStr(proj.Type))
-		d.workerWg.Add(1)
-		d.semaphore <- struct{}{}
-		go d.detectDriftConcurrently(ctx, proj, projectDir)
+		finished := <-done
+		projectResults = append(projectResults, finished.result)
+		// A project failing because the run was cancelled does not block its dependents: they
+		// are left unchecked like every other project the run did not reach.
+		failed := !finished.result.Succeeded && ctx.Err() == nil
+		for _, blocked := range sched.finish(finished.index, failed) {
+			result := d.blockedResult(d.Projects[blocked.index], finished.result.Project.Key())
+			if d.OnProjectDone != nil {
+				d.OnProjectDone(result)
+			}
+			projectResults = append(projectResults, result)
+		}
 	}
 
-	d.workerWg.Wait()
-	close(d.results)
-
-	projectResults := make([]DriftProjectResult, 0)
 	driftedCount := 0
 	erroredCount := 0
-	for result := range d.results {
-		projectResults = append(projectResults, result)
+	for _, result := range projectResults {
 		if result.Drifted {
 			driftedCount++
 		}
@@ -116,9 +166,73 @@ func (d *DriftDetector) DetectDrift(ctx context.Context) DriftDetectionResult {
 	return result
 }
 
+// detectDrift analyzes one project, bounded by its timeout. A project that runs out of time is
+// killed and reported as failed with ReasonTimeout. The timeout starts once the project holds the
+// init slot, so time spent waiting for other projects' inits does not count against it.
 func (d *DriftDetector) detectDrift(ctx context.Context, project models.TypedProject) (DriftProjectResult, error) {
-	executor := d.newExecutor(project.Dir, project.Type)
-	output, err := executor.Init(ctx, "-upgrade", "-lock=false", "-no-color")
+	releaseInit, err := d.acquireInitSlot(ctx)
+	if err != nil {
+		return DriftProjectResult{Project: project, Drifted: false, Succeeded: false,
+			FailedPhase: PhaseInit, InitOutput: err.Error(), PlanOutput: ""}, err
+	}
+	defer releaseInit()
+
+	timeout := d.projectTimeout(project)
+	if timeout <= 0 {
+		return d.analyze(ctx, project, releaseInit)
+	}
+
+	projectCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := d.analyze(projectCtx, project, releaseInit)
+	// Only the project's own deadline counts: a cancelled run is not a timeout.
+	if err == nil || ctx.Err() != nil || !errors.Is(projectCtx.Err(), context.DeadlineExceeded) {
+		return result, err
+	}
+
+	log.Warn().Msgf("Project %s timed out after %s during %s", project.Key(), timeout, result.FailedPhase)
+	result.FailureReason = ReasonTimeout
+	note := fmt.Sprintf("Timed out after %s during %s.", timeout, result.FailedPhase)
+	if result.FailedPhase == PhaseInit {
+		result.InitOutput = strings.TrimSpace(result.InitOutput + "\n\n" + note)
+	} else {
+		result.PlanOutput = strings.TrimSpace(result.PlanOutput + "\n\n" + note)
+	}
+	return result, fmt.Errorf("timed out after %s: %w", timeout, err)
+}
+
+// projectTimeout is the project's own timeout, or settings.timeout when it sets none. Zero or
+// less means no timeout.
+func (d *DriftDetector) projectTimeout(project models.TypedProject) time.Duration {
+	if project.Settings.Timeout != 0 {
+		return project.Settings.Timeout
+	}
+	return d.RepoConfig.Settings.Timeout
+}
+
+// analyze picks the project's executor and runs it, recording the tool version it ran with.
+// releaseInit hands back the init slot once init is done.
+func (d *DriftDetector) analyze(ctx context.Context, project models.TypedProject, releaseInit func()) (DriftProjectResult, error) {
+	if failure, failed := d.workspaceErrors[project.Dir]; failed && project.Settings.AllWorkspaces {
+		return DriftProjectResult{Project: project, Drifted: false, Succeeded: false, FailedPhase: PhaseInit,
+			FailureReason: failure.reason, InitOutput: failure.output, PlanOutput: ""}, errors.New("listing workspaces failed")
+	}
+
+	executor, version, err := d.projectExecutor(project)
+	if err != nil {
+		log.Error().Msgf("Failed to pick a binary for %s: %v", project.Dir, err)
+		return DriftProjectResult{Project: project, Drifted: false, Succeeded: false,
+			FailedPhase: PhaseInit, InitOutput: err.Error(), PlanOutput: ""}, err
+	}
+	result, err := d.runProject(ctx, project, executor, releaseInit)
+	result.ToolVersion = version
+	return result, err
+}
+
+// runProject runs init and the plans of the drift mode, and decides whether the project drifted.
+func (d *DriftDetector) runProject(ctx context.Context, project models.TypedProject, executor exec.Executor,
+	releaseInit func()) (DriftProjectResult, error) {
+	output, err := d.runInit(ctx, executor, project, releaseInit)
 
 	if err != nil {
 		log.Info().Msgf("Error running init command in %s: %v", project.Dir, err)
@@ -126,21 +240,186 @@ func (d *DriftDetector) detectDrift(ctx context.Context, project models.TypedPro
 		return DriftProjectResult{Project: project, Drifted: false, Succeeded: false,
 			FailedPhase: PhaseInit, InitOutput: orErrorText(output, err), PlanOutput: ""}, err
 	}
-	output, err = executor.Plan(ctx, "-lock=false", "-no-color")
+
+	planDir, err := os.MkdirTemp("", "driftive-plan")
 	if err != nil {
-		log.Info().Msgf("Error running plan command in %s: %v", project.Dir, err)
-		log.Info().Msg(output)
 		return DriftProjectResult{Project: project, Drifted: false, Succeeded: false,
-			FailedPhase: PhasePlan, InitOutput: "", PlanOutput: orErrorText(executor.ParseErrorOutput(output), err)}, err
+			FailedPhase: PhasePlan, InitOutput: "", PlanOutput: err.Error()}, err
+	}
+	defer os.RemoveAll(planDir)
+
+	mode := d.driftMode(project)
+	planArgs := planArgs(project)
+	var regular, refresh planRun
+	if mode != models.DriftModeRefreshOnly {
+		regular, err = runPlan(ctx, executor, filepath.Join(planDir, planFileName), planArgs...)
+		if err != nil {
+			log.Info().Msgf("Error running plan command in %s: %v", project.Dir, err)
+			log.Info().Msg(regular.output)
+			return DriftProjectResult{Project: project, Drifted: false, Succeeded: false,
+				FailedPhase: PhasePlan, InitOutput: "", PlanOutput: regular.output}, err
+		}
 	}
-	driftDetected := d.isDriftDetected(output)
-	if driftDetected {
-		output = executor.ParsePlan(output)
+	if mode != models.DriftModePlan {
+		refreshArgs := append([]string{"-refresh-only"}, planArgs...)
+		refresh, err = runPlan(ctx, executor, filepath.Join(planDir, refreshPlanFileName), refreshArgs...)
+		if err != nil {
+			log.Info().Msgf("Error running refresh-only plan command in %s: %v", project.Dir, err)
+			log.Info().Msg(refresh.output)
+			return DriftProjectResult{Project: project, Drifted: false, Succeeded: false,
+				FailedPhase: PhasePlan, InitOutput: "", PlanOutput: refresh.output}, err
+		}
+	}
+
+	resources, ignored := d.ignore.forProject(project.Settings).apply(attributeResources(mode, regular.plan, refresh.plan))
+	if ignored > 0 {
+		log.Info().Msgf("Ignored %d resource change(s) in %s per drift.ignore rules", ignored, project.Dir)
+	}
+	driftDetected := len(resources) > 0 || regular.plan.HasOutputChanges()
+
+	result := DriftProjectResult{Project: project, Drifted: driftDetected, Succeeded: true, InitOutput: "",
+		PlanOutput: planOutput(executor, driftDetected, regular, refresh), Plan: regular.plan,
+		DriftedResources: resources, IgnoredResources: ignored, DriftSource: driftSource(resources)}
+	if mode == models.DriftModeRefreshOnly {
+		result.Plan = refresh.plan
 	}
-	result := DriftProjectResult{Project: project, Drifted: driftDetected, Succeeded: true, InitOutput: "", PlanOutput: output}
 	return result, nil
 }
 
+// initArgs are driftive's own init arguments followed by the project's backend config and
+// extra init arguments. -upgrade is left out when settings.honor_lock_file is set. Pulumi
+// projects only get their extra init arguments.
+func (d *DriftDetector) initArgs(project models.TypedProject) []string {
+	if project.Type == models.Pulumi {
+		return project.Settings.InitArgs
+	}
+	args := make([]string, 0, 3+len(project.Settings.BackendConfig)+len(project.Settings.InitArgs))
+	if !d.RepoConfig.Settings.HonorLockFile {
+		args = append(args, "-upgrade")
+	}
+	args = append(args, "-lock=false", "-no-color")
+	for _, backendConfig := range project.Settings.BackendConfig {
+		args = append(args, "-backend-config="+backendConfig)
+	}
+	return append(args, project.Settings.InitArgs...)
+}
+
+// planArgs are driftive's own plan arguments followed by the project's var files and extra
+// plan arguments. Pulumi projects only get their extra plan arguments.
+func planArgs(project models.TypedProject) []string {
+	if project.Type == models.Pulumi {
+		return project.Settings.PlanArgs
+	}
+	args := []string{"-lock=false", "-no-color"}
+	for _, varFile := range project.Settings.VarFiles {
+		args = append(args, "-var-file="+varFile)
+	}
+	return append(args, project.Settings.PlanArgs...)
+}
+
+// executorOptions builds the executor options for a project. The shared plugin cache comes
+// first, then settings.env, and the project's env overrides both. The workspace is selected
+// with TF_WORKSPACE, which terraform, tofu and terragrunt all honor, and wins over an env entry.
+// A Pulumi project's workspace is its stack, passed to the executor instead.
+func (d *DriftDetector) executorOptions(project models.TypedProject) exec.Options {
+	global := d.RepoConfig.Settings.ProjectEnv
+	env := make([]string, 0, len(global.Env)+len(project.Settings.Env)+2)
+	if d.pluginCacheDir != "" {
+		env = append(env, "TF_PLUGIN_CACHE_DIR="+d.pluginCacheDir)
+	}
+	env = append(env, sortedEnv(global.Env)...)
+	env = append(env, sortedEnv(project.Settings.Env)...)
+	stack := ""
+	if project.Type == models.Pulumi {
+		stack = project.Workspace
+	} else if project.Workspace != "" {
+		env = append(env, "TF_WORKSPACE="+project.Workspace)
+	}
+
+	passthrough := project.Settings.EnvPassthrough
+	if passthrough == nil {
+		passthrough = global.EnvPassthrough
+	}
+	return exec.Options{Env: env, Passthrough: passthrough, Stack: stack}
+}
+
+// sortedEnv renders vars as KEY=VALUE entries, sorted so commands run with a stable environment.
+func sortedEnv(vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, key+"="+vars[key])
+	}
+	return env
+}
+
+// driftMode resolves the project's drift mode, falling back to drift.mode and then to a regular plan.
+// Pulumi projects always run a regular plan: their preview refreshes first, so it reports
+// outside changes and unapplied code alike.
+func (d *DriftDetector) driftMode(project models.TypedProject) models.DriftMode {
+	if project.Type == models.Pulumi {
+		return models.DriftModePlan
+	}
+	if project.Settings.DriftMode != "" {
+		return project.Settings.DriftMode
+	}
+	if d.RepoConfig.Drift.Mode != "" {
+		return models.DriftMode(d.RepoConfig.Drift.Mode)
+	}
+	return models.DriftModePlan
+}
+
+// planRun is one plan's human-readable output and the structured plan it saved.
+type planRun struct {
+	output string
+	plan   *plan.Plan
+}
+
+// runPlan plans into planFile and, when the plan has a diff, reads the saved plan back. On
+// error, output holds the text explaining the failure.
+func runPlan(ctx context.Context, executor exec.Executor, planFile string, args ...string) (planRun, error) {
+	result, err := executor.Plan(ctx, planFile, args...)
+	if err != nil {
+		return planRun{output: orErrorText(executor.ParseErrorOutput(result.Output), err)}, err
+	}
+	if result.Outcome == exec.PlanNoChanges {
+		return planRun{output: result.Output, plan: &plan.Plan{}}, nil
+	}
+	planModel, err := executor.Show(ctx, planFile)
+	if err != nil {
+		return planRun{output: fmt.Sprintf("Error reading the saved plan: %v", err)}, err
+	}
+	return planRun{output: result.Output, plan: planModel}, nil
+}
+
+// planOutput is the text reported for the project. Drifted projects get the trimmed plans that
+// found the drift; the refresh-only plan comes first since it explains what changed outside.
+func planOutput(executor exec.Executor, drifted bool, regular, refresh planRun) string {
+	if !drifted {
+		if regular.plan != nil {
+			return regular.output
+		}
+		return refresh.output
+	}
+
+	parts := make([]string, 0, 2)
+	if refresh.plan != nil && len(refresh.plan.ResourceDrift) > 0 {
+		parts = append(parts, executor.ParsePlan(refresh.output))
+	}
+	if regular.plan != nil && regular.plan.HasChanges() {
+		parts = append(parts, executor.ParsePlan(regular.output))
+	}
+	if len(parts) == 0 {
+		return executor.ParsePlan(regular.output)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
 // orErrorText falls back to the error text when the command produced no output, which happens
 // when the executable itself could not be run.
 func orErrorText(output string, err error) string {
@@ -149,13 +428,3 @@ func orErrorText(output string, err error) string {
 	}
 	return output
 }
-
-func (d *DriftDetector) isDriftDetected(commandOutput string) bool {
-	noChangesPatterns := []string{"Your infrastructure matches the configuration", "No changes. Infrastructure is up-to-date."}
-	for _, pattern := range noChangesPatterns {
-		if strings.Contains(commandOutput, pattern) {
-			return false
-		}
-	}
-	return true
-}
@@ -6,31 +6,55 @@ import (
 	"driftive/pkg/config/repo"
 	"driftive/pkg/exec"
 	"driftive/pkg/models"
+	"driftive/pkg/models/plan"
 	"errors"
+	"path/filepath"
 	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 // fakeExecutor stands in for terraform/tofu/terragrunt so DetectDrift can be exercised
-// without those binaries installed. planOutput drives whether drift is reported: the real
-// detector treats "Your infrastructure matches the configuration" as no drift.
+// without those binaries installed. plan drives whether drift is reported, as the saved plan
+// rendered by `show -json` does for the real executors.
 type fakeExecutor struct {
 	dir        string
 	planOutput string
+	plan       *plan.Plan
 
 	// initOutput/initErr and planErr drive the failure paths. Zero values mean "succeeds".
 	initOutput string
 	initErr    error
 	planErr    error
+	// planOutcome overrides the outcome of a successful plan, which defaults to exec.PlanChanges
+	// so the saved plan is read.
+	planOutcome exec.PlanOutcome
+	// workspaces and workspacesErr drive `workspace list`.
+	workspaces    []string
+	workspacesErr error
 
 	mu       *sync.Mutex
 	initDirs *[]string
 }
 
-const noDriftOutput = "Your infrastructure matches the configuration"
+// noDriftPlan is a plan whose only resource is unchanged.
+var noDriftPlan = &plan.Plan{
+	FormatVersion: "1.2",
+	ResourceChanges: []plan.ResourceChange{
+		{Address: "null_resource.foo", Change: plan.Change{Actions: []string{plan.ActionNoOp}}},
+	},
+}
+
+// driftPlan is a plan that would create one resource.
+var driftPlan = &plan.Plan{
+	FormatVersion: "1.2",
+	ResourceChanges: []plan.ResourceChange{
+		{Address: "null_resource.foo", Change: plan.Change{Actions: []string{plan.ActionCreate}}},
+	},
+}
 
 func (f fakeExecutor) Dir() string { return f.dir }
 
@@ -44,8 +68,25 @@ func (f fakeExecutor) Init(_ context.Context, _ ...string) (string, error) {
 	return "init ok", nil
 }
 
-func (f fakeExecutor) Plan(_ context.Context, _ ...string) (string, error) {
-	return f.planOutput, f.planErr
+func (f fakeExecutor) Plan(_ context.Context, _ string, _ ...string) (exec.PlanResult, error) {
+	if f.planErr != nil {
+		return exec.PlanResult{Output: f.planOutput, Outcome: exec.PlanFailed}, f.planErr
+	}
+	if f.planOutcome == exec.PlanFailed {
+		return exec.PlanResult{Output: f.planOutput, Outcome: exec.PlanChanges}, nil
+	}
+	return exec.PlanResult{Output: f.planOutput, Outcome: f.planOutcome}, nil
+}
+
+func (f fakeExecutor) Show(_ context.Context, _ string) (*plan.Plan, error) {
+	if f.plan == nil {
+		return noDriftPlan, nil
+	}
+	return f.plan, nil
+}
+
+func (f fakeExecutor) Workspaces(_ context.Context) ([]string, error) {
+	return f.workspaces, f.workspacesErr
 }
 
 func (f fakeExecutor) ParsePlan(output string) string        { return output }
@@ -54,7 +95,7 @@ func (f fakeExecutor) ParseErrorOutput(output string) string { return output }
 // newTestDetector wires a DriftDetector with a fake executor factory. initDirs records the
 // working directory each executor was built with, so tests can assert that execution still
 // uses the full discovered path.
-func newTestDetector(repoDir string, projects []models.TypedProject, planOutput string) (*DriftDetector, *[]string) {
+func newTestDetector(repoDir string, projects []models.TypedProject, p *plan.Plan) (*DriftDetector, *[]string) {
 	var mu sync.Mutex
 	initDirs := make([]string, 0)
 
@@ -66,8 +107,8 @@ func newTestDetector(repoDir string, projects []models.TypedProject, planOutput
 		nil,
 		nil,
 	)
-	d.newExecutor = func(dir string, _ models.ProjectType) exec.Executor {
-		return fakeExecutor{dir: dir, planOutput: planOutput, mu: &mu, initDirs: &initDirs}
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return fakeExecutor{dir: dir, plan: p, mu: &mu, initDirs: &initDirs}
 	}
 	return &d, &initDirs
 }
@@ -85,7 +126,7 @@ func newFailingTestDetector(projects []models.TypedProject, template fakeExecuto
 		nil,
 		nil,
 	)
-	d.newExecutor = func(dir string, _ models.ProjectType) exec.Executor {
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
 		e := template
 		e.dir = dir
 		e.mu = &mu
@@ -121,7 +162,7 @@ func TestDetectDriftReportsRelativeDirs(t *testing.T) {
 		{Dir: "infra/foo.bar", Type: models.Terraform},
 		{Dir: "infra/baz", Type: models.Terraform},
 	}
-	d, _ := newTestDetector(".", projects, noDriftOutput)
+	d, _ := newTestDetector(".", projects, noDriftPlan)
 
 	result := d.DetectDrift(context.Background())
 
@@ -141,7 +182,7 @@ func TestDetectDriftNormalizesAbsoluteDirs(t *testing.T) {
 	projects := []models.TypedProject{
 		{Dir: repoDir + "/infra/foo", Type: models.Terraform},
 	}
-	d, initDirs := newTestDetector(repoDir, projects, noDriftOutput)
+	d, initDirs := newTestDetector(repoDir, projects, noDriftPlan)
 
 	result := d.DetectDrift(context.Background())
 
@@ -163,7 +204,7 @@ func TestDetectDriftScansRepositoryRoot(t *testing.T) {
 	projects := []models.TypedProject{
 		{Dir: ".", Type: models.Terraform},
 	}
-	d, _ := newTestDetector(".", projects, noDriftOutput)
+	d, _ := newTestDetector(".", projects, noDriftPlan)
 
 	result := d.DetectDrift(context.Background())
 
@@ -187,7 +228,7 @@ func TestDetectDriftTotalCheckedReflectsCancellation(t *testing.T) {
 		{Dir: "infra/b", Type: models.Terraform},
 		{Dir: "infra/c", Type: models.Terraform},
 	}
-	d, _ := newTestDetector(".", projects, noDriftOutput)
+	d, _ := newTestDetector(".", projects, noDriftPlan)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -212,7 +253,7 @@ func TestDetectDriftReportsProgress(t *testing.T) {
 		{Dir: repoDir + "/infra/a", Type: models.Terraform},
 		{Dir: repoDir + "/infra/b", Type: models.Terraform},
 	}
-	d, _ := newTestDetector(repoDir, projects, noDriftOutput)
+	d, _ := newTestDetector(repoDir, projects, noDriftPlan)
 
 	var mu sync.Mutex
 	started := make([]string, 0)
@@ -265,7 +306,7 @@ func TestDetectDriftWithoutCallbacksIsUnaffected(t *testing.T) {
 	projects := []models.TypedProject{
 		{Dir: "infra/a", Type: models.Terraform},
 	}
-	d, _ := newTestDetector(".", projects, noDriftOutput)
+	d, _ := newTestDetector(".", projects, noDriftPlan)
 
 	if d.OnProjectStart != nil || d.OnProjectDone != nil {
 		t.Fatal("callbacks must default to nil so the scan path stays independent of the API")
@@ -284,7 +325,7 @@ func TestDetectDriftReportsDrift(t *testing.T) {
 		{Dir: "infra/a", Type: models.Terraform},
 		{Dir: "infra/b", Type: models.Terraform},
 	}
-	d, _ := newTestDetector(".", projects, "Plan: 1 to add, 0 to change, 0 to destroy.")
+	d, _ := newTestDetector(".", projects, driftPlan)
 
 	result := d.DetectDrift(context.Background())
 
@@ -380,7 +421,7 @@ func TestDetectDriftPlanFailureWithNoOutputKeepsErrorText(t *testing.T) {
 
 func TestDetectDriftSuccessLeavesFailedPhaseEmpty(t *testing.T) {
 	projects := []models.TypedProject{{Dir: "infra/a", Type: models.Terraform}}
-	d, _ := newTestDetector(".", projects, noDriftOutput)
+	d, _ := newTestDetector(".", projects, noDriftPlan)
 
 	result := d.DetectDrift(context.Background())
 
@@ -388,3 +429,271 @@ func TestDetectDriftSuccessLeavesFailedPhaseEmpty(t *testing.T) {
 		t.Errorf("FailedPhase = %q, want empty for a successful project", got)
 	}
 }
+
+// TestDetectDriftIgnoresPlanBanner pins that drift comes from the structured plan: output that
+// lacks the "No changes" banner must not be mistaken for drift when the plan itself is empty.
+func TestDetectDriftIgnoresPlanBanner(t *testing.T) {
+	projects := []models.TypedProject{{Dir: "infra/a", Type: models.Terraform}}
+	d := newFailingTestDetector(projects, fakeExecutor{
+		planOutput: "wrapper: planning complete",
+		plan:       noDriftPlan,
+	})
+
+	result := d.DetectDrift(context.Background())
+
+	got := result.ProjectResults[0]
+	if got.Drifted {
+		t.Error("expected no drift for a plan with only no-op changes")
+	}
+	if got.Plan != noDriftPlan {
+		t.Error("expected the result to carry the structured plan")
+	}
+}
+
+func TestDetectDriftRecordsShowFailure(t *testing.T) {
+	projects := []models.TypedProject{{Dir: "infra/a", Type: models.Terraform}}
+	d := newFailingTestDetector(projects, fakeExecutor{})
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return showFailingExecutor{fakeExecutor{dir: dir, mu: &sync.Mutex{}, initDirs: &[]string{}}}
+	}
+
+	result := d.DetectDrift(context.Background())
+
+	got := result.ProjectResults[0]
+	if got.Succeeded {
+		t.Fatal("expected the project to be marked as failed")
+	}
+	if got.FailedPhase != PhasePlan {
+		t.Errorf("FailedPhase = %q, want %q", got.FailedPhase, PhasePlan)
+	}
+}
+
+type showFailingExecutor struct {
+	fakeExecutor
+}
+
+func (f showFailingExecutor) Show(_ context.Context, _ string) (*plan.Plan, error) {
+	return nil, errors.New("no JSON plan found in output")
+}
+
+// modeExecutor serves a different saved plan for the refresh-only run, and records the extra
+// arguments of each plan.
+type modeExecutor struct {
+	fakeExecutor
+	regular, refresh *plan.Plan
+	planArgs         *[][]string
+}
+
+func (m modeExecutor) Plan(_ context.Context, _ string, args ...string) (exec.PlanResult, error) {
+	*m.planArgs = append(*m.planArgs, args)
+	return exec.PlanResult{Output: "plan output", Outcome: exec.PlanChanges}, nil
+}
+
+func (m modeExecutor) Show(_ context.Context, planFile string) (*plan.Plan, error) {
+	if filepath.Base(planFile) == refreshPlanFileName {
+		return m.refresh, nil
+	}
+	return m.regular, nil
+}
+
+func TestDetectDriftBothModesAttributesSource(t *testing.T) {
+	projects := []models.TypedProject{{
+		Dir: "infra/a", Type: models.Terraform,
+		Settings: models.ProjectSettings{DriftMode: models.DriftModeBoth},
+	}}
+	d, _ := newTestDetector(".", projects, nil)
+	var planArgs [][]string
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return modeExecutor{
+			fakeExecutor: fakeExecutor{dir: dir, mu: &sync.Mutex{}, initDirs: &[]string{}},
+			regular:      driftPlan,
+			refresh:      noDriftPlan,
+			planArgs:     &planArgs,
+		}
+	}
+
+	got := d.DetectDrift(context.Background()).ProjectResults[0]
+
+	if len(planArgs) != 2 || !slices.Contains(planArgs[1], "-refresh-only") {
+		t.Fatalf("expected a regular and a refresh-only plan, got %v", planArgs)
+	}
+	if !got.Drifted || got.DriftSource != DriftSourceCode {
+		t.Errorf("Drifted = %v, DriftSource = %q, want drift from code", got.Drifted, got.DriftSource)
+	}
+}
+
+func TestDetectDriftRefreshOnlyModeFromRepoConfig(t *testing.T) {
+	projects := []models.TypedProject{{Dir: "infra/a", Type: models.Terraform}}
+	d, _ := newTestDetector(".", projects, nil)
+	d.RepoConfig.Drift.Mode = string(models.DriftModeRefreshOnly)
+	var planArgs [][]string
+	refresh := &plan.Plan{FormatVersion: "1.2", ResourceDrift: []plan.ResourceChange{
+		{Address: "aws_s3_bucket.logs", Change: plan.Change{Actions: []string{plan.ActionUpdate}}},
+	}}
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return modeExecutor{
+			fakeExecutor: fakeExecutor{dir: dir, mu: &sync.Mutex{}, initDirs: &[]string{}},
+			refresh:      refresh,
+			planArgs:     &planArgs,
+		}
+	}
+
+	got := d.DetectDrift(context.Background()).ProjectResults[0]
+
+	if len(planArgs) != 1 || !slices.Contains(planArgs[0], "-refresh-only") {
+		t.Fatalf("expected only a refresh-only plan, got %v", planArgs)
+	}
+	if !got.Drifted || got.DriftSource != DriftSourceExternal {
+		t.Errorf("Drifted = %v, DriftSource = %q, want external drift", got.Drifted, got.DriftSource)
+	}
+}
+
+// TestDetectDriftSkipsShowWhenPlanHasNoChanges relies on exit code 0 meaning an empty plan:
+// the saved plan is not read back, so a broken show cannot fail a clean project.
+func TestDetectDriftSkipsShowWhenPlanHasNoChanges(t *testing.T) {
+	projects := []models.TypedProject{{Dir: "infra/a", Type: models.Terraform}}
+	d, _ := newTestDetector(".", projects, nil)
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return showFailingExecutor{fakeExecutor{dir: dir, planOutcome: exec.PlanNoChanges,
+			mu: &sync.Mutex{}, initDirs: &[]string{}}}
+	}
+
+	got := d.DetectDrift(context.Background()).ProjectResults[0]
+
+	if !got.Succeeded || got.Drifted {
+		t.Errorf("Succeeded = %v, Drifted = %v, want a clean project", got.Succeeded, got.Drifted)
+	}
+}
+
+func TestExecutorOptionsSelectsWorkspaceLast(t *testing.T) {
+	project := models.TypedProject{
+		Dir:       "infra/a",
+		Workspace: "prod",
+		Settings: models.ProjectSettings{Env: map[string]string{
+			"TF_WORKSPACE": "dev",
+			"AWS_PROFILE":  "ops",
+		}},
+	}
+
+	d, _ := newTestDetector(".", nil, nil)
+
+	got := d.executorOptions(project).Env
+
+	want := []string{"AWS_PROFILE=ops", "TF_WORKSPACE=dev", "TF_WORKSPACE=prod"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Env = %v, want %v", got, want)
+	}
+}
+
+func TestInitAndPlanArgsAppendProjectSettings(t *testing.T) {
+	project := models.TypedProject{Settings: models.ProjectSettings{
+		InitArgs:      []string{"-reconfigure"},
+		PlanArgs:      []string{"-parallelism=5"},
+		VarFiles:      []string{"envs/prod.tfvars"},
+		BackendConfig: []string{"backend.hcl"},
+	}}
+
+	d, _ := newTestDetector(".", nil, nil)
+
+	if got, want := d.initArgs(project), []string{"-upgrade", "-lock=false", "-no-color", "-backend-config=backend.hcl", "-reconfigure"}; !slices.Equal(got, want) {
+		t.Errorf("initArgs() = %v, want %v", got, want)
+	}
+	if got, want := planArgs(project), []string{"-lock=false", "-no-color", "-var-file=envs/prod.tfvars", "-parallelism=5"}; !slices.Equal(got, want) {
+		t.Errorf("planArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestPulumiProjectsGetTheirStackAndOwnArgsOnly(t *testing.T) {
+	project := models.TypedProject{Dir: "stacks/app", Type: models.Pulumi, Workspace: "prod", Settings: models.ProjectSettings{
+		InitArgs: []string{"--no-plugins"},
+		PlanArgs: []string{"--parallel=4"},
+	}}
+
+	d, _ := newTestDetector(".", nil, nil)
+	d.RepoConfig.Drift.Mode = string(models.DriftModeBoth)
+
+	if got := d.initArgs(project); !slices.Equal(got, []string{"--no-plugins"}) {
+		t.Errorf("initArgs() = %v, want only the project's init args", got)
+	}
+	if got := planArgs(project); !slices.Equal(got, []string{"--parallel=4"}) {
+		t.Errorf("planArgs() = %v, want only the project's plan args", got)
+	}
+	opts := d.executorOptions(project)
+	if opts.Stack != "prod" || slices.Contains(opts.Env, "TF_WORKSPACE=prod") {
+		t.Errorf("executorOptions() = %+v, want the stack passed without TF_WORKSPACE", opts)
+	}
+	if got := d.driftMode(project); got != models.DriftModePlan {
+		t.Errorf("driftMode() = %s, want plan", got)
+	}
+}
+
+func TestExecutorOptionsLayersProjectEnvOverSettings(t *testing.T) {
+	d, _ := newTestDetector(".", nil, nil)
+	d.RepoConfig.Settings.Env = map[string]string{"AWS_REGION": "eu-west-1", "AWS_PROFILE": "default"}
+	d.RepoConfig.Settings.EnvPassthrough = []string{"AWS_REGION"}
+	project := models.TypedProject{Settings: models.ProjectSettings{Env: map[string]string{"AWS_PROFILE": "prod"}}}
+
+	got := d.executorOptions(project)
+
+	want := []string{"AWS_PROFILE=default", "AWS_REGION=eu-west-1", "AWS_PROFILE=prod"}
+	if !slices.Equal(got.Env, want) {
+		t.Errorf("Env = %v, want %v", got.Env, want)
+	}
+	if !slices.Equal(got.Passthrough, []string{"AWS_REGION"}) {
+		t.Errorf("Passthrough = %v, want the settings allowlist", got.Passthrough)
+	}
+
+	project.Settings.EnvPassthrough = []string{}
+	if got := d.executorOptions(project).Passthrough; got == nil || len(got) != 0 {
+		t.Errorf("Passthrough = %v, want the project's empty allowlist to win", got)
+	}
+}
+
+// hangingExecutor's plan never finishes on its own, like a hung provider.
+type hangingExecutor struct {
+	fakeExecutor
+}
+
+func (h hangingExecutor) Plan(ctx context.Context, _ string, _ ...string) (exec.PlanResult, error) {
+	<-ctx.Done()
+	return exec.PlanResult{Output: "Refreshing state...", Outcome: exec.PlanFailed}, errors.New("signal: killed")
+}
+
+func TestDetectDriftReportsTimeout(t *testing.T) {
+	projects := []models.TypedProject{{
+		Dir: "infra/a", Type: models.Terraform,
+		Settings: models.ProjectSettings{Timeout: 20 * time.Millisecond},
+	}}
+	d, _ := newTestDetector(".", projects, nil)
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return hangingExecutor{fakeExecutor{dir: dir, mu: &sync.Mutex{}, initDirs: &[]string{}}}
+	}
+
+	result := d.DetectDrift(context.Background())
+
+	got := result.ProjectResults[0]
+	if got.Succeeded || got.FailureReason != ReasonTimeout || got.FailedPhase != PhasePlan {
+		t.Errorf("Succeeded = %v, FailureReason = %q, FailedPhase = %q, want a plan timeout",
+			got.Succeeded, got.FailureReason, got.FailedPhase)
+	}
+	if !strings.Contains(got.ErrorOutput(), "Timed out after 20ms during plan") {
+		t.Errorf("ErrorOutput() = %q, want the timeout noted", got.ErrorOutput())
+	}
+}
+
+func TestDetectDriftCancelledRunIsNotATimeout(t *testing.T) {
+	projects := []models.TypedProject{{Dir: "infra/a", Type: models.Terraform}}
+	d, _ := newTestDetector(".", projects, nil)
+	d.RepoConfig.Settings.Timeout = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		cancel()
+		return hangingExecutor{fakeExecutor{dir: dir, mu: &sync.Mutex{}, initDirs: &[]string{}}}
+	}
+
+	result := d.DetectDrift(ctx)
+
+	if got := result.ProjectResults[0].FailureReason; got != "" {
+		t.Errorf("FailureReason = %q, want empty for a cancelled run", got)
+	}
+}
@@ -0,0 +1,87 @@
+package drift
+
+import (
+	"driftive/pkg/config/repo"
+	"driftive/pkg/models"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ignoreRules drops resource changes configured under drift.ignore.
+type ignoreRules struct {
+	resources     []*regexp.Regexp
+	resourceTypes []string
+	attributes    []*regexp.Regexp
+}
+
+func newIgnoreRules(cfg repo.DriftiveRepoConfigDriftIgnore) ignoreRules {
+	return ignoreRules{
+		resources:     compileGlobs(cfg.Resources),
+		resourceTypes: cfg.ResourceTypes,
+		attributes:    compileGlobs(cfg.Attributes),
+	}
+}
+
+// forProject adds the ignore rules of the project's driftive.yml fragments.
+func (r ignoreRules) forProject(settings models.ProjectSettings) ignoreRules {
+	if len(settings.IgnoreResources) == 0 && len(settings.IgnoreResourceTypes) == 0 && len(settings.IgnoreAttributes) == 0 {
+		return r
+	}
+	return ignoreRules{
+		resources:     append(slices.Clip(r.resources), compileGlobs(settings.IgnoreResources)...),
+		resourceTypes: append(slices.Clip(r.resourceTypes), settings.IgnoreResourceTypes...),
+		attributes:    append(slices.Clip(r.attributes), compileGlobs(settings.IgnoreAttributes)...),
+	}
+}
+
+// compileGlobs turns address/attribute globs into anchored regexps. Only '*' and '?' are special,
+// so the brackets and quotes of instance keys (aws_instance.web["a"]) match literally. A glob also
+// matches anything nested under what it matches.
+func compileGlobs(globs []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		pattern := regexp.QuoteMeta(glob)
+		pattern = strings.ReplaceAll(pattern, `\*`, `.*`)
+		pattern = strings.ReplaceAll(pattern, `\?`, `.`)
+		compiled = append(compiled, regexp.MustCompile(`^`+pattern+`($|[.\[])`))
+	}
+	return compiled
+}
+
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	for _, p := range patterns {
+		if p.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns the resources that still count as drift and how many were ignored.
+func (r ignoreRules) apply(resources []DriftedResource) ([]DriftedResource, int) {
+	kept := make([]DriftedResource, 0, len(resources))
+	ignored := 0
+	for _, resource := range resources {
+		if matchesAny(r.resources, resource.Address) || slices.Contains(r.resourceTypes, resource.Type) {
+			ignored++
+			continue
+		}
+
+		if resource.Action == ActionUpdate && len(resource.ChangedAttributes) > 0 && len(r.attributes) > 0 {
+			var attrs []string
+			for _, attr := range resource.ChangedAttributes {
+				if !matchesAny(r.attributes, attr) {
+					attrs = append(attrs, attr)
+				}
+			}
+			if len(attrs) == 0 {
+				ignored++
+				continue
+			}
+			resource.ChangedAttributes = attrs
+		}
+		kept = append(kept, resource)
+	}
+	return kept, ignored
+}
@@ -0,0 +1,132 @@
+package drift
+
+import (
+	"context"
+	"driftive/pkg/config"
+	"driftive/pkg/config/repo"
+	"driftive/pkg/exec"
+	"driftive/pkg/models"
+	"driftive/pkg/models/plan"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestIgnoreRulesMatchAddressGlobs(t *testing.T) {
+	rules := newIgnoreRules(repo.DriftiveRepoConfigDriftIgnore{
+		Resources: []string{"aws_autoscaling_group.*", "module.legacy", `aws_instance.web["a"]`},
+	})
+
+	tests := []struct {
+		address string
+		ignored bool
+	}{
+		{"aws_autoscaling_group.workers", true},
+		{"aws_autoscaling_group_tag.workers", false},
+		{"module.legacy.aws_s3_bucket.logs", true},
+		{"module.legacy_v2.aws_s3_bucket.logs", false},
+		{`aws_instance.web["a"]`, true},
+		{`aws_instance.web["b"]`, false},
+	}
+
+	for _, tt := range tests {
+		_, ignored := rules.apply([]DriftedResource{{Address: tt.address, Action: ActionUpdate}})
+		if (ignored == 1) != tt.ignored {
+			t.Errorf("address %q ignored = %v, want %v", tt.address, ignored == 1, tt.ignored)
+		}
+	}
+}
+
+func TestIgnoreRulesMatchResourceTypes(t *testing.T) {
+	rules := newIgnoreRules(repo.DriftiveRepoConfigDriftIgnore{ResourceTypes: []string{"aws_autoscaling_group"}})
+
+	kept, ignored := rules.apply([]DriftedResource{
+		{Address: "aws_autoscaling_group.a", Type: "aws_autoscaling_group", Action: ActionUpdate},
+		{Address: "aws_instance.b", Type: "aws_instance", Action: ActionUpdate},
+	})
+
+	if ignored != 1 || len(kept) != 1 || kept[0].Address != "aws_instance.b" {
+		t.Errorf("expected only the autoscaling group ignored, kept %+v", kept)
+	}
+}
+
+func TestIgnoreRulesForProjectAddTheProjectsOwn(t *testing.T) {
+	repoRules := newIgnoreRules(repo.DriftiveRepoConfigDriftIgnore{ResourceTypes: []string{"aws_autoscaling_group"}})
+	rules := repoRules.forProject(models.ProjectSettings{IgnoreResources: []string{"module.legacy"}})
+
+	resources := []DriftedResource{
+		{Address: "aws_autoscaling_group.a", Type: "aws_autoscaling_group", Action: ActionUpdate},
+		{Address: "module.legacy.aws_s3_bucket.logs", Type: "aws_s3_bucket", Action: ActionUpdate},
+		{Address: "aws_instance.b", Type: "aws_instance", Action: ActionUpdate},
+	}
+	if kept, ignored := rules.apply(resources); ignored != 2 || len(kept) != 1 {
+		t.Errorf("expected the repository and project rules to apply, kept %+v", kept)
+	}
+	if _, ignored := repoRules.apply(resources); ignored != 1 {
+		t.Errorf("expected the repository rules to be left alone, ignored %d", ignored)
+	}
+}
+
+func TestIgnoreRulesDropUpdatesWhoseAttributesAllMatch(t *testing.T) {
+	rules := newIgnoreRules(repo.DriftiveRepoConfigDriftIgnore{Attributes: []string{"tags.LastModified", "tags_all"}})
+
+	kept, ignored := rules.apply([]DriftedResource{
+		{Address: "aws_s3_bucket.a", Action: ActionUpdate, ChangedAttributes: []string{"tags.LastModified", "tags_all.LastModified"}},
+		{Address: "aws_s3_bucket.b", Action: ActionUpdate, ChangedAttributes: []string{"tags.LastModified", "versioning[0].enabled"}},
+		// Attribute rules never hide a resource being created, deleted or replaced.
+		{Address: "aws_s3_bucket.c", Action: ActionReplace, ChangedAttributes: []string{"tags.LastModified"}},
+	})
+
+	if ignored != 1 {
+		t.Errorf("ignored = %d, want 1", ignored)
+	}
+	if len(kept) != 2 {
+		t.Fatalf("expected 2 resources kept, got %+v", kept)
+	}
+	if !slices.Equal(kept[0].ChangedAttributes, []string{"versioning[0].enabled"}) {
+		t.Errorf("expected the ignored attribute removed from the kept update, got %v", kept[0].ChangedAttributes)
+	}
+}
+
+// TestDetectDriftAppliesIgnoreRules is the end-to-end case from the bug report: a project whose
+// only change is a noisy tag must not be reported as drifted.
+func TestDetectDriftAppliesIgnoreRules(t *testing.T) {
+	var mu sync.Mutex
+	initDirs := make([]string, 0)
+	noisy := &plan.Plan{
+		FormatVersion: "1.2",
+		ResourceChanges: []plan.ResourceChange{{
+			Address: "aws_s3_bucket.logs",
+			Type:    "aws_s3_bucket",
+			Change: plan.Change{
+				Actions: []string{plan.ActionUpdate},
+				Before:  []byte(`{"tags": {"LastModified": "monday"}}`),
+				After:   []byte(`{"tags": {"LastModified": "tuesday"}}`),
+			},
+		}},
+	}
+
+	repoConfig := &repo.DriftiveRepoConfig{
+		Drift: repo.DriftiveRepoConfigDrift{
+			Ignore: repo.DriftiveRepoConfigDriftIgnore{Attributes: []string{"tags.LastModified"}},
+		},
+	}
+	d := NewDriftDetector(".", []models.TypedProject{{Dir: "infra/a", Type: models.Terraform}},
+		&config.DriftiveConfig{Concurrency: 1}, repoConfig, nil, nil)
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return fakeExecutor{dir: dir, plan: noisy, mu: &mu, initDirs: &initDirs}
+	}
+
+	result := d.DetectDrift(context.Background())
+
+	got := result.ProjectResults[0]
+	if got.Drifted {
+		t.Error("expected the project not to drift when every change is ignored")
+	}
+	if got.IgnoredResources != 1 {
+		t.Errorf("IgnoredResources = %d, want 1", got.IgnoredResources)
+	}
+	if result.TotalDrifted != 0 {
+		t.Errorf("TotalDrifted = %d, want 0", result.TotalDrifted)
+	}
+}
@@ -5,9 +5,9 @@ import (
 	"driftive/pkg/config/repo"
 	"driftive/pkg/exec"
 	"driftive/pkg/models"
+	"driftive/pkg/models/plan"
 	"driftive/pkg/utils"
 	"driftive/pkg/vcs/vcstypes"
-	"sync"
 	"time"
 )
 
@@ -24,19 +24,33 @@ type DriftDetector struct {
 	Config     *config.DriftiveConfig
 	RepoConfig *repo.DriftiveRepoConfig
 
-	workerWg  sync.WaitGroup
-	results   chan DriftProjectResult
 	semaphore chan struct{}
 
 	// newExecutor builds the executor for a project. Defaults to exec.NewExecutor; tests
 	// substitute a fake so DetectDrift can run without terraform/tofu installed.
-	newExecutor func(dir string, t models.ProjectType) exec.Executor
+	newExecutor func(dir string, t models.ProjectType, opts exec.Options) exec.Executor
 
-	// OnProjectStart receives the repo-relative dir when a project's analysis begins.
-	// OnProjectDone receives the finished result, whose Project.Dir is the same repo-relative
-	// string. Both are optional; when nil the scan behaves exactly as if they did not exist.
-	// Called from worker goroutines, so an implementation must be safe for concurrent use and
-	// must not block — anything slow here serializes the scan.
+	// ignore drops the changes configured under drift.ignore before drift is decided.
+	ignore ignoreRules
+
+	// retry reruns projects that failed with a transient error, per settings.retry.
+	retry retryPolicy
+
+	// pluginCacheDir is the shared TF_PLUGIN_CACHE_DIR, empty when disabled. initSlot holds the
+	// one project allowed to run init while it is in use.
+	pluginCacheDir string
+	initSlot       chan struct{}
+
+	// workspacesExpanded guards ExpandWorkspaces. workspaceErrors holds, by project dir, the
+	// workspace listings that failed.
+	workspacesExpanded bool
+	workspaceErrors    map[string]workspaceError
+
+	// OnProjectStart receives the project's key (its repo-relative dir, plus @workspace when
+	// it plans one) when its analysis begins. OnProjectDone receives the finished result, whose
+	// Project.Key() is the same string. Both are optional; when nil the scan behaves exactly as
+	// if they did not exist. Called from worker goroutines, so an implementation must be safe
+	// for concurrent use and must not block — anything slow here serializes the scan.
 	OnProjectStart func(dir string)
 	OnProjectDone  func(result DriftProjectResult)
 
@@ -49,6 +63,14 @@ const (
 	PhasePlan = "plan"
 )
 
+// Reasons reported by DriftProjectResult.FailureReason.
+const (
+	// ReasonTimeout is a project killed after running longer than its timeout.
+	ReasonTimeout = "timeout"
+	// ReasonBlocked is a project not analyzed because a project it depends on failed.
+	ReasonBlocked = "blocked"
+)
+
 type DriftProjectResult struct {
 	Project models.TypedProject `json:"project"`
 	Drifted bool                `json:"drifted"`
@@ -60,6 +82,30 @@ type DriftProjectResult struct {
 	SkippedDueToPR bool `json:"skipped_due_to_pr"`
 	// FailedPhase is PhaseInit or PhasePlan when Succeeded is false, empty otherwise.
 	FailedPhase string `json:"failed_phase,omitempty"`
+	// FailureReason tells why a failed project failed, when it was not the tool reporting an
+	// error: ReasonTimeout or ReasonBlocked. Empty otherwise.
+	FailureReason string `json:"failure_reason,omitempty"`
+	// BlockedBy is the key of the failed project that blocked this one. Set only with
+	// ReasonBlocked.
+	BlockedBy string `json:"blocked_by,omitempty"`
+	// Attempts is the number of runs the result took: 1, or more when transient failures were
+	// retried per settings.retry.
+	Attempts int `json:"attempts,omitempty"`
+	// ToolVersion is the terraform or tofu version the project ran with, picked from
+	// settings.binaries_dir. Empty when the binary on PATH was used.
+	ToolVersion string `json:"tool_version,omitempty"`
+	// Plan is the structured plan drift was decided from. Nil when the plan did not complete.
+	// Never serialized: before/after values can carry sensitive attributes in plain text.
+	Plan *plan.Plan `json:"-"`
+	// DriftedResources breaks a drifted project down per resource. Empty when the drift is
+	// limited to outputs or the plan did not complete.
+	DriftedResources []DriftedResource `json:"drifted_resources,omitempty"`
+	// IgnoredResources counts the resource changes dropped by drift.ignore rules.
+	IgnoredResources int `json:"ignored_resources,omitempty"`
+	// DriftSource tells infrastructure changed outside Terraform (DriftSourceExternal) from
+	// merged-but-unapplied code (DriftSourceCode), or DriftSourceMixed for both. Only set when a
+	// refresh-only plan ran.
+	DriftSource string `json:"drift_source,omitempty"`
 }
 
 // ErrorOutput returns the output explaining why a failed project failed. Only meaningful when
@@ -91,10 +137,15 @@ func NewDriftDetector(repoDir string, projects []models.TypedProject, cfg *confi
 		Projects:    projects,
 		Config:      cfg,
 		RepoConfig:  repoConfig,
-		workerWg:    sync.WaitGroup{},
-		results:     nil,
 		semaphore:   make(chan struct{}, utils.Max(1, cfg.Concurrency)),
 		newExecutor: exec.NewExecutor,
+		ignore:      newIgnoreRules(repoConfig.Drift.Ignore),
+		retry:       newRetryPolicy(repoConfig.Settings.Retry),
+
+		pluginCacheDir: pluginCacheDir(repoConfig.Settings.PluginCache),
+		initSlot:       make(chan struct{}, 1),
+
+		workspaceErrors: make(map[string]workspaceError),
 
 		Stash: Stash{
 			OpenPRChangedFiles: openPRChangedFiles,
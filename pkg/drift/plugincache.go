@@ -0,0 +1,66 @@
+package drift
+
+import (
+	"context"
+	"driftive/pkg/config/repo"
+	"driftive/pkg/exec"
+	"driftive/pkg/models"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// pluginCacheDir resolves and creates the shared plugin cache directory. Empty when the cache is
+// disabled or cannot be created, in which case each project downloads its own providers.
+func pluginCacheDir(cfg repo.DriftiveRepoConfigPluginCache) string {
+	if !cfg.Enabled {
+		return ""
+	}
+
+	dir := cfg.Dir
+	if dir == "" {
+		cacheDir, err := os.UserCacheDir()
+		if err != nil {
+			log.Warn().Msgf("Plugin cache disabled, no user cache dir: %v", err)
+			return ""
+		}
+		dir = filepath.Join(cacheDir, "driftive", "plugin-cache")
+	}
+	// Terraform resolves the cache dir from each project's working directory.
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		log.Warn().Msgf("Plugin cache disabled, invalid dir %s: %v", cfg.Dir, err)
+		return ""
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Warn().Msgf("Plugin cache disabled, failed to create %s: %v", dir, err)
+		return ""
+	}
+	log.Info().Msgf("Using plugin cache %s", dir)
+	return dir
+}
+
+// acquireInitSlot waits for the project's turn to run init. With the plugin cache enabled, inits
+// run one at a time: terraform does not guarantee the cache is safe for concurrent writers. Plans
+// still run concurrently. Waiting stops when ctx is done. The returned release hands the turn
+// back and may be called more than once.
+func (d *DriftDetector) acquireInitSlot(ctx context.Context) (func(), error) {
+	if d.pluginCacheDir == "" {
+		return func() {}, nil
+	}
+	select {
+	case d.initSlot <- struct{}{}:
+		return sync.OnceFunc(func() { <-d.initSlot }), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// runInit initializes the project, then calls release to hand back the init slot.
+func (d *DriftDetector) runInit(ctx context.Context, executor exec.Executor, project models.TypedProject,
+	release func()) (string, error) {
+	defer release()
+	return executor.Init(ctx, d.initArgs(project)...)
+}
@@ -0,0 +1,184 @@
+package drift
+
+import (
+	"context"
+	"driftive/pkg/config/repo"
+	"driftive/pkg/exec"
+	"driftive/pkg/models"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPluginCacheDir(t *testing.T) {
+	if got := pluginCacheDir(repo.DriftiveRepoConfigPluginCache{Dir: t.TempDir()}); got != "" {
+		t.Errorf("pluginCacheDir() = %q, want empty when disabled", got)
+	}
+
+	dir := filepath.Join(t.TempDir(), "plugins")
+	got := pluginCacheDir(repo.DriftiveRepoConfigPluginCache{Enabled: true, Dir: dir})
+	if got != dir {
+		t.Errorf("pluginCacheDir() = %q, want %q", got, dir)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("cache dir was not created: %v", err)
+	}
+}
+
+func TestPluginCacheDirIsAbsolute(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	got := pluginCacheDir(repo.DriftiveRepoConfigPluginCache{Enabled: true, Dir: "cache"})
+
+	if !filepath.IsAbs(got) || filepath.Base(got) != "cache" {
+		t.Errorf("pluginCacheDir() = %q, want an absolute path", got)
+	}
+}
+
+func TestExecutorOptionsSetsPluginCacheDir(t *testing.T) {
+	d, _ := newTestDetector(".", nil, nil)
+	d.pluginCacheDir = "/cache/plugins"
+	d.RepoConfig.Settings.Env = map[string]string{"TF_PLUGIN_CACHE_DIR": "/custom"}
+
+	got := d.executorOptions(models.TypedProject{}).Env
+
+	want := []string{"TF_PLUGIN_CACHE_DIR=/cache/plugins", "TF_PLUGIN_CACHE_DIR=/custom"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Env = %v, want %v so settings.env can override the cache", got, want)
+	}
+}
+
+func TestInitArgsHonorLockFile(t *testing.T) {
+	d, _ := newTestDetector(".", nil, nil)
+	d.RepoConfig.Settings.HonorLockFile = true
+
+	if got, want := d.initArgs(models.TypedProject{}), []string{"-lock=false", "-no-color"}; !slices.Equal(got, want) {
+		t.Errorf("initArgs() = %v, want %v", got, want)
+	}
+}
+
+// slowInitExecutor records how many inits overlap. With a barrier, each init waits until all
+// the barrier's inits are running at once.
+type slowInitExecutor struct {
+	fakeExecutor
+	active, peak *int
+	duration     time.Duration
+	barrier      *sync.WaitGroup
+}
+
+func (s slowInitExecutor) Init(ctx context.Context, args ...string) (string, error) {
+	s.mu.Lock()
+	*s.active++
+	*s.peak = max(*s.peak, *s.active)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		*s.active--
+		s.mu.Unlock()
+	}()
+
+	if s.barrier != nil {
+		s.barrier.Done()
+		arrived := make(chan struct{})
+		go func() {
+			s.barrier.Wait()
+			close(arrived)
+		}()
+		select {
+		case <-arrived:
+			return "init ok", nil
+		case <-time.After(5 * time.Second):
+			return "", errors.New("inits did not run concurrently")
+		}
+	}
+
+	select {
+	case <-time.After(s.duration):
+		return "init ok", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// newSlowInitDetector wires a detector running every project at once through slowInitExecutor.
+func newSlowInitDetector(projects []models.TypedProject, cacheDir string, template slowInitExecutor) (*DriftDetector, *int) {
+	d, _ := newTestDetector(".", projects, noDriftPlan)
+	d.semaphore = make(chan struct{}, len(projects))
+	d.pluginCacheDir = cacheDir
+	var active, peak int
+	mu := &sync.Mutex{}
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		executor := template
+		executor.fakeExecutor = fakeExecutor{dir: dir, plan: noDriftPlan, mu: mu, initDirs: &[]string{}}
+		executor.active, executor.peak = &active, &peak
+		return executor
+	}
+	return d, &peak
+}
+
+var slowInitProjects = []models.TypedProject{
+	{Dir: "a", Type: models.Terraform}, {Dir: "b", Type: models.Terraform},
+	{Dir: "c", Type: models.Terraform}, {Dir: "d", Type: models.Terraform},
+}
+
+func TestPluginCacheSerializesInits(t *testing.T) {
+	d, peak := newSlowInitDetector(slowInitProjects, "/cache/plugins", slowInitExecutor{duration: 5 * time.Millisecond})
+
+	result := d.DetectDrift(context.Background())
+
+	if *peak != 1 {
+		t.Errorf("peak concurrent inits = %d, want 1", *peak)
+	}
+	if result.TotalErrored != 0 {
+		t.Errorf("TotalErrored = %d, want 0", result.TotalErrored)
+	}
+}
+
+func TestInitsRunConcurrentlyWithoutPluginCache(t *testing.T) {
+	barrier := &sync.WaitGroup{}
+	barrier.Add(len(slowInitProjects))
+	d, _ := newSlowInitDetector(slowInitProjects, "", slowInitExecutor{barrier: barrier})
+
+	result := d.DetectDrift(context.Background())
+
+	for _, r := range result.ProjectResults {
+		if !r.Succeeded {
+			t.Errorf("%s: %s", r.Project.Dir, r.InitOutput)
+		}
+	}
+}
+
+func TestWaitingForInitSlotDoesNotCountAgainstTimeout(t *testing.T) {
+	// Each init takes most of the timeout, so the last project waits longer than its timeout.
+	projects := slowInitProjects[:3]
+	d, _ := newSlowInitDetector(projects, "/cache/plugins", slowInitExecutor{duration: 300 * time.Millisecond})
+	d.RepoConfig.Settings.Timeout = 500 * time.Millisecond
+
+	result := d.DetectDrift(context.Background())
+
+	for _, r := range result.ProjectResults {
+		if !r.Succeeded {
+			t.Errorf("%s failed (%s): %s", r.Project.Dir, r.FailureReason, r.InitOutput)
+		}
+	}
+}
+
+func TestAcquireInitSlotStopsWhenContextDone(t *testing.T) {
+	d, _ := newTestDetector(".", nil, nil)
+	d.pluginCacheDir = "/cache/plugins"
+	release, err := d.acquireInitSlot(context.Background())
+	if err != nil {
+		t.Fatalf("acquireInitSlot() error = %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := d.acquireInitSlot(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("acquireInitSlot() error = %v, want context.Canceled", err)
+	}
+}
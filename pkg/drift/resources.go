@@ -0,0 +1,239 @@
+package drift
+
+import (
+	"driftive/pkg/models"
+	"driftive/pkg/models/plan"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Actions reported by DriftedResource.Action.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionReplace = "replace"
+)
+
+// Sources reported by DriftedResource.Source and DriftProjectResult.DriftSource.
+const (
+	// DriftSourceExternal is infrastructure changed outside Terraform.
+	DriftSourceExternal = "external"
+	// DriftSourceCode is configuration changed but not applied yet.
+	DriftSourceCode = "code"
+	// DriftSourceMixed is a project with both kinds of drift.
+	DriftSourceMixed = "mixed"
+)
+
+// DriftedResource is one resource instance the plan would change.
+type DriftedResource struct {
+	Address string `json:"address"`
+	// ModulePath is the module the resource lives in, e.g. module.vpc. Empty for the root module.
+	ModulePath string `json:"module_path,omitempty"`
+	Type       string `json:"type"`
+	Provider   string `json:"provider"`
+	Action     string `json:"action"`
+	// ChangedAttributes are the attribute paths that differ, e.g. tags.LastModified or
+	// ingress[0].cidr_blocks. Only set for updates and replacements.
+	ChangedAttributes []string `json:"changed_attributes,omitempty"`
+	// Source is DriftSourceExternal or DriftSourceCode. Only known when a refresh-only plan
+	// ran, empty otherwise.
+	Source string `json:"source,omitempty"`
+}
+
+// attributeResources lists the drifted resources for the given mode. Under DriftModeBoth a
+// change is attributed to an outside change when the refresh-only plan saw that resource drift,
+// and to unapplied code otherwise.
+func attributeResources(mode models.DriftMode, regular, refresh *plan.Plan) []DriftedResource {
+	switch mode {
+	case models.DriftModeRefreshOnly:
+		return withSource(driftedResources(refresh.ResourceDrift), DriftSourceExternal)
+	case models.DriftModeBoth:
+		external := driftedResources(refresh.ResourceDrift)
+		changedOutside := make(map[string]bool, len(external))
+		for _, r := range external {
+			changedOutside[r.Address] = true
+		}
+
+		resources := driftedResources(regular.ResourceChanges)
+		planned := make(map[string]bool, len(resources))
+		for i := range resources {
+			planned[resources[i].Address] = true
+			resources[i].Source = DriftSourceCode
+			if changedOutside[resources[i].Address] {
+				resources[i].Source = DriftSourceExternal
+			}
+		}
+		for _, r := range external {
+			if !planned[r.Address] {
+				r.Source = DriftSourceExternal
+				resources = append(resources, r)
+			}
+		}
+		return resources
+	default:
+		return driftedResources(regular.ResourceChanges)
+	}
+}
+
+func withSource(resources []DriftedResource, source string) []DriftedResource {
+	for i := range resources {
+		resources[i].Source = source
+	}
+	return resources
+}
+
+// driftSource summarizes the sources of a project's drifted resources. Empty when unknown.
+func driftSource(resources []DriftedResource) string {
+	source := ""
+	for _, r := range resources {
+		switch {
+		case r.Source == "":
+			return ""
+		case source == "":
+			source = r.Source
+		case source != r.Source:
+			return DriftSourceMixed
+		}
+	}
+	return source
+}
+
+// driftedResources lists the resource changes that are not no-ops.
+func driftedResources(changes []plan.ResourceChange) []DriftedResource {
+	var resources []DriftedResource
+	for _, rc := range changes {
+		action := resourceAction(rc.Change.Actions)
+		if action == "" {
+			continue
+		}
+		resource := DriftedResource{
+			Address:    rc.Address,
+			ModulePath: rc.ModuleAddress,
+			Type:       rc.Type,
+			Provider:   rc.ProviderName,
+			Action:     action,
+		}
+		if action == ActionUpdate || action == ActionReplace {
+			resource.ChangedAttributes = changedAttributes(rc.Change)
+		}
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+// resourceAction collapses the plan's action list into a single action. Empty for no-ops and for
+// reads: data sources read during apply change nothing in the infrastructure, so they are not
+// drift.
+func resourceAction(actions []string) string {
+	switch {
+	case slices.Contains(actions, plan.ActionDelete) && slices.Contains(actions, plan.ActionCreate):
+		return ActionReplace
+	case slices.Contains(actions, plan.ActionCreate):
+		return ActionCreate
+	case slices.Contains(actions, plan.ActionUpdate):
+		return ActionUpdate
+	case slices.Contains(actions, plan.ActionDelete):
+		return ActionDelete
+	default:
+		return ""
+	}
+}
+
+// changedAttributes returns the sorted attribute paths that differ between before and after,
+// plus those only known after apply.
+func changedAttributes(change plan.Change) []string {
+	paths := make(map[string]bool)
+	diffValues("", decodeValue(change.Before), decodeValue(change.After), paths)
+	collectUnknown("", decodeValue(change.AfterUnknown), paths)
+
+	attrs := make([]string, 0, len(paths))
+	for p := range paths {
+		attrs = append(attrs, p)
+	}
+	sort.Strings(attrs)
+	return attrs
+}
+
+func decodeValue(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+func diffValues(path string, before, after any, paths map[string]bool) {
+	switch b := before.(type) {
+	case map[string]any:
+		a, ok := after.(map[string]any)
+		if !ok {
+			markChanged(path, paths)
+			return
+		}
+		for key, bv := range b {
+			diffValues(joinKey(path, key), bv, a[key], paths)
+		}
+		for key, av := range a {
+			if _, seen := b[key]; !seen {
+				diffValues(joinKey(path, key), nil, av, paths)
+			}
+		}
+	case []any:
+		a, ok := after.([]any)
+		if !ok {
+			markChanged(path, paths)
+			return
+		}
+		for i := 0; i < max(len(a), len(b)); i++ {
+			var bv, av any
+			if i < len(b) {
+				bv = b[i]
+			}
+			if i < len(a) {
+				av = a[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), bv, av, paths)
+		}
+	default:
+		if before != after {
+			markChanged(path, paths)
+		}
+	}
+}
+
+// collectUnknown marks every path whose after_unknown value is true.
+func collectUnknown(path string, unknown any, paths map[string]bool) {
+	switch u := unknown.(type) {
+	case bool:
+		if u {
+			markChanged(path, paths)
+		}
+	case map[string]any:
+		for key, v := range u {
+			collectUnknown(joinKey(path, key), v, paths)
+		}
+	case []any:
+		for i, v := range u {
+			collectUnknown(fmt.Sprintf("%s[%d]", path, i), v, paths)
+		}
+	}
+}
+
+func markChanged(path string, paths map[string]bool) {
+	if path != "" {
+		paths[path] = true
+	}
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,149 @@
+package drift
+
+import (
+	"driftive/pkg/models"
+	"driftive/pkg/models/plan"
+	"driftive/pkg/utils"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestDriftedResourcesFromPlan(t *testing.T) {
+	p, err := plan.Parse(utils.GetTestFile("test/output/plan_changes.json"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	resources := driftedResources(p.ResourceChanges)
+
+	if len(resources) != 1 {
+		t.Fatalf("expected the no-op change to be dropped, got %d resource(s): %+v", len(resources), resources)
+	}
+	got := resources[0]
+	if got.Address != "null_resource.foo" || got.Action != ActionReplace || got.Type != "null_resource" {
+		t.Errorf("unexpected resource: %+v", got)
+	}
+	if got.Provider != "registry.terraform.io/hashicorp/null" {
+		t.Errorf("Provider = %q", got.Provider)
+	}
+	if want := []string{"id", "triggers.foo"}; !slices.Equal(got.ChangedAttributes, want) {
+		t.Errorf("ChangedAttributes = %v, want %v", got.ChangedAttributes, want)
+	}
+}
+
+func TestDriftedResourcesKeepsModulePath(t *testing.T) {
+	p, err := plan.Parse(utils.GetTestFile("test/output/plan_changes.json"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	resources := driftedResources(p.ResourceDrift)
+
+	if len(resources) != 1 {
+		t.Fatalf("expected 1 drifted resource, got %d", len(resources))
+	}
+	if want := []string{"tags.LastModified"}; !slices.Equal(resources[0].ChangedAttributes, want) {
+		t.Errorf("ChangedAttributes = %v, want %v", resources[0].ChangedAttributes, want)
+	}
+}
+
+func TestDriftedResourcesSkipsDataSourceReads(t *testing.T) {
+	regular := &plan.Plan{ResourceChanges: []plan.ResourceChange{{
+		Address: "data.aws_iam_policy_document.assume",
+		Mode:    "data",
+		Type:    "aws_iam_policy_document",
+		Change:  plan.Change{Actions: []string{plan.ActionRead}},
+	}}}
+
+	if resources := driftedResources(regular.ResourceChanges); len(resources) != 0 {
+		t.Errorf("expected data source reads to be dropped, got %+v", resources)
+	}
+	if resources := attributeResources(models.DriftModePlan, regular, nil); len(resources) != 0 {
+		t.Errorf("expected no drift from data source reads, got %+v", resources)
+	}
+}
+
+func TestResourceAction(t *testing.T) {
+	tests := []struct {
+		actions []string
+		want    string
+	}{
+		{[]string{"no-op"}, ""},
+		{[]string{"create"}, ActionCreate},
+		{[]string{"update"}, ActionUpdate},
+		{[]string{"delete"}, ActionDelete},
+		{[]string{"delete", "create"}, ActionReplace},
+		{[]string{"create", "delete"}, ActionReplace},
+		{[]string{"read"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := resourceAction(tt.actions); got != tt.want {
+			t.Errorf("resourceAction(%v) = %q, want %q", tt.actions, got, tt.want)
+		}
+	}
+}
+
+func TestChangedAttributesWalksNestedValues(t *testing.T) {
+	change := plan.Change{
+		Before: []byte(`{"name": "a", "ingress": [{"port": 80}, {"port": 443}], "tags": {"Team": "x"}}`),
+		After:  []byte(`{"name": "a", "ingress": [{"port": 8080}], "tags": {"Team": "x", "Env": "prod"}}`),
+	}
+
+	want := []string{"ingress[0].port", "ingress[1]", "tags.Env"}
+	if got := changedAttributes(change); !slices.Equal(got, want) {
+		t.Errorf("changedAttributes() = %v, want %v", got, want)
+	}
+}
+
+func change(address string, actions ...string) plan.ResourceChange {
+	return plan.ResourceChange{Address: address, Change: plan.Change{Actions: actions}}
+}
+
+func TestAttributeResourcesSplitsExternalFromCode(t *testing.T) {
+	regular := &plan.Plan{ResourceChanges: []plan.ResourceChange{
+		change("aws_s3_bucket.logs", plan.ActionUpdate),
+		change("aws_iam_role.new", plan.ActionCreate),
+	}}
+	refresh := &plan.Plan{ResourceDrift: []plan.ResourceChange{
+		change("aws_s3_bucket.logs", plan.ActionUpdate),
+		change("aws_instance.gone", plan.ActionDelete),
+	}}
+
+	resources := attributeResources(models.DriftModeBoth, regular, refresh)
+
+	sources := map[string]string{}
+	for _, r := range resources {
+		sources[r.Address] = r.Source
+	}
+	want := map[string]string{
+		"aws_s3_bucket.logs": DriftSourceExternal,
+		"aws_iam_role.new":   DriftSourceCode,
+		"aws_instance.gone":  DriftSourceExternal,
+	}
+	if !maps.Equal(sources, want) {
+		t.Errorf("sources = %v, want %v", sources, want)
+	}
+	if got := driftSource(resources); got != DriftSourceMixed {
+		t.Errorf("driftSource() = %q, want %q", got, DriftSourceMixed)
+	}
+}
+
+func TestAttributeResourcesRefreshOnlyIsExternal(t *testing.T) {
+	refresh := &plan.Plan{ResourceDrift: []plan.ResourceChange{change("aws_s3_bucket.logs", plan.ActionUpdate)}}
+
+	resources := attributeResources(models.DriftModeRefreshOnly, nil, refresh)
+
+	if got := driftSource(resources); got != DriftSourceExternal {
+		t.Errorf("driftSource() = %q, want %q", got, DriftSourceExternal)
+	}
+}
+
+func TestDriftSourceUnknownInPlanMode(t *testing.T) {
+	regular := &plan.Plan{ResourceChanges: []plan.ResourceChange{change("aws_s3_bucket.logs", plan.ActionUpdate)}}
+
+	if got := driftSource(attributeResources(models.DriftModePlan, regular, nil)); got != "" {
+		t.Errorf("driftSource() = %q, want empty without a refresh-only plan", got)
+	}
+}
@@ -0,0 +1,86 @@
+package drift
+
+import (
+	"context"
+	"driftive/pkg/config/repo"
+	"driftive/pkg/models"
+	"driftive/pkg/utils"
+	"regexp"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxRetryBackoff bounds the doubling of the backoff, so many attempts do not stall the run for
+// hours. A configured backoff above it is used as is.
+const maxRetryBackoff = 5 * time.Minute
+
+// retryPolicy reruns projects that failed with a transient error, per settings.retry.
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+	patterns []*regexp.Regexp
+}
+
+func newRetryPolicy(cfg repo.DriftiveRepoConfigRetry) retryPolicy {
+	backoff := cfg.Backoff
+	if backoff == 0 {
+		backoff = repo.DefaultRetryBackoff
+	}
+	patterns := cfg.Patterns
+	if len(patterns) == 0 {
+		patterns = repo.DefaultRetryPatterns
+	}
+
+	// Patterns are validated when the config is loaded, so one that still fails to compile is
+	// dropped rather than failing the run.
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			compiled = append(compiled, re)
+		}
+	}
+	return retryPolicy{attempts: utils.Max(1, cfg.Attempts), backoff: backoff, patterns: compiled}
+}
+
+// retryable tells whether a failed result is worth another run. Timeouts are not retried: a
+// project that used its whole budget once would likely do it again.
+func (p retryPolicy) retryable(result DriftProjectResult) bool {
+	if result.Succeeded || result.FailureReason == ReasonTimeout {
+		return false
+	}
+	return matchesAny(p.patterns, result.ErrorOutput())
+}
+
+// delay is the wait before the given retry, 1 being the first: the backoff, doubled before each
+// further retry up to maxRetryBackoff.
+func (p retryPolicy) delay(retry int) time.Duration {
+	limit := max(p.backoff, maxRetryBackoff)
+	delay := p.backoff
+	for i := 1; i < retry && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// detectDriftWithRetry runs detectDrift and reruns it while the failure is transient, up to the
+// policy's attempts. Each run gets the project's full timeout. The result records the number of
+// runs it took.
+func (d *DriftDetector) detectDriftWithRetry(ctx context.Context, project models.TypedProject) (DriftProjectResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := d.detectDrift(ctx, project)
+		result.Attempts = attempt
+		if err == nil || attempt >= d.retry.attempts || !d.retry.retryable(result) {
+			return result, err
+		}
+
+		backoff := d.retry.delay(attempt)
+		log.Warn().Msgf("Transient %s error in %s (attempt %d/%d), retrying in %s: %v",
+			result.FailedPhase, project.Key(), attempt, d.retry.attempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+	}
+}
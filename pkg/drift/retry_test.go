@@ -0,0 +1,145 @@
+package drift
+
+import (
+	"context"
+	"driftive/pkg/config/repo"
+	"driftive/pkg/exec"
+	"driftive/pkg/models"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyExecutor's plan fails with output until it has been called failures times.
+type flakyExecutor struct {
+	fakeExecutor
+	output   string
+	failures int
+	calls    *int
+}
+
+func (f flakyExecutor) Plan(ctx context.Context, planFile string, args ...string) (exec.PlanResult, error) {
+	f.mu.Lock()
+	*f.calls++
+	call := *f.calls
+	f.mu.Unlock()
+	if call <= f.failures {
+		return exec.PlanResult{Output: f.output, Outcome: exec.PlanFailed}, errors.New("exit status 1")
+	}
+	return f.fakeExecutor.Plan(ctx, planFile, args...)
+}
+
+func newFlakyTestDetector(retry repo.DriftiveRepoConfigRetry, output string, failures int) (*DriftDetector, *int) {
+	projects := []models.TypedProject{{Dir: "infra/a", Type: models.Terraform}}
+	d, _ := newTestDetector(".", projects, noDriftPlan)
+	d.retry = newRetryPolicy(retry)
+	calls := 0
+	mu := &sync.Mutex{}
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return flakyExecutor{
+			fakeExecutor: fakeExecutor{dir: dir, plan: noDriftPlan, mu: mu, initDirs: &[]string{}},
+			output:       output,
+			failures:     failures,
+			calls:        &calls,
+		}
+	}
+	return d, &calls
+}
+
+func TestDetectDriftRetriesTransientFailure(t *testing.T) {
+	retry := repo.DriftiveRepoConfigRetry{Attempts: 3, Backoff: time.Millisecond}
+	d, calls := newFlakyTestDetector(retry, "Error: Error acquiring the state lock", 1)
+
+	got := d.DetectDrift(context.Background()).ProjectResults[0]
+
+	if !got.Succeeded || got.Attempts != 2 || *calls != 2 {
+		t.Errorf("Succeeded = %v, Attempts = %d, plan calls = %d, want success on the second attempt",
+			got.Succeeded, got.Attempts, *calls)
+	}
+}
+
+func TestDetectDriftGivesUpAfterAttempts(t *testing.T) {
+	retry := repo.DriftiveRepoConfigRetry{Attempts: 3, Backoff: time.Millisecond}
+	d, calls := newFlakyTestDetector(retry, "net/http: TLS handshake timeout", 5)
+
+	got := d.DetectDrift(context.Background()).ProjectResults[0]
+
+	if got.Succeeded || got.Attempts != 3 || *calls != 3 {
+		t.Errorf("Succeeded = %v, Attempts = %d, plan calls = %d, want failure after 3 attempts",
+			got.Succeeded, got.Attempts, *calls)
+	}
+}
+
+func TestDetectDriftDoesNotRetryPermanentFailure(t *testing.T) {
+	retry := repo.DriftiveRepoConfigRetry{Attempts: 3, Backoff: time.Millisecond}
+	d, calls := newFlakyTestDetector(retry, "Error: Unsupported argument", 1)
+
+	got := d.DetectDrift(context.Background()).ProjectResults[0]
+
+	if got.Succeeded || got.Attempts != 1 || *calls != 1 {
+		t.Errorf("Succeeded = %v, Attempts = %d, plan calls = %d, want a single failed attempt",
+			got.Succeeded, got.Attempts, *calls)
+	}
+}
+
+func TestDetectDriftRetriesAreOffByDefault(t *testing.T) {
+	d, calls := newFlakyTestDetector(repo.DriftiveRepoConfigRetry{}, "Error: Error acquiring the state lock", 1)
+
+	got := d.DetectDrift(context.Background()).ProjectResults[0]
+
+	if got.Succeeded || got.Attempts != 1 || *calls != 1 {
+		t.Errorf("Succeeded = %v, Attempts = %d, plan calls = %d, want no retry",
+			got.Succeeded, got.Attempts, *calls)
+	}
+}
+
+func TestRetryPolicyPatterns(t *testing.T) {
+	defaults := newRetryPolicy(repo.DriftiveRepoConfigRetry{Attempts: 2})
+	custom := newRetryPolicy(repo.DriftiveRepoConfigRetry{Attempts: 2, Patterns: []string{`(?i)quota exceeded`}})
+	failed := func(output string) DriftProjectResult {
+		return DriftProjectResult{FailedPhase: PhaseInit, InitOutput: output}
+	}
+
+	tests := []struct {
+		name   string
+		policy retryPolicy
+		result DriftProjectResult
+		want   bool
+	}{
+		{"registry timeout", defaults, failed("Error: Failed to query available provider packages"), true},
+		{"throttled API", defaults, failed("api error Throttling: Rate exceeded, StatusCode: 429"), true},
+		{"config error", defaults, failed("Error: Invalid reference"), false},
+		{"custom pattern", custom, failed("Error: Quota exceeded for project"), true},
+		{"custom replaces defaults", custom, failed("TLS handshake timeout"), false},
+		{"succeeded", defaults, DriftProjectResult{Succeeded: true, PlanOutput: "TLS handshake timeout"}, false},
+		{"timeout", defaults, DriftProjectResult{FailureReason: ReasonTimeout, PlanOutput: "i/o timeout"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.retryable(tt.result); got != tt.want {
+				t.Errorf("retryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelayIsCapped(t *testing.T) {
+	policy := newRetryPolicy(repo.DriftiveRepoConfigRetry{Attempts: 20, Backoff: 10 * time.Second})
+	for retry, want := range map[int]time.Duration{
+		1:  10 * time.Second,
+		2:  20 * time.Second,
+		5:  160 * time.Second,
+		6:  maxRetryBackoff,
+		19: maxRetryBackoff,
+	} {
+		if got := policy.delay(retry); got != want {
+			t.Errorf("delay(%d) = %s, want %s", retry, got, want)
+		}
+	}
+
+	long := newRetryPolicy(repo.DriftiveRepoConfigRetry{Attempts: 3, Backoff: 10 * time.Minute})
+	if got := long.delay(2); got != 10*time.Minute {
+		t.Errorf("delay(2) = %s, want the configured 10m backoff", got)
+	}
+}
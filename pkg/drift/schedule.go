@@ -0,0 +1,162 @@
+package drift
+
+import (
+	"driftive/pkg/models"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+// schedule hands out projects in dependency order: a project becomes ready once every project it
+// depends on has finished. Dependencies on dirs that are not part of the run are ignored, and a
+// dependency cycle is broken rather than stalling the run.
+type schedule struct {
+	projects   []models.TypedProject
+	dependents [][]int
+	// waitingOn counts each project's unfinished dependencies.
+	waitingOn []int
+	state     []projectState
+	// ready holds the indices of ready projects; the lowest is handed out first so runs without
+	// dependencies keep the project order.
+	ready     []int
+	inFlight  int
+	remaining int
+}
+
+type projectState int
+
+const (
+	stateWaiting projectState = iota
+	stateReady
+	stateDispatched
+	stateFinished
+)
+
+// blockedProject is a project that will not run because upstream, a project it depends on
+// directly or transitively, failed.
+type blockedProject struct {
+	index    int
+	upstream int
+}
+
+func newSchedule(projects []models.TypedProject) *schedule {
+	byDir := make(map[string][]int, len(projects))
+	for i, p := range projects {
+		dir := filepath.Clean(p.Dir)
+		byDir[dir] = append(byDir[dir], i)
+	}
+
+	s := &schedule{
+		projects:   projects,
+		dependents: make([][]int, len(projects)),
+		waitingOn:  make([]int, len(projects)),
+		state:      make([]projectState, len(projects)),
+		remaining:  len(projects),
+	}
+	for i, p := range projects {
+		seen := map[int]bool{}
+		for _, dep := range p.Settings.DependsOn {
+			for _, j := range byDir[filepath.Clean(dep)] {
+				// Workspaces of one dir share it, but do not depend on each other.
+				if filepath.Clean(projects[j].Dir) == filepath.Clean(p.Dir) || seen[j] {
+					continue
+				}
+				seen[j] = true
+				s.dependents[j] = append(s.dependents[j], i)
+				s.waitingOn[i]++
+			}
+		}
+	}
+	for i := range projects {
+		if s.waitingOn[i] == 0 {
+			s.markReady(i)
+		}
+	}
+	return s
+}
+
+func (s *schedule) markReady(i int) {
+	s.state[i] = stateReady
+	s.ready = append(s.ready, i)
+}
+
+// next returns the next project to run. ok is false when none is ready yet: either projects
+// in flight must finish first, or every project was handed out.
+func (s *schedule) next() (int, bool) {
+	if len(s.ready) == 0 && s.inFlight == 0 && s.remaining > 0 {
+		// Nothing runs and nothing is ready, yet projects are left: they wait on each other.
+		for i, state := range s.state {
+			if state == stateWaiting {
+				log.Warn().Msgf("Dependency cycle detected; running %s without waiting for its dependencies", s.projects[i].Key())
+				s.markReady(i)
+				break
+			}
+		}
+	}
+	if len(s.ready) == 0 {
+		return 0, false
+	}
+
+	lowest := 0
+	for k, i := range s.ready {
+		if i < s.ready[lowest] {
+			lowest = k
+		}
+	}
+	i := s.ready[lowest]
+	s.ready = append(s.ready[:lowest], s.ready[lowest+1:]...)
+	s.state[i] = stateDispatched
+	s.inFlight++
+	return i, true
+}
+
+// finish records that project i completed. When it failed, every project depending on it,
+// directly or transitively, is blocked and returned; they count as finished.
+func (s *schedule) finish(i int, failed bool) []blockedProject {
+	s.state[i] = stateFinished
+	s.inFlight--
+	s.remaining--
+
+	if !failed {
+		for _, dependent := range s.dependents[i] {
+			s.waitingOn[dependent]--
+			if s.waitingOn[dependent] == 0 && s.state[dependent] == stateWaiting {
+				s.markReady(dependent)
+			}
+		}
+		return nil
+	}
+
+	var blocked []blockedProject
+	queue := append([]int{}, s.dependents[i]...)
+	for len(queue) > 0 {
+		dependent := queue[0]
+		queue = queue[1:]
+		// A dependent already handed out in a broken cycle runs regardless.
+		if s.state[dependent] != stateWaiting && s.state[dependent] != stateReady {
+			continue
+		}
+		if s.state[dependent] == stateReady {
+			s.removeReady(dependent)
+		}
+		s.state[dependent] = stateFinished
+		s.remaining--
+		blocked = append(blocked, blockedProject{index: dependent, upstream: i})
+		queue = append(queue, s.dependents[dependent]...)
+	}
+	return blocked
+}
+
+func (s *schedule) removeReady(i int) {
+	for k, r := range s.ready {
+		if r == i {
+			s.ready = append(s.ready[:k], s.ready[k+1:]...)
+			return
+		}
+	}
+}
+
+// done reports whether every project has finished or been blocked.
+func (s *schedule) done() bool {
+	return s.remaining == 0
+}
@@ -0,0 +1,135 @@
+package drift
+
+import (
+	"context"
+	"driftive/pkg/exec"
+	"driftive/pkg/models"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// newDependencyTestDetector runs projects with up to 4 workers; the projects in failing fail
+// their plan.
+func newDependencyTestDetector(projects []models.TypedProject, failing ...string) (*DriftDetector, *[]string) {
+	d, _ := newTestDetector(".", projects, noDriftPlan)
+	d.semaphore = make(chan struct{}, 4)
+	var mu sync.Mutex
+	initDirs := make([]string, 0)
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		e := fakeExecutor{dir: dir, plan: noDriftPlan, mu: &mu, initDirs: &initDirs}
+		if slices.Contains(failing, dir) {
+			e.planErr = errors.New("exit status 1")
+			e.planOutput = "Error: reading VPC"
+		}
+		return e
+	}
+	return d, &initDirs
+}
+
+func dependent(dir string, deps ...string) models.TypedProject {
+	return models.TypedProject{Dir: dir, Type: models.Terragrunt, Settings: models.ProjectSettings{DependsOn: deps}}
+}
+
+func resultsByDir(result DriftDetectionResult) map[string]DriftProjectResult {
+	byDir := make(map[string]DriftProjectResult, len(result.ProjectResults))
+	for _, r := range result.ProjectResults {
+		byDir[r.Project.Dir] = r
+	}
+	return byDir
+}
+
+func TestDetectDriftRunsDependenciesFirst(t *testing.T) {
+	projects := []models.TypedProject{
+		dependent("live/app", "live/db", "live/vpc"),
+		dependent("live/db", "live/vpc"),
+		dependent("live/vpc"),
+	}
+	d, initDirs := newDependencyTestDetector(projects)
+
+	result := d.DetectDrift(context.Background())
+
+	if want := []string{"live/vpc", "live/db", "live/app"}; !slices.Equal(*initDirs, want) {
+		t.Errorf("projects ran in order %v, want %v", *initDirs, want)
+	}
+	if len(result.ProjectResults) != 3 || result.TotalChecked != 3 {
+		t.Errorf("got %d results, %d checked, want 3", len(result.ProjectResults), result.TotalChecked)
+	}
+}
+
+func TestDetectDriftBlocksDependentsOfFailedProject(t *testing.T) {
+	projects := []models.TypedProject{
+		dependent("live/app", "live/vpc"),
+		dependent("live/other"),
+		dependent("live/vpc"),
+		dependent("live/web", "live/app"),
+	}
+	d, initDirs := newDependencyTestDetector(projects, "live/vpc")
+	var done []string
+	d.OnProjectDone = func(r DriftProjectResult) { done = append(done, r.Project.Key()) }
+
+	result := d.DetectDrift(context.Background())
+
+	byDir := resultsByDir(result)
+	if len(byDir) != 4 || len(done) != 4 {
+		t.Fatalf("got results %v and %d done callbacks, want all 4 projects", byDir, len(done))
+	}
+	if r := byDir["live/vpc"]; r.Succeeded || r.FailureReason != "" {
+		t.Errorf("live/vpc: Succeeded = %v, FailureReason = %q, want a plain failure", r.Succeeded, r.FailureReason)
+	}
+	for _, dir := range []string{"live/app", "live/web"} {
+		r := byDir[dir]
+		if r.Succeeded || r.FailureReason != ReasonBlocked || r.BlockedBy != "live/vpc" {
+			t.Errorf("%s: Succeeded = %v, FailureReason = %q, BlockedBy = %q, want blocked by live/vpc",
+				dir, r.Succeeded, r.FailureReason, r.BlockedBy)
+		}
+		if slices.Contains(*initDirs, dir) {
+			t.Errorf("%s should not have run", dir)
+		}
+	}
+	if r := byDir["live/other"]; !r.Succeeded {
+		t.Errorf("live/other should run regardless of live/vpc")
+	}
+}
+
+func TestDetectDriftIgnoresDependenciesOutsideTheRun(t *testing.T) {
+	d, _ := newDependencyTestDetector([]models.TypedProject{dependent("live/app", "live/excluded")})
+
+	result := d.DetectDrift(context.Background())
+
+	if len(result.ProjectResults) != 1 || !result.ProjectResults[0].Succeeded {
+		t.Errorf("expected live/app to run, got %+v", result.ProjectResults)
+	}
+}
+
+func TestDetectDriftBreaksDependencyCycles(t *testing.T) {
+	projects := []models.TypedProject{
+		dependent("live/a", "live/b"),
+		dependent("live/b", "live/a"),
+	}
+	d, initDirs := newDependencyTestDetector(projects)
+
+	result := d.DetectDrift(context.Background())
+
+	if len(result.ProjectResults) != 2 || len(*initDirs) != 2 {
+		t.Errorf("expected both projects of the cycle to run, got %v", *initDirs)
+	}
+}
+
+func TestScheduleKeepsProjectOrderWithoutDependencies(t *testing.T) {
+	s := newSchedule([]models.TypedProject{{Dir: "a"}, {Dir: "b"}, {Dir: "c"}})
+
+	var order []int
+	for {
+		i, ok := s.next()
+		if !ok {
+			break
+		}
+		order = append(order, i)
+	}
+
+	if !slices.Equal(order, []int{0, 1, 2}) {
+		t.Errorf("order = %v, want the project order", order)
+	}
+}
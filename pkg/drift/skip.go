@@ -1,6 +1,8 @@
 package drift
 
 import (
+	"driftive/pkg/models"
+	"github.com/moby/patternmatcher"
 	"github.com/rs/zerolog/log"
 	"os"
 	"path/filepath"
@@ -19,6 +21,31 @@ func removeTrailingSlash(path string) string {
 	return path
 }
 
+// changesProject reports whether changing file affects the project: the file is in the project
+// dir, or matches one of its when_modified globs.
+func changesProject(project models.TypedProject, file string) bool {
+	fileFolder := removeTrailingSlash(getFolder(file))
+	projectFolder := removeTrailingSlash(project.Dir)
+	log.Debug().Msgf("Comparing file folder %s with project folder %s", fileFolder, projectFolder)
+	if fileFolder == projectFolder {
+		return true
+	}
+	if len(project.Settings.WhenModified) == 0 {
+		return false
+	}
+	pm, err := patternmatcher.New(project.Settings.WhenModified)
+	if err != nil {
+		log.Warn().Msgf("Invalid when_modified pattern for project %s: %v", project.Dir, err)
+		return false
+	}
+	rel, err := filepath.Rel(projectFolder, file)
+	if err != nil {
+		return false
+	}
+	match, err := pm.MatchesOrParentMatches(rel)
+	return err == nil && match
+}
+
 func (d *DriftDetector) handleSkipIfContainsPRChanges(analysisResult *DriftDetectionResult) {
 	log.Debug().Msgf("Handling skip if contains PR changes")
 	log.Debug().Msgf("RepoPath: %s", d.Config.RepositoryPath)
@@ -31,10 +58,7 @@ func (d *DriftDetector) handleSkipIfContainsPRChanges(analysisResult *DriftDetec
 				}
 				for _, file := range d.Stash.OpenPRChangedFiles {
 					log.Debug().Msgf("Checking project %s for file %s", projectResult.Project.Dir, file)
-					fileFolder := removeTrailingSlash(getFolder(file))
-					projectFolder := removeTrailingSlash(projectResult.Project.Dir)
-					log.Debug().Msgf("Comparing file folder %s with project folder %s", fileFolder, projectFolder)
-					if fileFolder == projectFolder {
+					if changesProject(projectResult.Project, file) {
 						projectResult.SkippedDueToPR = true
 						analysisResult.TotalDrifted--
 						analysisResult.TotalSkipped++
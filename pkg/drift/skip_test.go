@@ -112,3 +112,23 @@ func TestRemoveTrailingSlash(t *testing.T) {
 		t.Errorf("Expected '/home/user/repo_dir' but got '%s'", result)
 	}
 }
+
+func TestChangesProjectMatchesWhenModified(t *testing.T) {
+	project := models.TypedProject{Dir: "stacks/app", Settings: models.ProjectSettings{
+		WhenModified: []string{"*.tf", "../../modules/**/*.tf"},
+	}}
+
+	cases := map[string]bool{
+		"stacks/app/main.tf":            true,
+		"stacks/app/README.md":          true,
+		"modules/vpc/main.tf":           true,
+		"modules/vpc/README.md":         false,
+		"stacks/other/main.tf":          false,
+		"stacks/app/nested/variable.tf": false,
+	}
+	for file, want := range cases {
+		if got := changesProject(project, file); got != want {
+			t.Errorf("changesProject(%q) = %v, want %v", file, got, want)
+		}
+	}
+}
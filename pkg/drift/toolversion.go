@@ -0,0 +1,101 @@
+package drift
+
+import (
+	"driftive/pkg/exec"
+	"driftive/pkg/models"
+	"driftive/pkg/tfversion"
+	"fmt"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+// projectExecutor builds the project's executor. With settings.binaries_dir set, the executor
+// runs the installed terraform or tofu version the project asks for; version is that version,
+// empty when the binary on PATH is used.
+func (d *DriftDetector) projectExecutor(project models.TypedProject) (exec.Executor, string, error) {
+	opts := d.executorOptions(project)
+	binary, version, err := d.resolveBinary(project)
+	if err != nil {
+		return nil, "", err
+	}
+	opts.Binary = binary
+	return d.newExecutor(project.Dir, project.Type, opts), version, nil
+}
+
+// resolveBinary picks the highest installed version satisfying the project's version files or
+// required_version. A project that pins nothing runs the binary on PATH; one whose version is
+// not installed fails rather than running an arbitrary version. Pulumi projects always run the
+// pulumi on PATH.
+func (d *DriftDetector) resolveBinary(project models.TypedProject) (string, string, error) {
+	if d.RepoConfig.Settings.BinariesDir == "" || project.Type == models.Pulumi {
+		return "", "", nil
+	}
+	binariesDir, err := filepath.Abs(d.RepoConfig.Settings.BinariesDir)
+	if err != nil {
+		return "", "", err
+	}
+	dir, err := filepath.Abs(project.Dir)
+	if err != nil {
+		return "", "", err
+	}
+	root, err := filepath.Abs(d.RepoDir)
+	if err != nil {
+		return "", "", err
+	}
+
+	req, found, err := projectRequirement(project, dir, root)
+	if err != nil {
+		return "", "", fmt.Errorf("reading the required version: %w", err)
+	}
+	if !found {
+		return "", "", nil
+	}
+	source := "the project config"
+	if req.Source != "" {
+		source = relativeProjectDir(root, req.Source)
+	}
+
+	installed, err := tfversion.Installed(binariesDir, req.Tool)
+	if err != nil {
+		return "", "", fmt.Errorf("listing installed %s versions: %w", req.Tool, err)
+	}
+	selected, ok := tfversion.Select(installed, req.Constraint)
+	if !ok {
+		constraint := req.Constraint.String()
+		if constraint == "" {
+			constraint = "latest"
+		}
+		return "", "", fmt.Errorf("no %s version installed in %s matches %s (from %s)",
+			req.Tool, binariesDir, constraint, source)
+	}
+	log.Debug().Msgf("Using %s %s for %s (from %s)", req.Tool, selected.Version, project.Dir, source)
+	return selected.Path, selected.Version.String(), nil
+}
+
+// projectRequirement is the version the project's config sets, or else the one its version
+// files or required_version ask for. A requirement from the config has no Source.
+func projectRequirement(project models.TypedProject, dir, root string) (tfversion.Requirement, bool, error) {
+	tools := projectTools(project.Type)
+	if project.Settings.ToolVersion == "" {
+		return tfversion.Find(dir, root, tools...)
+	}
+	constraint, err := tfversion.ParseConstraint(project.Settings.ToolVersion)
+	if err != nil {
+		return tfversion.Requirement{}, false, err
+	}
+	return tfversion.Requirement{Tool: tools[0], Constraint: constraint}, true, nil
+}
+
+// projectTools are the tools whose version a project may pin, preferred first. Terragrunt
+// drives terraform or tofu, whichever the project's version files name.
+func projectTools(t models.ProjectType) []tfversion.Tool {
+	switch t {
+	case models.Tofu:
+		return []tfversion.Tool{tfversion.Tofu}
+	case models.Terragrunt:
+		return []tfversion.Tool{tfversion.Terraform, tfversion.Tofu}
+	default:
+		return []tfversion.Tool{tfversion.Terraform}
+	}
+}
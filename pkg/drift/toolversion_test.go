@@ -0,0 +1,109 @@
+package drift
+
+import (
+	"context"
+	"driftive/pkg/exec"
+	"driftive/pkg/models"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newVersionedRepo lays out a repository with one terraform project pinned by
+// .terraform-version, and a binaries dir with terraform 1.3.9 and 1.9.8 installed.
+func newVersionedRepo(t *testing.T, pinned string) (repoDir, projectDir, binariesDir string) {
+	t.Helper()
+	base := t.TempDir()
+	repoDir = filepath.Join(base, "repo")
+	projectDir = filepath.Join(repoDir, "stacks", "app")
+	binariesDir = filepath.Join(base, "binaries")
+
+	binary := "terraform"
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	for _, version := range []string{"1.3.9", "1.9.8"} {
+		dir := filepath.Join(binariesDir, "terraform", version)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, binary), nil, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, ".terraform-version"), []byte(pinned), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return repoDir, projectDir, binariesDir
+}
+
+func TestDetectDriftRunsThePinnedVersion(t *testing.T) {
+	repoDir, projectDir, binariesDir := newVersionedRepo(t, "1.3.9\n")
+	d, _ := newTestDetector(repoDir, []models.TypedProject{{Dir: projectDir, Type: models.Terraform}}, noDriftPlan)
+	d.RepoConfig.Settings.BinariesDir = binariesDir
+	var binary string
+	d.newExecutor = func(dir string, _ models.ProjectType, opts exec.Options) exec.Executor {
+		binary = opts.Binary
+		return fakeExecutor{dir: dir, plan: noDriftPlan, mu: &sync.Mutex{}, initDirs: &[]string{}}
+	}
+
+	got := d.DetectDrift(context.Background()).ProjectResults[0]
+
+	if !got.Succeeded || got.ToolVersion != "1.3.9" {
+		t.Errorf("Succeeded = %v, ToolVersion = %q, want success with 1.3.9", got.Succeeded, got.ToolVersion)
+	}
+	if want := filepath.Join(binariesDir, "terraform", "1.3.9"); filepath.Dir(binary) != want {
+		t.Errorf("Binary = %q, want the binary under %s", binary, want)
+	}
+}
+
+func TestDetectDriftFailsWhenThePinnedVersionIsMissing(t *testing.T) {
+	repoDir, projectDir, binariesDir := newVersionedRepo(t, "1.5.7\n")
+	d, initDirs := newTestDetector(repoDir, []models.TypedProject{{Dir: projectDir, Type: models.Terraform}}, noDriftPlan)
+	d.RepoConfig.Settings.BinariesDir = binariesDir
+
+	got := d.DetectDrift(context.Background()).ProjectResults[0]
+
+	if got.Succeeded || got.FailedPhase != PhaseInit {
+		t.Fatalf("Succeeded = %v, FailedPhase = %q, want an init failure", got.Succeeded, got.FailedPhase)
+	}
+	if !strings.Contains(got.InitOutput, "no terraform version installed") || !strings.Contains(got.InitOutput, "1.5.7") {
+		t.Errorf("InitOutput = %q, want the missing version explained", got.InitOutput)
+	}
+	if len(*initDirs) != 0 {
+		t.Errorf("init ran in %v, want no init with an arbitrary version", *initDirs)
+	}
+}
+
+func TestDetectDriftUsesPathWithoutBinariesDir(t *testing.T) {
+	repoDir, projectDir, _ := newVersionedRepo(t, "1.5.7\n")
+	d, _ := newTestDetector(repoDir, []models.TypedProject{{Dir: projectDir, Type: models.Terraform}}, noDriftPlan)
+
+	got := d.DetectDrift(context.Background()).ProjectResults[0]
+
+	if !got.Succeeded || got.ToolVersion != "" {
+		t.Errorf("Succeeded = %v, ToolVersion = %q, want the PATH binary", got.Succeeded, got.ToolVersion)
+	}
+}
+
+func TestConfiguredToolVersionWinsOverVersionFiles(t *testing.T) {
+	repoDir, projectDir, binariesDir := newVersionedRepo(t, "1.3.9\n")
+	project := models.TypedProject{Dir: projectDir, Type: models.Terraform, Settings: models.ProjectSettings{ToolVersion: "v1.9.8"}}
+	d, _ := newTestDetector(repoDir, []models.TypedProject{project}, noDriftPlan)
+	d.RepoConfig.Settings.BinariesDir = binariesDir
+
+	if _, version, err := d.resolveBinary(project); err != nil || version != "1.9.8" {
+		t.Errorf("resolveBinary() = %q, %v, want the configured 1.9.8", version, err)
+	}
+
+	project.Settings.ToolVersion = "1.5.7"
+	if _, _, err := d.resolveBinary(project); err == nil || !strings.Contains(err.Error(), "(from the project config)") {
+		t.Errorf("resolveBinary() error = %v, want the missing configured version explained", err)
+	}
+}
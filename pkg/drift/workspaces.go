@@ -0,0 +1,137 @@
+package drift
+
+import (
+	"context"
+	"driftive/pkg/models"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// workspaceError is a failed workspace listing, reported by DetectDrift as an init failure.
+type workspaceError struct {
+	// output explains the failure.
+	output string
+	// reason is ReasonTimeout when the listing ran out of time, empty otherwise.
+	reason string
+}
+
+// workspaceListing is the outcome of listing one project's workspaces.
+type workspaceListing struct {
+	workspaces []string
+	failure    *workspaceError
+}
+
+// ExpandWorkspaces replaces each project configured with `workspaces: all` by one project per
+// workspace its backend lists. Listing needs an initialized backend, so each such project is
+// initialized first. Listings run concurrently up to the configured concurrency, each bounded by
+// the project's timeout. A project whose workspaces cannot be listed is kept as-is and reported
+// as an init failure by DetectDrift.
+//
+// DetectDrift expands on its own; callers that need the final project count before the run
+// starts call it first. Calling it again is a no-op.
+func (d *DriftDetector) ExpandWorkspaces(ctx context.Context) {
+	if d.workspacesExpanded {
+		return
+	}
+	d.workspacesExpanded = true
+
+	listings := make([]workspaceListing, len(d.Projects))
+	var wg sync.WaitGroup
+	for i, project := range d.Projects {
+		if !project.Settings.AllWorkspaces {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.semaphore <- struct{}{}
+			defer func() {
+				<-d.semaphore
+			}()
+			listings[i] = d.listWorkspacesWithTimeout(ctx, project)
+		}()
+	}
+	wg.Wait()
+
+	projects := make([]models.TypedProject, 0, len(d.Projects))
+	for i, project := range d.Projects {
+		if !project.Settings.AllWorkspaces {
+			projects = append(projects, project)
+			continue
+		}
+
+		listing := listings[i]
+		if listing.failure != nil {
+			d.workspaceErrors[project.Dir] = *listing.failure
+			projects = append(projects, project)
+			continue
+		}
+		log.Info().Msgf("Planning %d workspace(s) of %s: %v", len(listing.workspaces), project.Dir, listing.workspaces)
+		for _, workspace := range listing.workspaces {
+			expanded := project
+			expanded.Workspace = workspace
+			expanded.Settings.AllWorkspaces = false
+			projects = append(projects, expanded)
+		}
+	}
+	d.Projects = projects
+}
+
+// listWorkspacesWithTimeout lists the project's workspaces, bounded by the project's timeout
+// like its analysis. The timeout starts once the project holds the init slot.
+func (d *DriftDetector) listWorkspacesWithTimeout(ctx context.Context, project models.TypedProject) workspaceListing {
+	releaseInit, err := d.acquireInitSlot(ctx)
+	if err != nil {
+		log.Error().Msgf("Failed to list workspaces of %s: %v", project.Dir, err)
+		return workspaceListing{failure: &workspaceError{output: err.Error()}}
+	}
+	defer releaseInit()
+
+	listCtx := ctx
+	timeout := d.projectTimeout(project)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		listCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	workspaces, output, err := d.listWorkspaces(listCtx, project, releaseInit)
+	if err == nil {
+		return workspaceListing{workspaces: workspaces}
+	}
+	// Only the project's own deadline counts: a cancelled run is not a timeout.
+	if timeout > 0 && ctx.Err() == nil && errors.Is(listCtx.Err(), context.DeadlineExceeded) {
+		log.Warn().Msgf("Listing workspaces of %s timed out after %s", project.Dir, timeout)
+		note := fmt.Sprintf("Timed out after %s while listing workspaces.", timeout)
+		return workspaceListing{failure: &workspaceError{output: strings.TrimSpace(output + "\n\n" + note),
+			reason: ReasonTimeout}}
+	}
+	log.Error().Msgf("Failed to list workspaces of %s: %v", project.Dir, err)
+	return workspaceListing{failure: &workspaceError{output: output}}
+}
+
+// listWorkspaces initializes the project, calling releaseInit once init is done, and lists its
+// workspaces. On error, output explains the failure.
+func (d *DriftDetector) listWorkspaces(ctx context.Context, project models.TypedProject, releaseInit func()) ([]string, string, error) {
+	executor, _, err := d.projectExecutor(project)
+	if err != nil {
+		return nil, err.Error(), err
+	}
+	output, err := d.runInit(ctx, executor, project, releaseInit)
+	if err != nil {
+		return nil, orErrorText(output, err), err
+	}
+	workspaces, err := executor.Workspaces(ctx)
+	if err != nil {
+		return nil, err.Error(), err
+	}
+	if len(workspaces) == 0 {
+		// Every backend has at least the default workspace; plan that rather than nothing.
+		return []string{""}, "", nil
+	}
+	return workspaces, "", nil
+}
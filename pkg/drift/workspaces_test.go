@@ -0,0 +1,164 @@
+package drift
+
+import (
+	"context"
+	"driftive/pkg/exec"
+	"driftive/pkg/models"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDetectDriftPlansEveryWorkspace(t *testing.T) {
+	projects := []models.TypedProject{
+		{Dir: "infra/app", Type: models.Terraform, Settings: models.ProjectSettings{AllWorkspaces: true}},
+		{Dir: "infra/dns", Type: models.Terraform},
+	}
+	d := newFailingTestDetector(projects, fakeExecutor{workspaces: []string{"default", "prod", "staging"}})
+
+	result := d.DetectDrift(context.Background())
+
+	keys := make([]string, 0, len(result.ProjectResults))
+	for _, r := range result.ProjectResults {
+		keys = append(keys, r.Project.Key())
+	}
+	sort.Strings(keys)
+	want := []string{"infra/app@default", "infra/app@prod", "infra/app@staging", "infra/dns"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("result keys = %v, want %v", keys, want)
+	}
+	if result.TotalProjects != 4 {
+		t.Errorf("TotalProjects = %d, want 4", result.TotalProjects)
+	}
+}
+
+func TestExpandWorkspacesIsIdempotent(t *testing.T) {
+	projects := []models.TypedProject{
+		{Dir: "infra/app", Type: models.Terraform, Settings: models.ProjectSettings{AllWorkspaces: true}},
+	}
+	d := newFailingTestDetector(projects, fakeExecutor{workspaces: []string{"prod", "staging"}})
+
+	d.ExpandWorkspaces(context.Background())
+	d.ExpandWorkspaces(context.Background())
+
+	if len(d.Projects) != 2 {
+		t.Errorf("expected 2 projects after expanding twice, got %d", len(d.Projects))
+	}
+}
+
+func TestDetectDriftReportsWorkspaceListingFailure(t *testing.T) {
+	projects := []models.TypedProject{
+		{Dir: "infra/app", Type: models.Terraform, Settings: models.ProjectSettings{AllWorkspaces: true}},
+	}
+	d := newFailingTestDetector(projects, fakeExecutor{workspacesErr: errors.New("backend not initialized")})
+
+	result := d.DetectDrift(context.Background())
+
+	if len(result.ProjectResults) != 1 {
+		t.Fatalf("expected the project to be reported once, got %d results", len(result.ProjectResults))
+	}
+	got := result.ProjectResults[0]
+	if got.Succeeded || got.FailedPhase != PhaseInit {
+		t.Errorf("Succeeded = %v, FailedPhase = %q, want an init failure", got.Succeeded, got.FailedPhase)
+	}
+	if got.InitOutput != "backend not initialized" {
+		t.Errorf("InitOutput = %q, want the listing error", got.InitOutput)
+	}
+}
+
+func TestDetectDriftReportsProgressByWorkspaceKey(t *testing.T) {
+	projects := []models.TypedProject{{Dir: "infra/app", Type: models.Terraform, Workspace: "prod"}}
+	d, _ := newTestDetector(".", projects, nil)
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return fakeExecutor{dir: dir, mu: &sync.Mutex{}, initDirs: &[]string{}}
+	}
+	var started []string
+	d.OnProjectStart = func(key string) { started = append(started, key) }
+
+	result := d.DetectDrift(context.Background())
+
+	if !slices.Equal(started, []string{"infra/app@prod"}) {
+		t.Errorf("OnProjectStart keys = %v, want [infra/app@prod]", started)
+	}
+	if got := result.ProjectResults[0].Project.Key(); got != "infra/app@prod" {
+		t.Errorf("result key = %q, want infra/app@prod", got)
+	}
+}
+
+// blockingWorkspacesExecutor lists workspaces only once the barrier's listings are all running,
+// or hangs until its context is done when there is no barrier.
+type blockingWorkspacesExecutor struct {
+	fakeExecutor
+	barrier *sync.WaitGroup
+}
+
+func (b blockingWorkspacesExecutor) Workspaces(ctx context.Context) ([]string, error) {
+	if b.barrier == nil {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	b.barrier.Done()
+	arrived := make(chan struct{})
+	go func() {
+		b.barrier.Wait()
+		close(arrived)
+	}()
+	select {
+	case <-arrived:
+		return []string{"prod"}, nil
+	case <-time.After(5 * time.Second):
+		return nil, errors.New("listings did not run concurrently")
+	}
+}
+
+func TestExpandWorkspacesListsConcurrently(t *testing.T) {
+	projects := []models.TypedProject{
+		{Dir: "infra/app", Type: models.Terraform, Settings: models.ProjectSettings{AllWorkspaces: true}},
+		{Dir: "infra/dns", Type: models.Terraform, Settings: models.ProjectSettings{AllWorkspaces: true}},
+	}
+	d, _ := newTestDetector(".", projects, nil)
+	d.semaphore = make(chan struct{}, len(projects))
+	barrier := &sync.WaitGroup{}
+	barrier.Add(len(projects))
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return blockingWorkspacesExecutor{fakeExecutor: fakeExecutor{dir: dir, mu: &sync.Mutex{}, initDirs: &[]string{}},
+			barrier: barrier}
+	}
+
+	d.ExpandWorkspaces(context.Background())
+
+	keys := make([]string, 0, len(d.Projects))
+	for _, p := range d.Projects {
+		keys = append(keys, p.Key())
+	}
+	if want := []string{"infra/app@prod", "infra/dns@prod"}; !slices.Equal(keys, want) {
+		t.Errorf("projects = %v, want %v; listing errors: %v", keys, want, d.workspaceErrors)
+	}
+}
+
+func TestDetectDriftReportsWorkspaceListingTimeout(t *testing.T) {
+	projects := []models.TypedProject{{Dir: "infra/app", Type: models.Terraform,
+		Settings: models.ProjectSettings{AllWorkspaces: true, Timeout: 20 * time.Millisecond}}}
+	d, _ := newTestDetector(".", projects, nil)
+	d.newExecutor = func(dir string, _ models.ProjectType, _ exec.Options) exec.Executor {
+		return blockingWorkspacesExecutor{fakeExecutor: fakeExecutor{dir: dir, mu: &sync.Mutex{}, initDirs: &[]string{}}}
+	}
+
+	result := d.DetectDrift(context.Background())
+
+	if len(result.ProjectResults) != 1 {
+		t.Fatalf("expected the project to be reported once, got %d results", len(result.ProjectResults))
+	}
+	got := result.ProjectResults[0]
+	if got.Succeeded || got.FailedPhase != PhaseInit || got.FailureReason != ReasonTimeout {
+		t.Errorf("Succeeded = %v, FailedPhase = %q, FailureReason = %q, want an init timeout",
+			got.Succeeded, got.FailedPhase, got.FailureReason)
+	}
+	if !strings.Contains(got.InitOutput, "while listing workspaces") {
+		t.Errorf("InitOutput = %q, want the timeout explained", got.InitOutput)
+	}
+}
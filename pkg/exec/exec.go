@@ -1,31 +1,111 @@
 package exec
 
 import (
+	"bytes"
 	"context"
 	"driftive/pkg/models"
+	"driftive/pkg/models/plan"
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
+// PlanOutcome classifies a plan by its -detailed-exitcode.
+type PlanOutcome int
+
+const (
+	// PlanFailed is any exit code other than 0 and 2.
+	PlanFailed PlanOutcome = iota
+	// PlanNoChanges is exit code 0: the plan is empty.
+	PlanNoChanges
+	// PlanChanges is exit code 2: the plan succeeded and has a diff.
+	PlanChanges
+)
+
+func (o PlanOutcome) String() string {
+	switch o {
+	case PlanNoChanges:
+		return "no changes"
+	case PlanChanges:
+		return "changes"
+	default:
+		return "failed"
+	}
+}
+
+// PlanResult is the human-readable output of a plan and how it ended.
+type PlanResult struct {
+	Output  string
+	Outcome PlanOutcome
+}
+
 type Executor interface {
 	Dir() string
 	Init(ctx context.Context, args ...string) (string, error)
-	Plan(ctx context.Context, args ...string) (string, error)
+	// Plan runs a plan with -detailed-exitcode and saves the binary plan to planFile. The error
+	// is only set when the outcome is PlanFailed.
+	Plan(ctx context.Context, planFile string, args ...string) (PlanResult, error)
+	// Show renders a plan saved by Plan as JSON and decodes it.
+	Show(ctx context.Context, planFile string) (*plan.Plan, error)
+	// Workspaces lists the workspaces of an initialized project.
+	Workspaces(ctx context.Context) ([]string, error)
 	ParsePlan(output string) string
 	ParseErrorOutput(output string) string
 }
 
-func NewExecutor(dir string, t models.ProjectType) Executor {
+// Options tune how an executor runs its commands.
+type Options struct {
+	// Env is added to the process environment as KEY=VALUE entries, overriding inherited values.
+	Env []string
+	// Passthrough restricts the inherited environment to the named host variables, plus
+	// alwaysPassedEnv. Nil inherits the whole environment.
+	Passthrough []string
+	// Binary is the terraform or tofu binary to run instead of the one on PATH. Terragrunt still
+	// runs from PATH and is pointed at Binary with TG_TF_PATH.
+	Binary string
+	// Stack is the Pulumi stack to preview. The other tools select their workspace with
+	// TF_WORKSPACE instead.
+	Stack string
+}
+
+// bin is the binary to run for the named tool.
+func (o Options) bin(name string) string {
+	if o.Binary != "" {
+		return o.Binary
+	}
+	return name
+}
+
+// forTerragrunt moves Binary into TG_TF_PATH, so terragrunt itself still runs from PATH.
+func (o Options) forTerragrunt() Options {
+	if o.Binary == "" {
+		return o
+	}
+	o.Env = append(slices.Clone(o.Env), "TG_TF_PATH="+o.Binary)
+	o.Binary = ""
+	return o
+}
+
+// alwaysPassedEnv are host variables every command needs to find the tool and its config,
+// even when Options.Passthrough restricts the environment.
+var alwaysPassedEnv = []string{"PATH", "HOME"}
+
+func NewExecutor(dir string, t models.ProjectType, opts Options) Executor {
 	switch t {
 	case models.Terraform:
-		return TerraformExecutor{dir}
+		return TerraformExecutor{dir, opts}
 	case models.Terragrunt:
-		return TerragruntExecutor{dir}
+		return TerragruntExecutor{dir, opts.forTerragrunt()}
 	case models.Tofu:
-		return TofuExecutor{dir}
+		return TofuExecutor{dir, opts}
+	case models.Pulumi:
+		return PulumiExecutor{dir, opts}
 	default:
 		return nil
 	}
@@ -38,12 +118,11 @@ func RunCommand(ctx context.Context, name string, arg ...string) (string, error)
 	return string(out), err
 }
 
-func RunCommandInDir(ctx context.Context, dir, name string, arg ...string) (string, error) {
+// RunCommandInDir runs a command in dir with the environment opts describe, returning its
+// combined output.
+func RunCommandInDir(ctx context.Context, dir string, opts Options, name string, arg ...string) (string, error) {
 	log.Debug().Msgf("Running command in %s: %s %v", dir, name, arg)
-	cmd := exec.CommandContext(ctx, name, arg...)
-	cmd.Env = os.Environ()
-	cmd.Env = append(cmd.Env, "TG_TF_FORWARD_STDOUT=true")
-	cmd.Dir = dir
+	cmd := newCommand(ctx, dir, opts, name, arg...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		var exiterr *exec.ExitError
@@ -55,3 +134,97 @@ func RunCommandInDir(ctx context.Context, dir, name string, arg ...string) (stri
 	}
 	return string(out), err
 }
+
+// killWaitDelay bounds how long a cancelled command may hold its output open before driftive
+// stops waiting for it.
+const killWaitDelay = 10 * time.Second
+
+// newCommand builds an executor command. When ctx is done, the command is killed along with
+// every process it started.
+func newCommand(ctx context.Context, dir string, opts Options, name string, arg ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, arg...)
+	cmd.Env = commandEnv(opts)
+	cmd.Dir = dir
+	killProcessGroupOnCancel(cmd)
+	cmd.WaitDelay = killWaitDelay
+	return cmd
+}
+
+// commandEnv is the environment of every executor command. Later entries win, so opts.Env
+// overrides both the inherited environment and driftive's own defaults.
+func commandEnv(opts Options) []string {
+	cmdEnv := os.Environ()
+	if opts.Passthrough != nil {
+		cmdEnv = filterEnv(cmdEnv, append(slices.Clone(alwaysPassedEnv), opts.Passthrough...))
+	}
+	cmdEnv = append(cmdEnv, "TG_TF_FORWARD_STDOUT=true")
+	return append(cmdEnv, opts.Env...)
+}
+
+// filterEnv keeps the KEY=VALUE entries of env whose key is in names.
+func filterEnv(env []string, names []string) []string {
+	filtered := make([]string, 0, len(names))
+	for _, entry := range env {
+		key, _, _ := strings.Cut(entry, "=")
+		if slices.Contains(names, key) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// runStdoutInDir runs a command whose stdout is machine-readable. Stdout and stderr are kept
+// apart so log lines cannot corrupt the document; stderr is folded into the error instead.
+func runStdoutInDir(ctx context.Context, dir string, opts Options, name string, arg ...string) ([]byte, error) {
+	log.Debug().Msgf("Running command in %s: %s %v", dir, name, arg)
+	cmd := newCommand(ctx, dir, opts, name, arg...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		log.Debug().Msgf("Error running command in %s: %s %v.\nError: %s", dir, name, arg, err)
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.Bytes(), fmt.Errorf("%w: %s", err, msg)
+		}
+		return stdout.Bytes(), err
+	}
+	return stdout.Bytes(), nil
+}
+
+// planToFile runs `<bin> plan -detailed-exitcode -out=<planFile>` with the given extra args.
+func planToFile(ctx context.Context, dir string, opts Options, bin, planFile string, args ...string) (PlanResult, error) {
+	planArgs := append([]string{"plan", "-detailed-exitcode", "-out=" + planFile}, args...)
+	return classifyPlan(RunCommandInDir(ctx, dir, opts, bin, planArgs...))
+}
+
+// classifyPlan maps the exit status of a -detailed-exitcode plan to its outcome. Exit code 2
+// is the tool's own signal for a diff, not a failure.
+func classifyPlan(output string, err error) (PlanResult, error) {
+	if err == nil {
+		return PlanResult{Output: output, Outcome: PlanNoChanges}, nil
+	}
+	var exiterr *exec.ExitError
+	if errors.As(err, &exiterr) && exiterr.ExitCode() == 2 {
+		return PlanResult{Output: output, Outcome: PlanChanges}, nil
+	}
+	return PlanResult{Output: output, Outcome: PlanFailed}, err
+}
+
+// listWorkspaces runs `<bin> workspace list`. Stdout only, so wrapper log lines on stderr are
+// not mistaken for workspaces.
+func listWorkspaces(ctx context.Context, dir string, opts Options, bin string) ([]string, error) {
+	out, err := runStdoutInDir(ctx, dir, opts, bin, "workspace", "list")
+	if err != nil {
+		return nil, err
+	}
+	return parseWorkspaceList(string(out)), nil
+}
+
+// showPlan runs `<bin> show -json <planFile>` and decodes the result.
+func showPlan(ctx context.Context, dir string, opts Options, bin, planFile string) (*plan.Plan, error) {
+	out, err := runStdoutInDir(ctx, dir, opts, bin, "show", "-json", planFile)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Parse(out)
+}
@@ -0,0 +1,111 @@
+package exec
+
+import (
+	"context"
+	"driftive/pkg/models"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exitWith(t *testing.T, code string) error {
+	t.Helper()
+	return exec.CommandContext(context.Background(), "sh", "-c", "exit "+code).Run()
+}
+
+func TestClassifyPlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    PlanOutcome
+		wantErr bool
+	}{
+		{name: "exit 0 is an empty plan", err: nil, want: PlanNoChanges},
+		{name: "exit 2 is a diff", err: exitWith(t, "2"), want: PlanChanges},
+		{name: "exit 1 is a failure", err: exitWith(t, "1"), want: PlanFailed, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := classifyPlan("output", tt.err)
+			if got.Outcome != tt.want {
+				t.Errorf("Outcome = %v, want %v", got.Outcome, tt.want)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Output != "output" {
+				t.Errorf("Output = %q, want the plan output kept", got.Output)
+			}
+		})
+	}
+}
+
+func TestCommandEnvPassthroughKeepsOnlyNamedVariables(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "other-account")
+	t.Setenv("AWS_REGION", "eu-west-1")
+
+	env := commandEnv(Options{Passthrough: []string{"AWS_REGION"}, Env: []string{"AWS_PROFILE=prod"}})
+
+	if slices.ContainsFunc(env, func(e string) bool { return strings.HasPrefix(e, "AWS_ACCESS_KEY_ID=") }) {
+		t.Errorf("unlisted host variable leaked into the environment: %v", env)
+	}
+	for _, want := range []string{"AWS_REGION=eu-west-1", "AWS_PROFILE=prod", "TG_TF_FORWARD_STDOUT=true"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("expected %s in the environment, got %v", want, env)
+		}
+	}
+	if !slices.ContainsFunc(env, func(e string) bool { return strings.HasPrefix(e, "PATH=") }) {
+		t.Errorf("PATH must always be passed through, got %v", env)
+	}
+}
+
+func TestCommandEnvInheritsByDefault(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+
+	if env := commandEnv(Options{}); !slices.Contains(env, "AWS_ACCESS_KEY_ID=key") {
+		t.Errorf("expected the host environment to be inherited, got %v", env)
+	}
+}
+
+func TestNewExecutorBinary(t *testing.T) {
+	opts := Options{Env: []string{"AWS_PROFILE=prod"}, Binary: "/opt/terraform/1.3.9/terraform"}
+
+	tf := NewExecutor("stacks/app", models.Terraform, opts).(TerraformExecutor)
+	if got := tf.opts.bin("terraform"); got != opts.Binary {
+		t.Errorf("terraform runs %q, want %q", got, opts.Binary)
+	}
+
+	tg := NewExecutor("stacks/app", models.Terragrunt, opts).(TerragruntExecutor)
+	if got := tg.opts.bin("terragrunt"); got != "terragrunt" {
+		t.Errorf("terragrunt runs %q, want terragrunt from PATH", got)
+	}
+	if want := []string{"AWS_PROFILE=prod", "TG_TF_PATH=" + opts.Binary}; !slices.Equal(tg.opts.Env, want) {
+		t.Errorf("terragrunt Env = %v, want %v", tg.opts.Env, want)
+	}
+	if len(opts.Env) != 1 {
+		t.Errorf("caller's Env was modified: %v", opts.Env)
+	}
+}
+
+// TestRunCommandInDirKillsChildrenOnCancel covers a child that keeps the output pipe open: only
+// killing the process group lets the command return when its context is done.
+func TestRunCommandInDirKillsChildrenOnCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are not used on Windows")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := RunCommandInDir(ctx, t.TempDir(), Options{}, "sh", "-c", "sleep 30 & wait")
+
+	if err == nil {
+		t.Fatal("expected the cancelled command to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("command returned after %s, want it killed promptly", elapsed)
+	}
+}
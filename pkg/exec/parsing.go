@@ -59,3 +59,16 @@ func parseErrorOutput(output string) string {
 	log.Debug().Msgf("No refresh keyword found in error output. Returning full output.")
 	return output
 }
+
+// parseWorkspaceList reads the output of `workspace list`, one workspace per line with the
+// selected one marked by an asterisk.
+func parseWorkspaceList(output string) []string {
+	var workspaces []string
+	for _, line := range strings.Split(output, "\n") {
+		name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		if name != "" {
+			workspaces = append(workspaces, name)
+		}
+	}
+	return workspaces
+}
@@ -10,7 +10,7 @@ import (
 func TestErrorOutput(t *testing.T) {
 	file := utils.GetTestFile("test/output/error_planning.txt")
 	expected := utils.GetTestFile("test/output/error_planning_expected.txt")
-	tf := NewExecutor("testdata", models.Terraform)
+	tf := NewExecutor("testdata", models.Terraform, Options{})
 	result := tf.ParsePlan(string(file))
 	if result != strings.Trim(string(expected), " \n") {
 		t.Fatalf("Expected: %s\nGot: %s", string(expected), result)
@@ -20,9 +20,20 @@ func TestErrorOutput(t *testing.T) {
 func TestChangesOutput(t *testing.T) {
 	file := utils.GetTestFile("test/output/changes.txt")
 	expected := utils.GetTestFile("test/output/changes_expected.txt")
-	tf := NewExecutor("testdata", models.Terraform)
+	tf := NewExecutor("testdata", models.Terraform, Options{})
 	result := tf.ParsePlan(string(file))
 	if result != strings.Trim(string(expected), " \n") {
 		t.Fatalf("Expected: %s\nGot: %s", string(expected), result)
 	}
 }
+
+func TestParseWorkspaceList(t *testing.T) {
+	output := "  default\n* prod\n  staging\n\n"
+
+	got := parseWorkspaceList(output)
+
+	want := []string{"default", "prod", "staging"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("parseWorkspaceList() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package exec
+
+import "os/exec"
+
+// killProcessGroupOnCancel keeps the default cancellation on Windows, which kills the process
+// itself. cmd.WaitDelay still bounds the wait on children holding its output open.
+func killProcessGroupOnCancel(_ *exec.Cmd) {}
@@ -0,0 +1,138 @@
+package exec
+
+import (
+	"context"
+	"driftive/pkg/models/plan"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PulumiExecutor previews a Pulumi stack. Pulumi has no saved plans, so Plan writes the JSON
+// preview to the plan file and Show converts it into a plan.Plan.
+type PulumiExecutor struct {
+	dir  string
+	opts Options
+}
+
+func (p PulumiExecutor) Dir() string {
+	return p.dir
+}
+
+// Init installs the program's plugins and dependencies.
+func (p PulumiExecutor) Init(ctx context.Context, args ...string) (string, error) {
+	return RunCommandInDir(ctx, p.Dir(), p.opts, "pulumi", append([]string{"install"}, args...)...)
+}
+
+// Plan runs `pulumi preview --refresh --json`, so changes made outside Pulumi show up as
+// updates. The preview exits 0 whether or not it has changes; the outcome is read from its
+// steps instead.
+func (p PulumiExecutor) Plan(ctx context.Context, planFile string, args ...string) (PlanResult, error) {
+	out, err := runStdoutInDir(ctx, p.Dir(), p.opts, "pulumi", append(p.stackArgs("preview", "--refresh", "--json"), args...)...)
+	if err != nil {
+		return PlanResult{Output: pulumiErrorOutput(out), Outcome: PlanFailed}, err
+	}
+	preview, err := plan.ParsePulumiPreview(out)
+	if err != nil {
+		return PlanResult{Outcome: PlanFailed}, err
+	}
+	if err := os.WriteFile(planFile, out, 0o600); err != nil {
+		return PlanResult{Outcome: PlanFailed}, err
+	}
+
+	converted := preview.Plan()
+	outcome := PlanNoChanges
+	if converted.HasChanges() {
+		outcome = PlanChanges
+	}
+	return PlanResult{Output: renderPulumiPlan(converted), Outcome: outcome}, nil
+}
+
+func (p PulumiExecutor) Show(_ context.Context, planFile string) (*plan.Plan, error) {
+	data, err := os.ReadFile(planFile)
+	if err != nil {
+		return nil, err
+	}
+	preview, err := plan.ParsePulumiPreview(data)
+	if err != nil {
+		return nil, err
+	}
+	return preview.Plan(), nil
+}
+
+// Workspaces lists the stacks of the project.
+func (p PulumiExecutor) Workspaces(ctx context.Context) ([]string, error) {
+	out, err := runStdoutInDir(ctx, p.Dir(), p.opts, "pulumi", "stack", "ls", "--json")
+	if err != nil {
+		return nil, err
+	}
+	var stacks []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(out, &stacks); err != nil {
+		return nil, fmt.Errorf("failed to decode the stack list. %w", err)
+	}
+	names := make([]string, 0, len(stacks))
+	for _, stack := range stacks {
+		names = append(names, stack.Name)
+	}
+	return names, nil
+}
+
+// ParsePlan returns the output as is: Plan already renders only the changed resources.
+func (p PulumiExecutor) ParsePlan(output string) string {
+	return output
+}
+
+func (p PulumiExecutor) ParseErrorOutput(output string) string {
+	return output
+}
+
+// stackArgs are the command's args followed by --stack when a stack is set, plus
+// --non-interactive so a missing login fails instead of prompting.
+func (p PulumiExecutor) stackArgs(args ...string) []string {
+	args = append(args, "--non-interactive")
+	if p.opts.Stack != "" {
+		args = append(args, "--stack", p.opts.Stack)
+	}
+	return args
+}
+
+// pulumiErrorOutput is the text of a failed preview's error diagnostics. Empty when the output
+// holds none, in which case the error itself explains the failure.
+func pulumiErrorOutput(out []byte) string {
+	preview, err := plan.ParsePulumiPreview(out)
+	if err != nil {
+		return ""
+	}
+	return strings.Join(preview.Errors(), "\n")
+}
+
+// pulumiSymbols are the markers Pulumi prints in front of each kind of step.
+var pulumiSymbols = map[string]string{
+	plan.ActionCreate: "+",
+	plan.ActionUpdate: "~",
+	plan.ActionDelete: "-",
+}
+
+// renderPulumiPlan lists the resources the preview would change, one per line, e.g.
+// "~ aws:s3/bucket:Bucket::logs (update)".
+func renderPulumiPlan(p *plan.Plan) string {
+	var lines []string
+	for _, rc := range p.ResourceChanges {
+		if rc.Change.IsNoOp() {
+			continue
+		}
+		symbol, action := "+-", "replace"
+		if len(rc.Change.Actions) == 1 {
+			action = rc.Change.Actions[0]
+			symbol = pulumiSymbols[action]
+		}
+		lines = append(lines, fmt.Sprintf("%s %s (%s)", symbol, rc.Address, action))
+	}
+	if len(lines) == 0 {
+		return "No changes. The stack matches its program."
+	}
+	return "Pulumi will perform the following changes:\n" + strings.Join(lines, "\n")
+}
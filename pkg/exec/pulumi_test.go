@@ -0,0 +1,93 @@
+package exec
+
+import (
+	"context"
+	"driftive/pkg/models"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakePulumi puts a pulumi script on PATH that logs its arguments to args.txt in dir and
+// prints stdout.
+func fakePulumi(t *testing.T, dir, stdout string, exitCode int) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake pulumi is a shell script")
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args.txt") + "\ncat <<'JSON'\n" + stdout + "\nJSON\nexit " + strconv.Itoa(exitCode) + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "pulumi"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestPulumiPlanSavesPreviewForShow(t *testing.T) {
+	dir := t.TempDir()
+	fakePulumi(t, dir, `{"steps":[{"op":"update","urn":"urn:pulumi:prod::app::aws:s3/bucket:Bucket::logs"}]}`, 0)
+	executor := NewExecutor(dir, models.Pulumi, Options{Stack: "prod"})
+	planFile := filepath.Join(dir, "preview.json")
+
+	result, err := executor.Plan(context.Background(), planFile, "--parallel=4")
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if result.Outcome != PlanChanges || !strings.Contains(result.Output, "~ aws:s3/bucket:Bucket::logs (update)") {
+		t.Errorf("Plan() = %+v, want the changed bucket", result)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args.txt"))
+	if got := strings.TrimSpace(string(args)); got != "preview --refresh --json --non-interactive --stack prod --parallel=4" {
+		t.Errorf("pulumi ran with %q", got)
+	}
+
+	p, err := executor.Show(context.Background(), planFile)
+	if err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+	if len(p.ResourceChanges) != 1 || p.ResourceChanges[0].Address != "aws:s3/bucket:Bucket::logs" {
+		t.Errorf("Show() = %+v", p.ResourceChanges)
+	}
+}
+
+func TestPulumiPlanWithoutChanges(t *testing.T) {
+	dir := t.TempDir()
+	fakePulumi(t, dir, `{"steps":[{"op":"same","urn":"urn:pulumi:prod::app::pulumi:pulumi:Stack::app-prod"}]}`, 0)
+
+	result, err := NewExecutor(dir, models.Pulumi, Options{}).Plan(context.Background(), filepath.Join(dir, "preview.json"))
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if result.Outcome != PlanNoChanges {
+		t.Errorf("Outcome = %s, want no changes", result.Outcome)
+	}
+}
+
+func TestPulumiPlanFailureReportsDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	fakePulumi(t, dir, `{"steps":[],"diagnostics":[{"message":"error: no stack named 'prod' found","severity":"error"}]}`, 1)
+
+	result, err := NewExecutor(dir, models.Pulumi, Options{Stack: "prod"}).Plan(context.Background(), filepath.Join(dir, "preview.json"))
+	if err == nil {
+		t.Fatal("expected the failed preview to return an error")
+	}
+	if result.Outcome != PlanFailed || result.Output != "error: no stack named 'prod' found" {
+		t.Errorf("Plan() = %+v, want the error diagnostic", result)
+	}
+}
+
+func TestPulumiWorkspacesListsStacks(t *testing.T) {
+	dir := t.TempDir()
+	fakePulumi(t, dir, `[{"name":"dev","current":true},{"name":"prod","current":false}]`, 0)
+
+	stacks, err := NewExecutor(dir, models.Pulumi, Options{}).Workspaces(context.Background())
+	if err != nil {
+		t.Fatalf("Workspaces() error = %v", err)
+	}
+	if strings.Join(stacks, ",") != "dev,prod" {
+		t.Errorf("Workspaces() = %v, want dev and prod", stacks)
+	}
+}
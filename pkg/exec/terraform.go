@@ -1,9 +1,13 @@
 package exec
 
-import "context"
+import (
+	"context"
+	"driftive/pkg/models/plan"
+)
 
 type TerraformExecutor struct {
-	dir string
+	dir  string
+	opts Options
 }
 
 func (t TerraformExecutor) Dir() string {
@@ -11,11 +15,19 @@ func (t TerraformExecutor) Dir() string {
 }
 
 func (t TerraformExecutor) Init(ctx context.Context, args ...string) (string, error) {
-	return RunCommandInDir(ctx, t.Dir(), "terraform", append([]string{"init"}, args...)...)
+	return RunCommandInDir(ctx, t.Dir(), t.opts, t.opts.bin("terraform"), append([]string{"init"}, args...)...)
 }
 
-func (t TerraformExecutor) Plan(ctx context.Context, args ...string) (string, error) {
-	return RunCommandInDir(ctx, t.Dir(), "terraform", append([]string{"plan"}, args...)...)
+func (t TerraformExecutor) Plan(ctx context.Context, planFile string, args ...string) (PlanResult, error) {
+	return planToFile(ctx, t.Dir(), t.opts, t.opts.bin("terraform"), planFile, args...)
+}
+
+func (t TerraformExecutor) Show(ctx context.Context, planFile string) (*plan.Plan, error) {
+	return showPlan(ctx, t.Dir(), t.opts, t.opts.bin("terraform"), planFile)
+}
+
+func (t TerraformExecutor) Workspaces(ctx context.Context) ([]string, error) {
+	return listWorkspaces(ctx, t.Dir(), t.opts, t.opts.bin("terraform"))
 }
 
 func (t TerraformExecutor) ParsePlan(output string) string {
@@ -1,9 +1,13 @@
 package exec
 
-import "context"
+import (
+	"context"
+	"driftive/pkg/models/plan"
+)
 
 type TerragruntExecutor struct {
-	dir string
+	dir  string
+	opts Options
 }
 
 func (t TerragruntExecutor) Dir() string {
@@ -11,11 +15,19 @@ func (t TerragruntExecutor) Dir() string {
 }
 
 func (t TerragruntExecutor) Init(ctx context.Context, args ...string) (string, error) {
-	return RunCommandInDir(ctx, t.Dir(), "terragrunt", append([]string{"init"}, args...)...)
+	return RunCommandInDir(ctx, t.Dir(), t.opts, "terragrunt", append([]string{"init"}, args...)...)
 }
 
-func (t TerragruntExecutor) Plan(ctx context.Context, args ...string) (string, error) {
-	return RunCommandInDir(ctx, t.Dir(), "terragrunt", append([]string{"plan"}, args...)...)
+func (t TerragruntExecutor) Plan(ctx context.Context, planFile string, args ...string) (PlanResult, error) {
+	return planToFile(ctx, t.Dir(), t.opts, "terragrunt", planFile, args...)
+}
+
+func (t TerragruntExecutor) Show(ctx context.Context, planFile string) (*plan.Plan, error) {
+	return showPlan(ctx, t.Dir(), t.opts, "terragrunt", planFile)
+}
+
+func (t TerragruntExecutor) Workspaces(ctx context.Context) ([]string, error) {
+	return listWorkspaces(ctx, t.Dir(), t.opts, "terragrunt")
 }
 
 func (t TerragruntExecutor) ParsePlan(output string) string {
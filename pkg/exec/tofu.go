@@ -1,9 +1,13 @@
 package exec
 
-import "context"
+import (
+	"context"
+	"driftive/pkg/models/plan"
+)
 
 type TofuExecutor struct {
-	dir string
+	dir  string
+	opts Options
 }
 
 func (t TofuExecutor) Dir() string {
@@ -11,11 +15,19 @@ func (t TofuExecutor) Dir() string {
 }
 
 func (t TofuExecutor) Init(ctx context.Context, args ...string) (string, error) {
-	return RunCommandInDir(ctx, t.Dir(), "tofu", append([]string{"init"}, args...)...)
+	return RunCommandInDir(ctx, t.Dir(), t.opts, t.opts.bin("tofu"), append([]string{"init"}, args...)...)
 }
 
-func (t TofuExecutor) Plan(ctx context.Context, args ...string) (string, error) {
-	return RunCommandInDir(ctx, t.Dir(), "tofu", append([]string{"plan"}, args...)...)
+func (t TofuExecutor) Plan(ctx context.Context, planFile string, args ...string) (PlanResult, error) {
+	return planToFile(ctx, t.Dir(), t.opts, t.opts.bin("tofu"), planFile, args...)
+}
+
+func (t TofuExecutor) Show(ctx context.Context, planFile string) (*plan.Plan, error) {
+	return showPlan(ctx, t.Dir(), t.opts, t.opts.bin("tofu"), planFile)
+}
+
+func (t TofuExecutor) Workspaces(ctx context.Context) ([]string, error) {
+	return listWorkspaces(ctx, t.Dir(), t.opts, t.opts.bin("tofu"))
 }
 
 func (t TofuExecutor) ParsePlan(output string) string {
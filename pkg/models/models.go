@@ -1,24 +1,129 @@
 package models
 
+import "time"
+
 type ProjectType int
 
 const (
 	Terraform ProjectType = iota
 	Tofu
 	Terragrunt
+	Pulumi
 )
 
 type Project struct {
-	Dir string `json:"dir" yaml:"dir"`
+	Dir       string `json:"dir" yaml:"dir"`
+	Workspace string `json:"workspace,omitempty" yaml:"workspace,omitempty"`
+}
+
+// Key identifies the project across runs and in issue titles: its dir, suffixed with
+// @workspace when it plans a workspace other than the default one.
+func (p Project) Key() string {
+	return projectKey(p.Dir, p.Workspace)
+}
+
+func projectKey(dir, workspace string) string {
+	if workspace == "" {
+		return dir
+	}
+	return dir + "@" + workspace
 }
 
-// TypedProject represents a TF/Tofu/Terragrunt project to be analyzed
+// TypedProject represents a TF/Tofu/Terragrunt/Pulumi project to be analyzed
 type TypedProject struct {
 	// Dir is the discovered path to the project, carrying whatever prefix --repo-path had
 	// (or the temp clone dir under --repo-url). It is used as the subprocess working
 	// directory. Results report it relative to the repository root instead.
 	Dir  string      `json:"dir" yaml:"dir"`
 	Type ProjectType `json:"type" yaml:"type"`
+	// Name is a human-readable name, e.g. prod/payments, from driftive.yml or the Terramate stack
+	// the project was discovered from. Empty when none was given.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Workspace is the Terraform workspace to plan, or the Pulumi stack to preview. Empty uses
+	// the default workspace, or the stack selected in the project.
+	Workspace string `json:"workspace,omitempty" yaml:"workspace,omitempty"`
+	// Tags label the project, from driftive.yml and the Terramate stack it was discovered from.
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Owners are the teams or people responsible for the project, e.g. @org/payments.
+	Owners []string `json:"owners,omitempty" yaml:"owners,omitempty"`
+	// Severity ranks the project's drift: low, medium, high or critical. Empty when unset.
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	// Settings tune how the project is analyzed. They are not reported with results.
+	Settings ProjectSettings `json:"-" yaml:"-"`
+}
+
+// Key identifies the project across runs and in issue titles. See Project.Key.
+func (p TypedProject) Key() string {
+	return projectKey(p.Dir, p.Workspace)
+}
+
+// DisplayName is the name to show people: Name when set, suffixed with @workspace like Key, and
+// the key otherwise.
+func (p TypedProject) DisplayName() string {
+	if p.Name == "" {
+		return p.Key()
+	}
+	return projectKey(p.Name, p.Workspace)
+}
+
+// AsProject is the project as stored in issue metadata.
+func (p TypedProject) AsProject() Project {
+	return Project{Dir: p.Dir, Workspace: p.Workspace}
+}
+
+// DriftMode selects which plans are run to detect drift.
+type DriftMode string
+
+const (
+	// DriftModePlan runs a regular plan. Unapplied code changes and changes made outside
+	// Terraform are reported alike.
+	DriftModePlan DriftMode = "plan"
+	// DriftModeRefreshOnly runs `plan -refresh-only`, which only reports changes made outside
+	// Terraform.
+	DriftModeRefreshOnly DriftMode = "refresh-only"
+	// DriftModeBoth runs both plans, so each difference can be attributed to its source.
+	DriftModeBoth DriftMode = "both"
+)
+
+// ProjectSettings are per-project analysis settings, resolved from driftive.yml.
+type ProjectSettings struct {
+	// DriftMode overrides the repository-wide drift mode. Empty inherits it.
+	DriftMode DriftMode
+	// InitArgs and PlanArgs are appended to driftive's own init and plan arguments.
+	InitArgs []string
+	PlanArgs []string
+	// VarFiles are passed to plan as -var-file. BackendConfig entries are passed to init as
+	// -backend-config.
+	VarFiles      []string
+	BackendConfig []string
+	// Env is added to the environment of every command run for the project.
+	Env map[string]string
+	// EnvPassthrough restricts the host variables passed to the project's commands. Nil
+	// inherits settings.env_passthrough.
+	EnvPassthrough []string
+	// Timeout bounds the project's whole analysis. Zero inherits settings.timeout.
+	Timeout time.Duration
+	// AllWorkspaces asks for the project to be planned once per workspace its backend lists.
+	// The drift detector expands it into one project per workspace before analysis.
+	AllWorkspaces bool
+	// ToolVersion is the terraform or tofu version constraint set in the project's config, e.g.
+	// the terraform_version of an atlantis.yaml project. It wins over version files and
+	// required_version.
+	ToolVersion string
+	// WhenModified are globs, relative to the project dir, of files outside it that affect the
+	// project, e.g. ../modules/**/*.tf. An open PR changing a matching file skips the project's
+	// drift like one changing a file in its dir.
+	WhenModified []string
+	// DependsOn are the dirs of the projects this project reads outputs from, taken from its
+	// terragrunt dependency blocks. It is analyzed after them.
+	DependsOn []string
+	// Labels are added to the repository-wide labels of the project's drift issues.
+	Labels []string
+	// IgnoreResources, IgnoreResourceTypes and IgnoreAttributes extend drift.ignore for the
+	// project.
+	IgnoreResources     []string
+	IgnoreResourceTypes []string
+	IgnoreAttributes    []string
 }
 
 func ProjectTypeToStr(t ProjectType) string {
@@ -29,6 +134,24 @@ func ProjectTypeToStr(t ProjectType) string {
 		return "tofu"
 	case Terragrunt:
 		return "tg"
+	case Pulumi:
+		return "pulumi"
+	default:
+		return "?"
+	}
+}
+
+// ProjectTypeToExecutable is the executable name driftive.yml uses for t.
+func ProjectTypeToExecutable(t ProjectType) string {
+	switch t {
+	case Terraform:
+		return "terraform"
+	case Tofu:
+		return "tofu"
+	case Terragrunt:
+		return "terragrunt"
+	case Pulumi:
+		return "pulumi"
 	default:
 		return "?"
 	}
@@ -0,0 +1,109 @@
+// Package plan models the machine-readable plan produced by `terraform show -json` (and the
+// OpenTofu equivalent), so drift is decided from structured resource changes rather than from
+// the human-readable plan text.
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Actions reported in Change.Actions.
+const (
+	ActionNoOp   = "no-op"
+	ActionCreate = "create"
+	ActionRead   = "read"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Plan is the subset of the JSON plan representation driftive relies on.
+type Plan struct {
+	FormatVersion    string `json:"format_version"`
+	TerraformVersion string `json:"terraform_version,omitempty"`
+	// ResourceChanges are the changes the plan would apply.
+	ResourceChanges []ResourceChange `json:"resource_changes,omitempty"`
+	// ResourceDrift are the changes detected outside of Terraform while refreshing state.
+	ResourceDrift []ResourceChange  `json:"resource_drift,omitempty"`
+	OutputChanges map[string]Change `json:"output_changes,omitempty"`
+	Errored       bool              `json:"errored,omitempty"`
+}
+
+// ResourceChange is a single resource instance's planned change.
+type ResourceChange struct {
+	Address       string `json:"address"`
+	ModuleAddress string `json:"module_address,omitempty"`
+	Mode          string `json:"mode"`
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	ProviderName  string `json:"provider_name"`
+	Change        Change `json:"change"`
+	ActionReason  string `json:"action_reason,omitempty"`
+}
+
+// Change describes a change to a resource instance or an output. Before, After and
+// AfterUnknown are kept raw: they mirror the provider schema and may hold sensitive values.
+type Change struct {
+	Actions      []string        `json:"actions"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	AfterUnknown json.RawMessage `json:"after_unknown,omitempty"`
+}
+
+// IsNoOp reports whether the change leaves the object untouched.
+func (c Change) IsNoOp() bool {
+	for _, action := range c.Actions {
+		if action != ActionNoOp {
+			return false
+		}
+	}
+	return true
+}
+
+// HasChanges mirrors Terraform's own notion of an empty plan: any resource change other than a
+// no-op, or any change to a root module output, makes the plan non-empty.
+func (p *Plan) HasChanges() bool {
+	if p == nil {
+		return false
+	}
+	for _, rc := range p.ResourceChanges {
+		if !rc.Change.IsNoOp() {
+			return true
+		}
+	}
+	return p.HasOutputChanges()
+}
+
+// HasOutputChanges reports whether any root module output would change.
+func (p *Plan) HasOutputChanges() bool {
+	if p == nil {
+		return false
+	}
+	for _, oc := range p.OutputChanges {
+		if !oc.IsNoOp() {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse decodes the output of `show -json`. Wrappers such as terragrunt may print log lines
+// around the document, so anything before the opening brace is skipped.
+func Parse(data []byte) (*Plan, error) {
+	start := bytes.IndexByte(data, '{')
+	if start == -1 {
+		return nil, errors.New("no JSON plan found in output")
+	}
+
+	var p Plan
+	decoder := json.NewDecoder(bytes.NewReader(data[start:]))
+	if err := decoder.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON plan. %w", err)
+	}
+	if p.FormatVersion == "" {
+		return nil, errors.New("output is not a JSON plan: format_version is missing")
+	}
+	return &p, nil
+}
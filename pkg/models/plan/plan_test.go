@@ -0,0 +1,91 @@
+package plan
+
+import (
+	"driftive/pkg/utils"
+	"testing"
+)
+
+func TestParseJSONPlan(t *testing.T) {
+	p, err := Parse(utils.GetTestFile("test/output/plan_changes.json"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if len(p.ResourceChanges) != 2 {
+		t.Fatalf("expected 2 resource changes, got %d", len(p.ResourceChanges))
+	}
+	replaced := p.ResourceChanges[0]
+	if replaced.Address != "null_resource.foo" || replaced.ProviderName != "registry.terraform.io/hashicorp/null" {
+		t.Errorf("unexpected first resource change: %+v", replaced)
+	}
+	if p.ResourceChanges[1].ModuleAddress != "module.vpc" {
+		t.Errorf("ModuleAddress = %q, want module.vpc", p.ResourceChanges[1].ModuleAddress)
+	}
+	if len(p.ResourceDrift) != 1 {
+		t.Errorf("expected 1 resource drift entry, got %d", len(p.ResourceDrift))
+	}
+	if !p.HasChanges() {
+		t.Error("expected a plan with a replacement to have changes")
+	}
+}
+
+// TestParseSkipsWrapperLogLines covers terragrunt printing log lines before the document.
+func TestParseSkipsWrapperLogLines(t *testing.T) {
+	data := []byte("INFO[0000] Downloading Terraform configurations\n{\"format_version\":\"1.2\"}\n")
+
+	p, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if p.HasChanges() {
+		t.Error("expected an empty plan to have no changes")
+	}
+}
+
+func TestParseRejectsNonPlanOutput(t *testing.T) {
+	for _, input := range []string{"", "Error: no plan", `{"foo": "bar"}`} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("Parse(%q) expected an error", input)
+		}
+	}
+}
+
+func TestHasChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		plan *Plan
+		want bool
+	}{
+		{"nil plan", nil, false},
+		{"empty plan", &Plan{}, false},
+		{
+			name: "no-op only",
+			plan: &Plan{ResourceChanges: []ResourceChange{{Change: Change{Actions: []string{ActionNoOp}}}}},
+			want: false,
+		},
+		{
+			name: "update",
+			plan: &Plan{ResourceChanges: []ResourceChange{{Change: Change{Actions: []string{ActionUpdate}}}}},
+			want: true,
+		},
+		{
+			name: "output change only",
+			plan: &Plan{OutputChanges: map[string]Change{"id": {Actions: []string{ActionCreate}}}},
+			want: true,
+		},
+		{
+			// Drift outside of Terraform that the configuration would not revert is not a change.
+			name: "resource drift only",
+			plan: &Plan{ResourceDrift: []ResourceChange{{Change: Change{Actions: []string{ActionUpdate}}}}},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.plan.HasChanges(); got != tt.want {
+				t.Errorf("HasChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
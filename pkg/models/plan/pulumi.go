@@ -0,0 +1,137 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Operations reported by the steps of `pulumi preview --json`.
+const (
+	pulumiOpSame              = "same"
+	pulumiOpCreate            = "create"
+	pulumiOpUpdate            = "update"
+	pulumiOpDelete            = "delete"
+	pulumiOpReplace           = "replace"
+	pulumiOpCreateReplacement = "create-replacement"
+)
+
+// PulumiPreview is the subset of the `pulumi preview --json` document driftive relies on.
+type PulumiPreview struct {
+	Steps       []PulumiStep       `json:"steps"`
+	Diagnostics []PulumiDiagnostic `json:"diagnostics,omitempty"`
+}
+
+// PulumiStep is the operation the preview would perform on one resource.
+type PulumiStep struct {
+	Op       string               `json:"op"`
+	URN      string               `json:"urn"`
+	OldState *PulumiResourceState `json:"oldState,omitempty"`
+	NewState *PulumiResourceState `json:"newState,omitempty"`
+}
+
+// PulumiResourceState is a resource's state before or after a step. Inputs are kept raw like
+// Change.Before and Change.After.
+type PulumiResourceState struct {
+	Type   string          `json:"type"`
+	Inputs json.RawMessage `json:"inputs,omitempty"`
+}
+
+// PulumiDiagnostic is a message the preview printed, e.g. the reason it failed.
+type PulumiDiagnostic struct {
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+	URN      string `json:"urn,omitempty"`
+}
+
+// ParsePulumiPreview decodes the output of `pulumi preview --json`. Anything before the opening
+// brace is skipped, as with Parse.
+func ParsePulumiPreview(data []byte) (*PulumiPreview, error) {
+	start := bytes.IndexByte(data, '{')
+	if start == -1 {
+		return nil, errors.New("no JSON preview found in output")
+	}
+
+	var p PulumiPreview
+	decoder := json.NewDecoder(bytes.NewReader(data[start:]))
+	if err := decoder.Decode(&p); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON preview. %w", err)
+	}
+	return &p, nil
+}
+
+// Errors are the messages of the preview's error diagnostics.
+func (p *PulumiPreview) Errors() []string {
+	var messages []string
+	for _, d := range p.Diagnostics {
+		if d.Severity == "error" {
+			messages = append(messages, strings.TrimSpace(d.Message))
+		}
+	}
+	return messages
+}
+
+// Plan converts the preview into a Plan. Each step becomes a resource change addressed by its
+// type and name, e.g. aws:s3/bucket:Bucket::logs, with the resource inputs as before and after
+// values. The steps of a replacement are folded into a single delete-and-create change, and
+// steps that change nothing, such as reads and refreshes, are left out.
+func (p *PulumiPreview) Plan() *Plan {
+	converted := &Plan{FormatVersion: "pulumi"}
+	seen := make(map[string]bool, len(p.Steps))
+	for _, step := range p.Steps {
+		actions := pulumiActions(step.Op)
+		// A replacement may be listed as both a replace and a create-replacement step.
+		if actions == nil || seen[step.URN] {
+			continue
+		}
+		seen[step.URN] = true
+		qualifiedType, name := splitURN(step.URN)
+		resourceType := qualifiedType[strings.LastIndex(qualifiedType, "$")+1:]
+		change := ResourceChange{
+			Address:      qualifiedType + "::" + name,
+			Mode:         "managed",
+			Type:         resourceType,
+			Name:         name,
+			ProviderName: strings.SplitN(resourceType, ":", 2)[0],
+			Change:       Change{Actions: actions},
+		}
+		if step.OldState != nil {
+			change.Change.Before = step.OldState.Inputs
+		}
+		if step.NewState != nil {
+			change.Change.After = step.NewState.Inputs
+		}
+		converted.ResourceChanges = append(converted.ResourceChanges, change)
+	}
+	return converted
+}
+
+// pulumiActions maps a step operation to plan actions. Nil for steps that are not reported.
+func pulumiActions(op string) []string {
+	switch op {
+	case pulumiOpSame:
+		return []string{ActionNoOp}
+	case pulumiOpCreate:
+		return []string{ActionCreate}
+	case pulumiOpUpdate:
+		return []string{ActionUpdate}
+	case pulumiOpDelete:
+		return []string{ActionDelete}
+	case pulumiOpReplace, pulumiOpCreateReplacement:
+		return []string{ActionDelete, ActionCreate}
+	default:
+		return nil
+	}
+}
+
+// splitURN returns the qualified type and the name of a resource URN, which has the form
+// urn:pulumi:<stack>::<project>::<qualified type>::<name>. The name may itself contain "::".
+func splitURN(urn string) (string, string) {
+	parts := strings.SplitN(urn, "::", 4)
+	if len(parts) < 4 {
+		return "", urn
+	}
+	return parts[2], parts[3]
+}
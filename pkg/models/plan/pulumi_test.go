@@ -0,0 +1,62 @@
+package plan
+
+import (
+	"driftive/pkg/utils"
+	"slices"
+	"testing"
+)
+
+func TestPulumiPreviewPlan(t *testing.T) {
+	preview, err := ParsePulumiPreview(utils.GetTestFile("test/output/pulumi_preview.json"))
+	if err != nil {
+		t.Fatalf("ParsePulumiPreview() error = %v", err)
+	}
+	p := preview.Plan()
+
+	if len(p.ResourceChanges) != 3 {
+		t.Fatalf("expected the stack, the bucket and one change for the replacement, got %+v", p.ResourceChanges)
+	}
+	bucket := p.ResourceChanges[1]
+	if bucket.Address != "aws:s3/bucket:Bucket::logs" || bucket.Type != "aws:s3/bucket:Bucket" || bucket.ProviderName != "aws" {
+		t.Errorf("unexpected bucket change: %+v", bucket)
+	}
+	if !slices.Equal(bucket.Change.Actions, []string{ActionUpdate}) || len(bucket.Change.Before) == 0 || len(bucket.Change.After) == 0 {
+		t.Errorf("expected an update with inputs before and after, got %+v", bucket.Change)
+	}
+	eip := p.ResourceChanges[2]
+	if eip.Address != "my:net:Vpc$aws:ec2/eip:Eip::nat" || eip.Type != "aws:ec2/eip:Eip" {
+		t.Errorf("unexpected replacement: %+v", eip)
+	}
+	if !slices.Equal(eip.Change.Actions, []string{ActionDelete, ActionCreate}) {
+		t.Errorf("replacement actions = %v", eip.Change.Actions)
+	}
+	if !p.HasChanges() {
+		t.Error("expected the preview to have changes")
+	}
+}
+
+func TestPulumiPreviewWithoutChanges(t *testing.T) {
+	data := []byte(`{"steps":[{"op":"same","urn":"urn:pulumi:dev::app::pulumi:pulumi:Stack::app-dev"}]}`)
+
+	preview, err := ParsePulumiPreview(data)
+	if err != nil {
+		t.Fatalf("ParsePulumiPreview() error = %v", err)
+	}
+	if preview.Plan().HasChanges() {
+		t.Error("expected a preview of same steps to have no changes")
+	}
+}
+
+func TestPulumiPreviewErrors(t *testing.T) {
+	data := []byte(`{"steps":[],"diagnostics":[
+		{"message":"warning: deprecated","severity":"warning"},
+		{"message":"error: no stack named 'prod' found\n","severity":"error"}]}`)
+
+	preview, err := ParsePulumiPreview(data)
+	if err != nil {
+		t.Fatalf("ParsePulumiPreview() error = %v", err)
+	}
+	if got := preview.Errors(); !slices.Equal(got, []string{"error: no stack named 'prod' found"}) {
+		t.Errorf("Errors() = %v", got)
+	}
+}
@@ -26,12 +26,26 @@ func (s Stdout) Handle(ctx context.Context, driftResult drift.DriftDetectionResu
 	s.logger.Info().Msgf("%d projects: %d drifted, %d errored, %d skipped, %d clean",
 		summary.TotalProjects, summary.NumDrifted(), summary.NumErrored(), summary.NumSkipped(), summary.NumClean())
 
+	if summary.Changes.Total() > 0 {
+		s.logger.Info().Msgf("Drifted resources: %s", summary.Changes)
+	}
+
+	if summary.NumTimedOut() > 0 {
+		s.logger.Info().Msgf("%d projects timed out", summary.NumTimedOut())
+	}
+
+	if summary.NumBlocked() > 0 {
+		s.logger.Info().Msgf("%d projects blocked by upstream errors", summary.NumBlocked())
+	}
+
 	if summary.NotChecked > 0 {
 		s.logger.Info().Msgf("%d projects were not checked", summary.NotChecked)
 	}
 
 	s.section("Projects with state drift:", summary.Drifted)
 	s.section("Projects that failed to analyze:", summary.Errored)
+	s.section("Projects that timed out:", summary.TimedOut)
+	s.section("Projects blocked by upstream errors:", summary.Blocked)
 	s.section("Skipped due to open PRs:", summary.Skipped)
 
 	if !summary.HasFindings() {
@@ -53,8 +67,18 @@ func (s Stdout) section(title string, projects []report.Project) {
 }
 
 func describe(p report.Project) string {
-	if p.FailedPhase == "" {
-		return p.Dir
+	label := p.Title()
+	if metadata := p.MetadataText(); metadata != "" {
+		label += " (" + metadata + ")"
+	}
+	if p.BlockedBy != "" {
+		return fmt.Sprintf("%s (blocked by %s)", label, p.BlockedBy)
+	}
+	if p.FailedPhase != "" {
+		return fmt.Sprintf("%s (%s)", label, p.FailedPhase)
+	}
+	if changes := p.ChangeText(); changes != "" {
+		return fmt.Sprintf("%s (%s)", label, changes)
 	}
-	return fmt.Sprintf("%s (%s)", p.Dir, p.FailedPhase)
+	return label
 }
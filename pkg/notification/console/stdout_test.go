@@ -86,6 +86,24 @@ func TestStdoutShowsFailedPhase(t *testing.T) {
 	}
 }
 
+func TestStdoutShowsResourceBreakdown(t *testing.T) {
+	vpc := projectResult("infra/prod/vpc", true, true, false, "")
+	vpc.DriftedResources = []drift.DriftedResource{
+		{Address: "aws_vpc.main", Action: drift.ActionUpdate},
+		{Address: "aws_subnet.a", Action: drift.ActionUpdate},
+		{Address: "aws_eip.nat", Action: drift.ActionReplace},
+	}
+
+	out := handleAndCapture(t, result([]drift.DriftProjectResult{vpc}, 1))
+
+	if !strings.Contains(out, "infra/prod/vpc (2 updates, 1 replace)") {
+		t.Errorf("expected the per-project breakdown, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Drifted resources: 2 updates, 1 replace") {
+		t.Errorf("expected the run totals, got:\n%s", out)
+	}
+}
+
 func TestStdoutListsSkippedProjects(t *testing.T) {
 	out := handleAndCapture(t, result([]drift.DriftProjectResult{
 		projectResult("infra/prod/vpc", true, true, false, ""),
@@ -124,3 +142,36 @@ func TestNewStdoutUsesGlobalLogger(t *testing.T) {
 		t.Errorf("Handle() error = %v", err)
 	}
 }
+
+func TestStdoutListsTimedOutProjectsSeparately(t *testing.T) {
+	timedOut := projectResult("infra/prod/eks", false, false, false, drift.PhasePlan)
+	timedOut.FailureReason = drift.ReasonTimeout
+
+	out := handleAndCapture(t, result([]drift.DriftProjectResult{timedOut}, 1))
+
+	if !strings.Contains(out, "Projects that timed out") || !strings.Contains(out, "infra/prod/eks (plan)") {
+		t.Errorf("expected a timed out section, got:\n%s", out)
+	}
+	if strings.Contains(out, "failed to analyze") {
+		t.Errorf("a timeout must not be listed with errors, got:\n%s", out)
+	}
+	if !strings.Contains(out, "1 projects timed out") {
+		t.Errorf("expected the timeout count, got:\n%s", out)
+	}
+}
+
+func TestStdoutListsBlockedProjects(t *testing.T) {
+	blocked := projectResult("live/app", false, false, false, "")
+	blocked.FailureReason = drift.ReasonBlocked
+	blocked.BlockedBy = "live/vpc"
+	failed := projectResult("live/vpc", false, false, false, drift.PhasePlan)
+
+	out := handleAndCapture(t, result([]drift.DriftProjectResult{blocked, failed}, 2))
+
+	if !strings.Contains(out, "Projects blocked by upstream errors") || !strings.Contains(out, "live/app (blocked by live/vpc)") {
+		t.Errorf("expected a blocked section, got:\n%s", out)
+	}
+	if !strings.Contains(out, "1 projects blocked by upstream errors") {
+		t.Errorf("expected the blocked count, got:\n%s", out)
+	}
+}
@@ -0,0 +1,161 @@
+// Package discord posts drift reports to a Discord channel webhook as an embed.
+package discord
+
+import (
+	"context"
+	"driftive/pkg/drift"
+	"driftive/pkg/models/backend"
+	"driftive/pkg/notification/post"
+	"driftive/pkg/notification/report"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Embed colors, the same as Slack's
+const (
+	colorDanger  = 0xE53E3E // Red for drifts detected
+	colorWarning = 0xED8936 // Orange for errors without drift
+	colorSuccess = 0x38A169 // Green for all resolved
+)
+
+// maxProjectListChars is the budget for one project list. Discord caps an embed field value at
+// 1024 characters, and the whole embed at 6000, which four full lists and the stats fit in.
+const maxProjectListChars = 1000
+
+// Discord webhook types
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	URL         string         `json:"url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields"`
+	Footer      discordFooter  `json:"footer"`
+}
+
+type discordMessage struct {
+	Username string `json:"username"`
+	// Content is the message text, shown in push notifications. Embeds are not.
+	Content string         `json:"content"`
+	Embeds  []discordEmbed `json:"embeds"`
+}
+
+type Discord struct {
+	Url          string
+	IssuesState  *backend.DriftIssuesState
+	DashboardURL string
+	// Repo is "owner/name" from the GitHub Actions context, used to identify the source
+	// repository and to build issue links. Empty outside GitHub Actions.
+	Repo string
+	// DriftIssues and ErrorIssues map a project key to its open GitHub issue number. Nil when
+	// GitHub issues are disabled, in which case rows render as plain text.
+	DriftIssues map[string]int
+	ErrorIssues map[string]int
+}
+
+func (discord Discord) Handle(ctx context.Context, driftResult drift.DriftDetectionResult) error {
+	summary := report.Classify(driftResult)
+
+	if !summary.ShouldNotify(discord.IssuesState) {
+		log.Info().Msg("No drifts or errors detected. Skipping discord notification")
+		return nil
+	}
+
+	return post.JSON(ctx, "discord", discord.Url, discord.buildMessage(summary))
+}
+
+func (discord Discord) buildMessage(summary report.Summary) discordMessage {
+	color, title := discord.headline(summary)
+
+	fields := discord.statsFields(summary)
+	links := report.IssueLinks{Repo: discord.Repo, DriftIssues: discord.DriftIssues, ErrorIssues: discord.ErrorIssues}
+	for _, list := range summary.Lists(links) {
+		fields = append(fields, discordField{
+			Name:  list.Heading,
+			Value: discord.renderProjectList(list.Lines, maxProjectListChars),
+		})
+	}
+
+	var description []string
+	if report.IssuesResolved(discord.IssuesState) {
+		description = append(description, "🎉 "+report.Resolved(discord.IssuesState).Text(bold))
+	}
+	if discord.DashboardURL != "" {
+		description = append(description, fmt.Sprintf("[View in Dashboard](%s)", discord.DashboardURL))
+	}
+
+	return discordMessage{
+		Username: "Driftive",
+		Content:  summary.Synopsis(discord.Repo),
+		Embeds: []discordEmbed{
+			{
+				Title:       title,
+				URL:         discord.DashboardURL,
+				Description: strings.Join(description, "\n\n"),
+				Color:       color,
+				Fields:      fields,
+				Footer:      discordFooter{Text: discord.footerText()},
+			},
+		},
+	}
+}
+
+func (discord Discord) headline(summary report.Summary) (color int, title string) {
+	switch summary.Headline(discord.IssuesState) {
+	case report.HeadlineDrift:
+		return colorDanger, "⚠️ Drift Detected"
+	case report.HeadlineErrors:
+		return colorWarning, "🚨 Analysis Errors"
+	case report.HeadlineResolved:
+		return colorSuccess, "✅ All Drifts Resolved"
+	}
+	return 0, ""
+}
+
+// statsFields are inline, so Discord lays them out up to three per row.
+func (discord Discord) statsFields(summary report.Summary) []discordField {
+	stats := summary.Stats()
+	fields := make([]discordField, 0, len(stats))
+	for _, stat := range stats {
+		value := stat.Value
+		if stat.Detail != "" {
+			value += "\n" + stat.Detail
+		}
+		fields = append(fields, discordField{Name: stat.Name, Value: value, Inline: true})
+	}
+	return fields
+}
+
+// renderProjectList builds a markdown list with one bullet per project, stopping before budget
+// bytes and appending a "…and N more" suffix.
+func (discord Discord) renderProjectList(lines []report.Line, budget int) string {
+	format := report.ListFormat{Line: report.MarkdownLine, Truncated: discord.buildTruncationSuffix}
+	return strings.TrimSuffix(format.Render("", lines, budget), "\n")
+}
+
+func (discord Discord) buildTruncationSuffix(remaining int) string {
+	return "_" + report.TruncationText(remaining, discord.DashboardURL != "") + "_\n"
+}
+
+// footerText is plain text: Discord renders no links in embed footers.
+func (discord Discord) footerText() string {
+	if discord.Repo == "" {
+		return "Detected by Driftive"
+	}
+	return discord.Repo + " · Detected by Driftive"
+}
+
+func bold(text string) string {
+	return "**" + text + "**"
+}
@@ -0,0 +1,177 @@
+package discord
+
+import (
+	"context"
+	"driftive/pkg/drift"
+	"driftive/pkg/models"
+	"driftive/pkg/models/backend"
+	"driftive/pkg/notification/report"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func drifted(dir string) drift.DriftProjectResult {
+	return drift.DriftProjectResult{Project: models.TypedProject{Dir: dir}, Drifted: true, Succeeded: true}
+}
+
+func clean(dir string) drift.DriftProjectResult {
+	return drift.DriftProjectResult{Project: models.TypedProject{Dir: dir}, Succeeded: true}
+}
+
+func errored(dir, phase string) drift.DriftProjectResult {
+	return drift.DriftProjectResult{Project: models.TypedProject{Dir: dir}, FailedPhase: phase}
+}
+
+func build(discord Discord, result drift.DriftDetectionResult) discordMessage {
+	return discord.buildMessage(report.Classify(result))
+}
+
+func field(embed discordEmbed, name string) string {
+	for _, f := range embed.Fields {
+		if f.Name == name {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+func TestBuildMessage_WithDriftsAndErrors(t *testing.T) {
+	discord := Discord{
+		DashboardURL: "https://app.driftive.cloud/runs/1",
+		Repo:         "acme/infra",
+		DriftIssues:  map[string]int{"terraform/vpc": 3},
+	}
+	message := build(discord, drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{
+			drifted("terraform/vpc"),
+			errored("terraform/db", drift.PhasePlan),
+			clean("terraform/iam"),
+		},
+		TotalProjects: 3,
+		Duration:      time.Minute,
+	})
+	embed := message.Embeds[0]
+
+	if embed.Color != colorDanger || !strings.Contains(embed.Title, "Drift Detected") {
+		t.Errorf("unexpected headline %q (%x)", embed.Title, embed.Color)
+	}
+	if embed.URL != discord.DashboardURL || !strings.Contains(embed.Description, "[View in Dashboard]") {
+		t.Errorf("expected the dashboard link, got url=%q description=%q", embed.URL, embed.Description)
+	}
+	if got := field(embed, "Drifted"); got != "1 / 3 projects" {
+		t.Errorf("Drifted field = %q", got)
+	}
+	if got := field(embed, "Drifted Projects"); got != "- [terraform/vpc](https://github.com/acme/infra/issues/3)" {
+		t.Errorf("drifted list = %q", got)
+	}
+	if got := field(embed, "Failed Projects"); got != "- terraform/db _(plan)_" {
+		t.Errorf("failed list = %q", got)
+	}
+	if embed.Footer.Text != "acme/infra · Detected by Driftive" {
+		t.Errorf("footer = %q", embed.Footer.Text)
+	}
+	if message.Content != "[acme/infra] Drift detected in 1 project(s), 1 failed to analyze" {
+		t.Errorf("content = %q", message.Content)
+	}
+}
+
+func TestBuildMessage_AllResolved(t *testing.T) {
+	discord := Discord{IssuesState: &backend.DriftIssuesState{StateUpdated: true, NumResolvedIssues: 2}}
+	embed := build(discord, drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{clean("a")}, TotalProjects: 1}).Embeds[0]
+
+	if embed.Color != colorSuccess || !strings.Contains(embed.Description, "**2 issue(s) resolved**") {
+		t.Errorf("unexpected resolved embed: %+v", embed)
+	}
+	if field(embed, "Drifted Projects") != "" {
+		t.Error("expected no project lists")
+	}
+}
+
+// TestBuildMessage_StaysWithinEmbedLimits pins Discord's limits: 1024 characters per field value
+// and 6000 for the whole embed.
+func TestBuildMessage_StaysWithinEmbedLimits(t *testing.T) {
+	result := drift.DriftDetectionResult{TotalProjects: 800}
+	for i := range 200 {
+		result.ProjectResults = append(result.ProjectResults,
+			drifted(fmt.Sprintf("terraform/production/drifted-%03d", i)),
+			errored(fmt.Sprintf("terraform/production/errored-%03d", i), drift.PhaseInit),
+			drift.DriftProjectResult{Project: models.TypedProject{Dir: fmt.Sprintf("terraform/production/slow-%03d", i)},
+				FailureReason: drift.ReasonTimeout, FailedPhase: drift.PhasePlan},
+			drift.DriftProjectResult{Project: models.TypedProject{Dir: fmt.Sprintf("terraform/production/blocked-%03d", i)},
+				FailureReason: drift.ReasonBlocked, BlockedBy: "terraform/production/errored-000"})
+	}
+	embed := build(Discord{DashboardURL: "https://app.driftive.cloud/runs/1"}, result).Embeds[0]
+
+	total := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description) +
+		utf8.RuneCountInString(embed.Footer.Text)
+	for _, f := range embed.Fields {
+		if n := utf8.RuneCountInString(f.Value); n > 1024 {
+			t.Errorf("field %q is %d characters, over 1024", f.Name, n)
+		}
+		total += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	if total > 6000 {
+		t.Errorf("embed is %d characters, over 6000", total)
+	}
+	if !strings.Contains(field(embed, "Drifted Projects"), "more project(s). View all in the dashboard.") {
+		t.Error("expected the drifted list to be truncated")
+	}
+}
+
+func TestHandle_SkipsWhenOnlyClean(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("should not send request when nothing drifted, errored or resolved")
+	}))
+	defer server.Close()
+
+	discord := Discord{Url: server.URL}
+	result := drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{clean("a")}, TotalProjects: 1}
+	if err := discord.Handle(context.Background(), result); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHandle_SendsEmbed(t *testing.T) {
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	discord := Discord{Url: server.URL}
+	result := drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{drifted("terraform/vpc")}, TotalProjects: 1}
+	if err := discord.Handle(context.Background(), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var message discordMessage
+	if err := json.Unmarshal(receivedBody, &message); err != nil {
+		t.Fatalf("failed to unmarshal sent message: %v", err)
+	}
+	if len(message.Embeds) != 1 {
+		t.Errorf("expected 1 embed, got %d", len(message.Embeds))
+	}
+}
+
+func TestHandle_ReturnsErrorOnBadStatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"embeds": ["0"]}`))
+	}))
+	defer server.Close()
+
+	discord := Discord{Url: server.URL}
+	result := drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{drifted("a")}, TotalProjects: 1}
+	err := discord.Handle(context.Background(), result)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("expected an error with the status code, got: %v", err)
+	}
+}
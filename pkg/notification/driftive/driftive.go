@@ -3,13 +3,13 @@ package driftive
 import (
 	"context"
 	"driftive/pkg/drift"
+	"driftive/pkg/notification/post"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
-	"resty.dev/v3"
 )
 
 // AnalysisResponse is the response from the Driftive API after uploading analysis results
@@ -44,22 +44,8 @@ func (d Driftive) Handle(ctx context.Context, driftResult drift.DriftDetectionRe
 		idemKey = uuid.NewString()
 	}
 
-	client := resty.New().
-		SetTimeout(30 * time.Second).
-		SetRetryCount(3).
-		// resty only retries idempotent methods unless told otherwise, so without this the
-		// retry count and conditions below never apply to this POST. The Idempotency-Key
-		// sent on every attempt is what makes retrying it safe.
-		SetRetryAllowNonIdempotent(true).
-		SetRetryWaitTime(2 * time.Second).
-		SetRetryMaxWaitTime(15 * time.Second).
-		AddRetryConditions(func(res *resty.Response, err error) bool {
-			if err != nil {
-				return true
-			}
-			sc := res.StatusCode()
-			return sc == 429 || sc >= 500
-		})
+	// The Idempotency-Key sent on every attempt is what makes retrying this POST safe.
+	client := post.NewRetryingClient(2*time.Second, 15*time.Second)
 	defer client.Close()
 
 	res, err := client.R().
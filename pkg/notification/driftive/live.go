@@ -88,11 +88,12 @@ func NewLiveReporter(url, token, runKey string, totalProjects int) *LiveReporter
 	}
 }
 
-// ProjectStarted records that a project's analysis has begun. Safe for concurrent use.
-func (r *LiveReporter) ProjectStarted(dir string) {
+// ProjectStarted records that a project's analysis has begun, by its key. Safe for concurrent
+// use.
+func (r *LiveReporter) ProjectStarted(key string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.running[dir] = struct{}{}
+	r.running[key] = struct{}{}
 	r.version++
 }
 
@@ -100,7 +101,7 @@ func (r *LiveReporter) ProjectStarted(dir string) {
 func (r *LiveReporter) ProjectFinished(result drift.DriftProjectResult) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	delete(r.running, result.Project.Dir)
+	delete(r.running, result.Project.Key())
 	r.pending = append(r.pending, result)
 	r.version++
 }
@@ -10,7 +10,7 @@ func (g *GithubIssueNotification) CloseIssueWithComment(ctx context.Context,
 	projectIssue types.ProjectIssue) bool {
 	log.Info().Msgf("Closing issue [%s] for project %s (repo: %s/%s)",
 		projectIssue.Kind,
-		projectIssue.Project.Dir,
+		projectIssue.Project.Key(),
 		g.config.GithubContext.RepositoryOwner,
 		g.config.GithubContext.GetRepositoryName())
 
@@ -27,7 +27,7 @@ func (g *GithubIssueNotification) CloseIssueWithComment(ctx context.Context,
 	}
 
 	log.Info().Msgf("Closed issue [%s] for project %s (repo: %s/%s)",
-		projectIssue.Kind, projectIssue.Project.Dir, g.config.GithubContext.RepositoryOwner,
+		projectIssue.Kind, projectIssue.Project.Key(), g.config.GithubContext.RepositoryOwner,
 		g.config.GithubContext.GetRepositoryName())
 	return true
 }
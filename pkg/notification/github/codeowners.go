@@ -0,0 +1,148 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// codeOwnersPaths are where GitHub looks for CODEOWNERS, in order. The first one found is used.
+var codeOwnersPaths = []string{
+	filepath.Join(".github", "CODEOWNERS"),
+	"CODEOWNERS",
+	filepath.Join("docs", "CODEOWNERS"),
+}
+
+// CodeOwners are the rules of a repository's CODEOWNERS file.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// LoadCodeOwners reads the CODEOWNERS file of the repository in repoDir. Returns nil when the
+// repository has none or it cannot be read.
+func LoadCodeOwners(repoDir string) *CodeOwners {
+	for _, name := range codeOwnersPaths {
+		content, err := os.ReadFile(filepath.Join(repoDir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			log.Warn().Msgf("Failed to read %s. Issues will not be assigned to code owners. %v", name, err)
+			return nil
+		}
+		log.Info().Msgf("Assigning issues to the code owners in %s", filepath.ToSlash(name))
+		return ParseCodeOwners(content)
+	}
+	return nil
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file. Lines with an invalid pattern are
+// skipped, like GitHub does.
+func ParseCodeOwners(content []byte) *CodeOwners {
+	var co CodeOwners
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pattern, err := compileCodeOwnersPattern(fields[0])
+		if err != nil {
+			log.Warn().Msgf("Skipping CODEOWNERS pattern %s. %v", fields[0], err)
+			continue
+		}
+		co.rules = append(co.rules, codeOwnersRule{pattern: pattern, owners: fields[1:]})
+	}
+	return &co
+}
+
+// codeOwnersProbe stands in for a file name when looking up the owners of a dir. It matches
+// wildcards but no literal file name.
+const codeOwnersProbe = "\x00"
+
+// Owners returns the owners of the project in dir, relative to the repository root: those
+// CODEOWNERS gives a file directly in it. Patterns naming files, like *.tf, do not count. As in
+// GitHub, the last matching rule wins, and a rule without owners leaves the dir unowned.
+func (co *CodeOwners) Owners(dir string) []string {
+	if co == nil {
+		return nil
+	}
+	file := path.Join(filepath.ToSlash(dir), codeOwnersProbe)
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].pattern.MatchString(file) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// compileCodeOwnersPattern turns a gitignore-style CODEOWNERS pattern into a regexp matching
+// the repository-relative paths it covers.
+func compileCodeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	// A pattern ending in /* only covers the files directly in the dir, the others also cover
+	// everything nested under what they match.
+	suffix := "(/.*)?"
+	if strings.HasSuffix(pattern, "/*") {
+		suffix = ""
+	}
+	pattern = strings.TrimSuffix(pattern, "/")
+	// A pattern is anchored to the root when it has a slash other than a trailing one.
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	expr.WriteString(suffix + "$")
+	return regexp.Compile(expr.String())
+}
+
+// splitOwners splits owners into the users an issue can be assigned to, without their @, and
+// the teams to mention. Email owners are left out: they can be neither assigned nor mentioned.
+func splitOwners(owners []string) (users, teams []string) {
+	for _, owner := range owners {
+		name, ok := strings.CutPrefix(owner, "@")
+		switch {
+		case !ok:
+		case strings.Contains(name, "/"):
+			teams = append(teams, owner)
+		default:
+			users = append(users, name)
+		}
+	}
+	return users, teams
+}
@@ -0,0 +1,87 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCodeOwnersOwners(t *testing.T) {
+	co := ParseCodeOwners([]byte(`# Default owners
+* @acme/infra
+
+/infra/ @alice # the infra dir
+apps @bob
+/services/*   @carol
+/modules/**/network @dave
+*.md @docs
+/infra/legacy/
+`))
+
+	tests := []struct {
+		dir  string
+		want []string
+	}{
+		{dir: ".", want: []string{"@acme/infra"}},
+		{dir: "infra", want: []string{"@alice"}},
+		{dir: "infra/prod/eu", want: []string{"@alice"}},
+		// The last matching rule wins, even without owners.
+		{dir: "infra/legacy", want: []string{}},
+		{dir: "other/infra", want: []string{"@acme/infra"}},
+		// Unanchored patterns match at any depth.
+		{dir: "apps", want: []string{"@bob"}},
+		{dir: "teams/apps/web", want: []string{"@bob"}},
+		// /* only covers files directly in the dir.
+		{dir: "services", want: []string{"@carol"}},
+		{dir: "services/api", want: []string{"@acme/infra"}},
+		{dir: "modules/network", want: []string{"@dave"}},
+		{dir: "modules/aws/vpc/network/peering", want: []string{"@dave"}},
+		{dir: "modules/networks", want: []string{"@acme/infra"}},
+	}
+	for _, tt := range tests {
+		got := co.Owners(tt.dir)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Owners(%q) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestCodeOwnersNil(t *testing.T) {
+	var co *CodeOwners
+	if got := co.Owners("infra"); got != nil {
+		t.Errorf("Owners() = %v, want nil", got)
+	}
+}
+
+func TestSplitOwners(t *testing.T) {
+	users, teams := splitOwners([]string{"@alice", "@acme/platform", "ops@example.com", "@bob"})
+	if !reflect.DeepEqual(users, []string{"alice", "bob"}) {
+		t.Errorf("users = %v", users)
+	}
+	if !reflect.DeepEqual(teams, []string{"@acme/platform"}) {
+		t.Errorf("teams = %v", teams)
+	}
+}
+
+func TestLoadCodeOwners(t *testing.T) {
+	repoDir := t.TempDir()
+	if co := LoadCodeOwners(repoDir); co != nil {
+		t.Fatalf("expected nil without a CODEOWNERS file")
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "CODEOWNERS"), []byte("* @root\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(repoDir, ".github"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, ".github", "CODEOWNERS"), []byte("* @github\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// .github/CODEOWNERS takes precedence, like on GitHub.
+	if got := LoadCodeOwners(repoDir).Owners("infra"); !reflect.DeepEqual(got, []string{"@github"}) {
+		t.Errorf("Owners() = %v, want [@github]", got)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -46,9 +47,12 @@ type GithubIssueNotification struct {
 	ghClient     *github.Client
 	scm          vcs.VCS
 	dashboardURL string
+	// codeOwners assigns and mentions the owners of each project's dir. Nil when the repository
+	// has no CODEOWNERS.
+	codeOwners *CodeOwners
 }
 
-func NewGithubIssueNotification(config *config.DriftiveConfig, repoConfig *repo.DriftiveRepoConfig, ghOpts vcs.VCS, dashboardURL string) (*GithubIssueNotification, error) {
+func NewGithubIssueNotification(config *config.DriftiveConfig, repoConfig *repo.DriftiveRepoConfig, ghOpts vcs.VCS, dashboardURL string, codeOwners *CodeOwners) (*GithubIssueNotification, error) {
 	if config.GithubContext.Repository == "" || config.GithubContext.RepositoryOwner == "" {
 		log.Warn().Msg("Github repository or owner not provided. Skipping github notification")
 		return nil, errors.New(ErrRepoNotProvided)
@@ -59,20 +63,55 @@ func NewGithubIssueNotification(config *config.DriftiveConfig, repoConfig *repo.
 		log.Warn().Msg("Github token not provided. Skipping github notification")
 		return nil, err
 	}
-	return &GithubIssueNotification{config: config, repoConfig: repoConfig, ghClient: ghClient, scm: ghOpts, dashboardURL: dashboardURL}, nil
+	return &GithubIssueNotification{config: config, repoConfig: repoConfig, ghClient: ghClient, scm: ghOpts, dashboardURL: dashboardURL,
+		codeOwners: codeOwners}, nil
 }
 
-func parseGithubBodyTemplate(project drift.DriftProjectResult, bodyTemplate string) (*string, error) {
+// driftSourceText explains where a project's drift came from. Empty when no refresh-only plan
+// ran, which keeps the body of plan-mode issues unchanged.
+func driftSourceText(source string) string {
+	switch source {
+	case drift.DriftSourceExternal:
+		return "**Source:** infrastructure was changed outside Terraform."
+	case drift.DriftSourceCode:
+		return "**Source:** merged code changes have not been applied yet."
+	case drift.DriftSourceMixed:
+		return "**Source:** infrastructure was changed outside Terraform, and merged code changes have not been applied yet."
+	default:
+		return ""
+	}
+}
+
+// metadataText lists the project's name, owners, severity and tags for an issue body. Empty when
+// none is set.
+func metadataText(project models.TypedProject) string {
+	var parts []string
+	if project.Name != "" {
+		parts = append(parts, "**Name:** "+project.DisplayName())
+	}
+	if len(project.Owners) > 0 {
+		parts = append(parts, "**Owners:** "+strings.Join(project.Owners, " "))
+	}
+	if project.Severity != "" {
+		parts = append(parts, "**Severity:** "+project.Severity)
+	}
+	if len(project.Tags) > 0 {
+		parts = append(parts, "**Tags:** "+strings.Join(project.Tags, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// parseGithubBodyTemplate renders an issue body for the project. codeOwnerTeams are mentioned in
+// it.
+func parseGithubBodyTemplate(project drift.DriftProjectResult, bodyTemplate string, codeOwnerTeams []string) (*string, error) {
 	projectKind := types.DriftIssueKind
 	if !project.Succeeded {
 		projectKind = types.ErrorIssueKind
 	}
 
 	ghProject := types.GHProject{
-		Project: models.Project{
-			Dir: project.Project.Dir,
-		},
-		Kind: projectKind,
+		Project: project.Project.AsProject(),
+		Kind:    projectKind,
 	}
 
 	projectJson, err := json.Marshal(ghProject)
@@ -88,10 +127,18 @@ func parseGithubBodyTemplate(project drift.DriftProjectResult, bodyTemplate stri
 
 	templateArgs := struct {
 		ProjectDir  string
+		Metadata    string
+		CodeOwners  string
+		DriftSource string
+		Attempts    int
 		Output      string
 		ProjectJSON string
 	}{
-		ProjectDir:  project.Project.Dir,
+		ProjectDir:  project.Project.Key(),
+		Metadata:    metadataText(project.Project),
+		CodeOwners:  strings.Join(codeOwnerTeams, " "),
+		DriftSource: driftSourceText(project.DriftSource),
+		Attempts:    project.Attempts,
 		Output:      utils.TruncateBytes(output, maxIssueBodySize),
 		ProjectJSON: string(projectJson),
 	}
@@ -160,7 +207,7 @@ func (g *GithubIssueNotification) HandleIssues(ctx context.Context,
 	for _, project := range allDriftiveOpenIssues {
 		if project.Kind == types.DriftIssueKind {
 			for _, projectResult := range driftResult.ProjectResults {
-				if !projectResult.Drifted && projectResult.Succeeded && project.Project.Dir == projectResult.Project.Dir {
+				if !projectResult.Drifted && projectResult.Succeeded && project.Project.Key() == projectResult.Project.Key() {
 					closeableDriftIssues = append(closeableDriftIssues, project)
 				}
 			}
@@ -171,7 +218,7 @@ func (g *GithubIssueNotification) HandleIssues(ctx context.Context,
 	for _, project := range allDriftiveOpenIssues {
 		if project.Kind == types.ErrorIssueKind {
 			for _, projectResult := range driftResult.ProjectResults {
-				if projectResult.Succeeded && project.Project.Dir == projectResult.Project.Dir {
+				if projectResult.Succeeded && project.Project.Key() == projectResult.Project.Key() {
 					closeableErrorIssues = append(closeableErrorIssues, project)
 				}
 			}
@@ -187,18 +234,20 @@ func (g *GithubIssueNotification) HandleIssues(ctx context.Context,
 	// Create issues for drifted projects
 	for _, projectResult := range driftResult.ProjectResults {
 		if projectResult.Drifted && !projectResult.SkippedDueToPR {
-			issueBody, err := parseGithubBodyTemplate(projectResult, issueBodyTemplate)
+			assignees, teams := splitOwners(g.codeOwners.Owners(projectResult.Project.Dir))
+			issueBody, err := parseGithubBodyTemplate(projectResult, issueBodyTemplate, teams)
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to parse github issue description template")
 				continue
 			}
 
 			issue := types.GithubIssue{
-				Title:   fmt.Sprintf(issueTitleFormat, projectResult.Project.Dir),
-				Body:    *issueBody,
-				Labels:  g.repoConfig.GitHub.Issues.Labels,
-				Project: projectResult.Project,
-				Kind:    types.DriftIssueKind,
+				Title:     fmt.Sprintf(issueTitleFormat, projectResult.Project.Key()),
+				Body:      *issueBody,
+				Labels:    driftIssueLabels(g.repoConfig.GitHub.Issues.Labels, projectResult.Project.Settings.Labels),
+				Assignees: assignees,
+				Project:   projectResult.Project,
+				Kind:      types.DriftIssueKind,
 			}
 			createOrUpdateResult := g.scm.CreateOrUpdateIssue(
 				ctx,
@@ -209,18 +258,16 @@ func (g *GithubIssueNotification) HandleIssues(ctx context.Context,
 			if createOrUpdateResult.Created {
 				numOpenDriftIssues++
 				newlyCreatedIssues = append(newlyCreatedIssues, types.ProjectIssue{
-					Issue: *createOrUpdateResult.Issue,
-					Project: models.Project{
-						Dir: projectResult.Project.Dir,
-					},
-					Kind: types.DriftIssueKind,
+					Issue:   *createOrUpdateResult.Issue,
+					Project: projectResult.Project.AsProject(),
+					Kind:    types.DriftIssueKind,
 				})
 			}
 			if createOrUpdateResult.RateLimited {
-				rateLimitedProjectDirs = append(rateLimitedProjectDirs, projectResult.Project.Dir)
+				rateLimitedProjectDirs = append(rateLimitedProjectDirs, projectResult.Project.Key())
 			}
 		} else if projectResult.Drifted && projectResult.SkippedDueToPR {
-			log.Info().Msgf("Skipping drift notification for %s due to open PRs", projectResult.Project.Dir)
+			log.Info().Msgf("Skipping drift notification for %s due to open PRs", projectResult.Project.Key())
 		}
 	}
 
@@ -232,19 +279,22 @@ func (g *GithubIssueNotification) HandleIssues(ctx context.Context,
 	var rateLimitedErrorDirs []string
 	if g.repoConfig.GitHub.Issues.Errors.Enabled {
 		for _, projectResult := range driftResult.ProjectResults {
-			if !projectResult.Succeeded {
-				issueBody, err := parseGithubBodyTemplate(projectResult, errorIssueBodyTemplate)
+			// A blocked project did not fail itself; the issue of the upstream project covers it.
+			if !projectResult.Succeeded && projectResult.FailureReason != drift.ReasonBlocked {
+				assignees, teams := splitOwners(g.codeOwners.Owners(projectResult.Project.Dir))
+				issueBody, err := parseGithubBodyTemplate(projectResult, errorIssueBodyTemplate, teams)
 				if err != nil {
 					log.Error().Err(err).Msg("Failed to parse github issue description template")
 					continue
 				}
 
 				issue := types.GithubIssue{
-					Title:   fmt.Sprintf(errorIssueTitleFormat, projectResult.Project.Dir),
-					Body:    *issueBody,
-					Labels:  g.repoConfig.GitHub.Issues.Errors.Labels,
-					Project: projectResult.Project,
-					Kind:    types.ErrorIssueKind,
+					Title:     fmt.Sprintf(errorIssueTitleFormat, projectResult.Project.Key()),
+					Body:      *issueBody,
+					Labels:    g.repoConfig.GitHub.Issues.Errors.Labels,
+					Assignees: assignees,
+					Project:   projectResult.Project,
+					Kind:      types.ErrorIssueKind,
 				}
 				createOrUpdateResult := g.scm.CreateOrUpdateIssue(
 					ctx,
@@ -255,15 +305,13 @@ func (g *GithubIssueNotification) HandleIssues(ctx context.Context,
 				if createOrUpdateResult.Created {
 					numOpenErrorIssues++
 					newlyCreatedIssues = append(newlyCreatedIssues, types.ProjectIssue{
-						Issue: *createOrUpdateResult.Issue,
-						Project: models.Project{
-							Dir: projectResult.Project.Dir,
-						},
-						Kind: types.ErrorIssueKind,
+						Issue:   *createOrUpdateResult.Issue,
+						Project: projectResult.Project.AsProject(),
+						Kind:    types.ErrorIssueKind,
 					})
 				}
 				if createOrUpdateResult.RateLimited {
-					rateLimitedErrorDirs = append(rateLimitedErrorDirs, projectResult.Project.Dir)
+					rateLimitedErrorDirs = append(rateLimitedErrorDirs, projectResult.Project.Key())
 				}
 			}
 		}
@@ -283,6 +331,21 @@ func (g *GithubIssueNotification) HandleIssues(ctx context.Context,
 	}, nil
 }
 
+// driftIssueLabels returns the repository-wide drift issue labels followed by the project's own,
+// from its driftive.yml fragments, without duplicates.
+func driftIssueLabels(repoLabels, projectLabels []string) []string {
+	if len(projectLabels) == 0 {
+		return repoLabels
+	}
+	labels := make([]string, 0, len(repoLabels)+len(projectLabels))
+	for _, label := range append(slices.Clip(repoLabels), projectLabels...) {
+		if !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
 func filterIssuesByKind(allIssues []types.ProjectIssue, kind string) []types.ProjectIssue {
 	var issues []types.ProjectIssue
 	for _, issue := range allIssues {
@@ -25,7 +25,7 @@ func erroredResult(dir, phase, initOutput, planOutput string) drift.DriftProject
 func TestErrorIssueBodyUsesInitOutputWhenInitFailed(t *testing.T) {
 	result := erroredResult("infra/prod", drift.PhaseInit, "Error: Failed to install provider", "")
 
-	body, err := parseGithubBodyTemplate(result, errorIssueBodyTemplate)
+	body, err := parseGithubBodyTemplate(result, errorIssueBodyTemplate, nil)
 	if err != nil {
 		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
 	}
@@ -38,7 +38,7 @@ func TestErrorIssueBodyUsesInitOutputWhenInitFailed(t *testing.T) {
 func TestErrorIssueBodyUsesPlanOutputWhenPlanFailed(t *testing.T) {
 	result := erroredResult("infra/prod", drift.PhasePlan, "", "Planning failed. Terraform encountered an error")
 
-	body, err := parseGithubBodyTemplate(result, errorIssueBodyTemplate)
+	body, err := parseGithubBodyTemplate(result, errorIssueBodyTemplate, nil)
 	if err != nil {
 		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
 	}
@@ -58,7 +58,7 @@ func TestDriftIssueBodyStillUsesPlanOutput(t *testing.T) {
 		PlanOutput: "Plan: 1 to add, 0 to change, 0 to destroy.",
 	}
 
-	body, err := parseGithubBodyTemplate(result, issueBodyTemplate)
+	body, err := parseGithubBodyTemplate(result, issueBodyTemplate, nil)
 	if err != nil {
 		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
 	}
@@ -85,7 +85,7 @@ func TestIssueBodyKindIsErrorWhenSucceededFalse(t *testing.T) {
 		PlanOutput:  "Planning failed.",
 	}
 
-	body, err := parseGithubBodyTemplate(result, errorIssueBodyTemplate)
+	body, err := parseGithubBodyTemplate(result, errorIssueBodyTemplate, nil)
 	if err != nil {
 		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
 	}
@@ -102,7 +102,7 @@ func TestIssueBodyTruncationStaysValidUTF8(t *testing.T) {
 	oversized := strings.Repeat("╷", 70000/3)
 	result := erroredResult("infra/prod", drift.PhasePlan, "", oversized)
 
-	body, err := parseGithubBodyTemplate(result, errorIssueBodyTemplate)
+	body, err := parseGithubBodyTemplate(result, errorIssueBodyTemplate, nil)
 	if err != nil {
 		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
 	}
@@ -114,3 +114,82 @@ func TestIssueBodyTruncationStaysValidUTF8(t *testing.T) {
 		t.Error("truncated issue body lost its project metadata marker")
 	}
 }
+
+func TestDriftIssueBodyNamesTheDriftSource(t *testing.T) {
+	result := drift.DriftProjectResult{
+		Project:     models.TypedProject{Dir: "infra/prod", Type: models.Terraform},
+		Drifted:     true,
+		Succeeded:   true,
+		PlanOutput:  "Plan: 0 to add, 1 to change, 0 to destroy.",
+		DriftSource: drift.DriftSourceExternal,
+	}
+
+	body, err := parseGithubBodyTemplate(result, issueBodyTemplate, nil)
+	if err != nil {
+		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
+	}
+
+	if !strings.Contains(*body, "changed outside Terraform") {
+		t.Errorf("drift issue body should name the drift source:\n%s", *body)
+	}
+
+	result.DriftSource = ""
+	body, err = parseGithubBodyTemplate(result, issueBodyTemplate, nil)
+	if err != nil {
+		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
+	}
+	if strings.Contains(*body, "**Source:**") {
+		t.Errorf("plan-mode issue body should not mention a source:\n%s", *body)
+	}
+}
+
+func TestErrorIssueBodyNotesRetriedAttempts(t *testing.T) {
+	result := erroredResult("infra/prod", drift.PhasePlan, "", "Error: Error acquiring the state lock")
+	result.Attempts = 3
+
+	body, err := parseGithubBodyTemplate(result, errorIssueBodyTemplate, nil)
+	if err != nil {
+		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
+	}
+	if !strings.Contains(*body, "Error in project: infra/prod\n\nFailed after 3 attempts.\n") {
+		t.Errorf("error issue body should note the attempts:\n%s", *body)
+	}
+
+	result.Attempts = 1
+	body, err = parseGithubBodyTemplate(result, errorIssueBodyTemplate, nil)
+	if err != nil {
+		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
+	}
+	if strings.Contains(*body, "attempts") {
+		t.Errorf("a single attempt should not be noted:\n%s", *body)
+	}
+}
+
+func TestIssueBodyListsProjectMetadata(t *testing.T) {
+	result := drift.DriftProjectResult{
+		Project: models.TypedProject{Dir: "prod/payments", Name: "payments", Owners: []string{"@acme/payments"},
+			Severity: "critical", Tags: []string{"pci"}},
+		Drifted:   true,
+		Succeeded: true,
+	}
+
+	body, err := parseGithubBodyTemplate(result, issueBodyTemplate, nil)
+	if err != nil {
+		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
+	}
+
+	want := "**Name:** payments · **Owners:** @acme/payments · **Severity:** critical · **Tags:** pci"
+	if !strings.Contains(*body, want) {
+		t.Errorf("issue body is missing the project metadata:\n%s", *body)
+	}
+}
+
+func TestIssueBodyWithoutMetadataIsUnchanged(t *testing.T) {
+	body, err := parseGithubBodyTemplate(erroredResult("infra/prod", drift.PhasePlan, "", "boom"), errorIssueBodyTemplate, nil)
+	if err != nil {
+		t.Fatalf("parseGithubBodyTemplate() error = %v", err)
+	}
+	if !strings.HasPrefix(*body, "Error in project: infra/prod\n\n<details>") {
+		t.Errorf("unexpected body:\n%s", *body)
+	}
+}
@@ -70,7 +70,7 @@ func TestDriftIssueRateLimitRecorded(t *testing.T) {
 	}
 }
 
-func TestIssueNumbersByDirSplitsByKind(t *testing.T) {
+func TestIssueNumbersByProjectSplitsByKind(t *testing.T) {
 	state := &types.GithubState{
 		DriftIssuesOpen: []types.ProjectIssue{
 			{Project: models.Project{Dir: "infra/a"}, Issue: vcstypes.VCSIssue{Number: 1}, Kind: types.DriftIssueKind},
@@ -81,21 +81,35 @@ func TestIssueNumbersByDirSplitsByKind(t *testing.T) {
 		},
 	}
 
-	drifts := state.IssueNumbersByDir(types.DriftIssueKind)
+	drifts := state.IssueNumbersByProject(types.DriftIssueKind)
 	if len(drifts) != 2 || drifts["infra/a"] != 1 || drifts["infra/b"] != 2 {
 		t.Errorf("drift issue numbers = %v", drifts)
 	}
 
-	errored := state.IssueNumbersByDir(types.ErrorIssueKind)
+	errored := state.IssueNumbersByProject(types.ErrorIssueKind)
 	if len(errored) != 1 || errored["infra/c"] != 3 {
 		t.Errorf("error issue numbers = %v", errored)
 	}
 }
 
-func TestIssueNumbersByDirNilStateIsSafe(t *testing.T) {
+func TestIssueNumbersByProjectNilStateIsSafe(t *testing.T) {
 	var state *types.GithubState
 
-	if got := state.IssueNumbersByDir(types.DriftIssueKind); got != nil {
-		t.Errorf("IssueNumbersByDir() on nil state = %v, want nil", got)
+	if got := state.IssueNumbersByProject(types.DriftIssueKind); got != nil {
+		t.Errorf("IssueNumbersByProject() on nil state = %v, want nil", got)
+	}
+}
+
+func TestDriftIssueLabelsAddTheProjectsOwn(t *testing.T) {
+	repoLabels := []string{"drift", "infra"}
+	if got := driftIssueLabels(repoLabels, nil); !slices.Equal(got, repoLabels) {
+		t.Errorf("driftIssueLabels() = %v, want the repository labels", got)
+	}
+	got := driftIssueLabels(repoLabels, []string{"team-a", "drift"})
+	if want := []string{"drift", "infra", "team-a"}; !slices.Equal(got, want) {
+		t.Errorf("driftIssueLabels() = %v, want %v", got, want)
+	}
+	if !slices.Equal(repoLabels, []string{"drift", "infra"}) {
+		t.Errorf("repository labels were modified: %v", repoLabels)
 	}
 }
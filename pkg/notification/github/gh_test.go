@@ -9,6 +9,8 @@ import (
 	"driftive/pkg/models"
 	"driftive/pkg/notification/github/types"
 	"driftive/pkg/vcs/vcstypes"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -402,3 +404,89 @@ func TestGetProjectFromIssueBody_InvalidJSON(t *testing.T) {
 		t.Error("expected error for invalid JSON")
 	}
 }
+
+// TestResolvedWorkspaceOnlyClosesItsOwnIssue covers two workspaces of one dir: each has its own
+// issue, and a clean plan of one must leave the other's issue open.
+func TestResolvedWorkspaceOnlyClosesItsOwnIssue(t *testing.T) {
+	mock := &mockVCS{}
+	n := newNotification(mock, true, true)
+
+	openIssues := []*vcstypes.VCSIssue{
+		{Number: 1, Title: "drift detected: infra/app@staging",
+			Body: "<!--PROJECT_JSON_START-->{\"project\":{\"dir\":\"infra/app\",\"workspace\":\"staging\"},\"kind\":\"drift\"}<!--PROJECT_JSON_END-->"},
+		{Number: 2, Title: "drift detected: infra/app@prod",
+			Body: "<!--PROJECT_JSON_START-->{\"project\":{\"dir\":\"infra/app\",\"workspace\":\"prod\"},\"kind\":\"drift\"}<!--PROJECT_JSON_END-->"},
+	}
+
+	results := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{
+			{Project: models.TypedProject{Dir: "infra/app", Workspace: "staging"}, Drifted: false, Succeeded: true},
+			{Project: models.TypedProject{Dir: "infra/app", Workspace: "prod"}, Drifted: true, Succeeded: true},
+		},
+	}
+
+	if _, err := n.HandleIssues(context.Background(), results, openIssues); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mock.closedIssueNumbers) != 1 || mock.closedIssueNumbers[0] != 1 {
+		t.Errorf("expected only the staging issue closed, got %v", mock.closedIssueNumbers)
+	}
+	if len(mock.createOrUpdateCalls) != 1 || mock.createOrUpdateCalls[0].Title != "drift detected: infra/app@prod" {
+		t.Errorf("expected the prod issue to be titled by dir and workspace, got %+v", mock.createOrUpdateCalls)
+	}
+}
+
+func TestBlockedProjectGetsNoErrorIssue(t *testing.T) {
+	mock := &mockVCS{}
+	n := newNotification(mock, true, true)
+
+	results := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{
+			{Project: models.TypedProject{Dir: "live/vpc"}, FailedPhase: drift.PhasePlan},
+			{Project: models.TypedProject{Dir: "live/app"}, FailureReason: drift.ReasonBlocked, BlockedBy: "live/vpc"},
+		},
+	}
+
+	if _, err := n.HandleIssues(context.Background(), results, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mock.createOrUpdateCalls) != 1 || mock.createOrUpdateCalls[0].Project.Dir != "live/vpc" {
+		t.Errorf("expected a single error issue for live/vpc, got %+v", mock.createOrUpdateCalls)
+	}
+}
+
+func TestIssuesAreAssignedToCodeOwners(t *testing.T) {
+	mock := &mockVCS{}
+	n := newNotification(mock, true, true)
+	n.codeOwners = ParseCodeOwners([]byte("/infra/ @alice @acme/platform ops@example.com\n"))
+
+	results := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{
+			{Project: models.TypedProject{Dir: "infra/prod"}, Drifted: true, Succeeded: true},
+			{Project: models.TypedProject{Dir: "infra/staging"}, Succeeded: false},
+			{Project: models.TypedProject{Dir: "apps/web"}, Drifted: true, Succeeded: true},
+		},
+	}
+
+	if _, err := n.HandleIssues(context.Background(), results, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mock.createOrUpdateCalls) != 3 {
+		t.Fatalf("expected 3 issues, got %d", len(mock.createOrUpdateCalls))
+	}
+	for _, issue := range mock.createOrUpdateCalls {
+		owned := issue.Project.Dir != "apps/web"
+		if owned && !reflect.DeepEqual(issue.Assignees, []string{"alice"}) {
+			t.Errorf("%s: expected assignees [alice], got %v", issue.Project.Dir, issue.Assignees)
+		}
+		if owned != strings.Contains(issue.Body, "**Code owners:** @acme/platform") {
+			t.Errorf("%s: unexpected code owners mention in body:\n%s", issue.Project.Dir, issue.Body)
+		}
+		if !owned && issue.Assignees != nil {
+			t.Errorf("%s: expected no assignees, got %v", issue.Project.Dir, issue.Assignees)
+		}
+	}
+}
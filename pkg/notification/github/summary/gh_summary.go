@@ -30,8 +30,22 @@ type SummaryProject struct {
 	IssueNumber int `json:"issue_number,omitempty"`
 	// RateLimited is true when an issue was wanted but max_open_issues blocked creation.
 	RateLimited bool `json:"rate_limited,omitempty"`
-	// FailedPhase is drift.PhaseInit or drift.PhasePlan; set only on errored projects.
+	// FailedPhase is drift.PhaseInit or drift.PhasePlan; set only on errored and timed out
+	// projects.
 	FailedPhase string `json:"failed_phase,omitempty"`
+	// Changes is the resource breakdown, e.g. "3 updates, 1 replace in module.vpc"; set only on
+	// drifted projects.
+	Changes string `json:"changes,omitempty"`
+	// BlockedBy is the failed project that blocked this one; set only on blocked projects.
+	BlockedBy string `json:"blocked_by,omitempty"`
+}
+
+// ChangesCell renders the Changes column.
+func (p SummaryProject) ChangesCell() string {
+	if p.Changes == "" {
+		return "—"
+	}
+	return strings.ReplaceAll(p.Changes, "|", "\\|")
 }
 
 // DirCell renders the Project column. GFM splits table rows on "|" before inline parsing, so a
@@ -40,6 +54,11 @@ func (p SummaryProject) DirCell() string {
 	return "`" + strings.ReplaceAll(p.Dir, "|", "\\|") + "`"
 }
 
+// BlockedByCell renders the Blocked by column.
+func (p SummaryProject) BlockedByCell() string {
+	return "`" + strings.ReplaceAll(p.BlockedBy, "|", "\\|") + "`"
+}
+
 // IssueLink renders the Issue column.
 func (p SummaryProject) IssueLink() string {
 	if p.IssueNumber > 0 {
@@ -55,12 +74,20 @@ type GithubSummary struct {
 	TotalProjects int `json:"total_projects"`
 	NumDrifted    int `json:"num_drifted"`
 	NumErrored    int `json:"num_errored"`
+	NumTimedOut   int `json:"num_timed_out,omitempty"`
+	NumBlocked    int `json:"num_blocked,omitempty"`
 	NumSkipped    int `json:"num_skipped"`
 	NumClean      int `json:"num_clean"`
 	NumNotChecked int `json:"num_not_checked,omitempty"`
+	// ResourceChanges totals the drifted resources by action, e.g. "3 updates, 1 replace".
+	ResourceChanges string `json:"resource_changes,omitempty"`
 
 	Drifted []SummaryProject `json:"drifted,omitempty"`
 	Errored []SummaryProject `json:"errored,omitempty"`
+	// TimedOut are failed projects killed for running past their timeout.
+	TimedOut []SummaryProject `json:"timed_out,omitempty"`
+	// Blocked are projects not analyzed because a project they depend on failed.
+	Blocked []SummaryProject `json:"blocked,omitempty"`
 	Skipped []SummaryProject `json:"skipped,omitempty"`
 	// OtherIssues are open driftive issues not represented above: the project was not part of
 	// this run, or it came back clean while close_resolved is off.
@@ -73,11 +100,11 @@ type GithubSummary struct {
 }
 
 func (s GithubSummary) HasFindings() bool {
-	return len(s.Drifted) > 0 || len(s.Errored) > 0
+	return len(s.Drifted) > 0 || len(s.Errored) > 0 || len(s.TimedOut) > 0
 }
 
 func (s GithubSummary) HasRateLimited() bool {
-	for _, group := range [][]SummaryProject{s.Drifted, s.Errored} {
+	for _, group := range [][]SummaryProject{s.Drifted, s.Errored, s.TimedOut} {
 		for _, p := range group {
 			if p.RateLimited {
 				return true
@@ -111,7 +138,7 @@ func NewGithubSummaryHandler(
 }
 
 // buildSummary converts a run's results plus the post-run issue state into the summary model.
-// The tables are driven by the run, with issue numbers joined in by project dir; open issues
+// The tables are driven by the run, with issue numbers joined in by project key; open issues
 // with no matching result land in OtherIssues rather than disappearing.
 func buildSummary(
 	driftResult drift.DriftDetectionResult,
@@ -121,8 +148,8 @@ func buildSummary(
 ) GithubSummary {
 	classified := report.Classify(driftResult)
 
-	driftIssues := state.IssueNumbersByDir(driftiveGithub.DriftIssueKind)
-	errorIssues := state.IssueNumbersByDir(driftiveGithub.ErrorIssueKind)
+	driftIssues := state.IssueNumbersByProject(driftiveGithub.DriftIssueKind)
+	errorIssues := state.IssueNumbersByProject(driftiveGithub.ErrorIssueKind)
 
 	var rateLimitedDrifts, rateLimitedErrors []string
 	if state != nil {
@@ -134,18 +161,23 @@ func buildSummary(
 		TotalProjects:       classified.TotalProjects,
 		NumDrifted:          classified.NumDrifted(),
 		NumErrored:          classified.NumErrored(),
+		NumTimedOut:         classified.NumTimedOut(),
+		NumBlocked:          classified.NumBlocked(),
 		NumSkipped:          classified.NumSkipped(),
 		NumClean:            classified.NumClean(),
 		NumNotChecked:       classified.NotChecked,
+		ResourceChanges:     classified.Changes.String(),
 		Drifted:             toRows(classified.Drifted, driftIssues, rateLimitedDrifts),
 		Errored:             toRows(classified.Errored, errorIssues, rateLimitedErrors),
+		TimedOut:            toRows(classified.TimedOut, errorIssues, rateLimitedErrors),
+		Blocked:             toRows(classified.Blocked, nil, nil),
 		Skipped:             toRows(classified.Skipped, nil, nil),
 		LastAnalysisDate:    now.Format(time.RFC3339),
 		LastAnalysisDisplay: now.UTC().Format("2006-01-02 15:04 UTC"),
 		Duration:            classified.DurationText(),
 		DashboardURL:        dashboardURL,
 	}
-	summary.OtherIssues = otherIssues(state, summary.Drifted, summary.Errored)
+	summary.OtherIssues = otherIssues(state, summary.Drifted, summary.Errored, summary.TimedOut)
 
 	return summary
 }
@@ -166,6 +198,8 @@ func toRows(projects []report.Project, issues map[string]int, rateLimited []stri
 			IssueNumber: issues[p.Dir],
 			RateLimited: limited[p.Dir],
 			FailedPhase: p.FailedPhase,
+			Changes:     p.ChangeText(),
+			BlockedBy:   p.BlockedBy,
 		})
 	}
 	return rows
@@ -186,11 +220,11 @@ func otherIssues(state *driftiveGithub.GithubState, reported ...[]SummaryProject
 	var rows []SummaryProject
 	for _, group := range [][]driftiveGithub.ProjectIssue{state.DriftIssuesOpen, state.ErrorIssuesOpen} {
 		for _, issue := range group {
-			if seen[issue.Project.Dir] {
+			if seen[issue.Project.Key()] {
 				continue
 			}
-			seen[issue.Project.Dir] = true
-			rows = append(rows, SummaryProject{Dir: issue.Project.Dir, IssueNumber: issue.Issue.Number})
+			seen[issue.Project.Key()] = true
+			rows = append(rows, SummaryProject{Dir: issue.Project.Key(), IssueNumber: issue.Issue.Number})
 		}
 	}
 	sort.Slice(rows, func(i, j int) bool { return rows[i].Dir < rows[j].Dir })
@@ -43,6 +43,11 @@ func projectResult(dir string, drifted, succeeded, skipped bool, phase string) d
 	}
 }
 
+func withResources(r drift.DriftProjectResult, resources ...drift.DriftedResource) drift.DriftProjectResult {
+	r.DriftedResources = resources
+	return r
+}
+
 func projectIssue(dir string, number int, kind string) types.ProjectIssue {
 	return types.ProjectIssue{
 		Project: models.Project{Dir: dir},
@@ -55,7 +60,11 @@ func projectIssue(dir string, number int, kind string) types.ProjectIssue {
 func fullRun() (drift.DriftDetectionResult, *types.GithubState) {
 	result := drift.DriftDetectionResult{
 		ProjectResults: []drift.DriftProjectResult{
-			projectResult("infra/prod/vpc", true, true, false, ""),
+			withResources(projectResult("infra/prod/vpc", true, true, false, ""),
+				drift.DriftedResource{Address: "module.vpc.aws_subnet.a", ModulePath: "module.vpc", Action: drift.ActionUpdate},
+				drift.DriftedResource{Address: "module.vpc.aws_subnet.b", ModulePath: "module.vpc", Action: drift.ActionUpdate},
+				drift.DriftedResource{Address: "module.vpc.aws_nat_gateway.a", ModulePath: "module.vpc", Action: drift.ActionReplace},
+			),
 			projectResult("infra/prod/rds", true, true, false, ""),
 			projectResult("infra/stg/eks", true, true, false, ""),
 			projectResult("infra/prod/iam", false, false, false, drift.PhasePlan),
@@ -151,7 +160,7 @@ func TestSummaryPreservesStateBlock(t *testing.T) {
 	}
 }
 
-func TestBuildSummaryJoinsIssueNumbersByDir(t *testing.T) {
+func TestBuildSummaryJoinsIssueNumbersByProject(t *testing.T) {
 	result, state := fullRun()
 	summary := buildSummary(result, state, "", analysisTime)
 
@@ -226,6 +235,65 @@ func TestBuildSummaryFailedPhaseColumn(t *testing.T) {
 	}
 }
 
+func TestBuildSummaryTimedOutTable(t *testing.T) {
+	timedOut := projectResult("infra/prod/eks", false, false, false, drift.PhasePlan)
+	timedOut.FailureReason = drift.ReasonTimeout
+	result := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{timedOut, projectResult("infra/prod/iam", false, false, false, drift.PhaseInit)},
+		TotalProjects:  2,
+	}
+	state := &types.GithubState{
+		ErrorIssuesOpen: []types.ProjectIssue{projectIssue("infra/prod/eks", 140, types.ErrorIssueKind)},
+	}
+
+	summary := buildSummary(result, state, "", analysisTime)
+
+	if summary.NumTimedOut != 1 || summary.NumErrored != 1 {
+		t.Fatalf("timed out = %d, errored = %d, want 1 and 1", summary.NumTimedOut, summary.NumErrored)
+	}
+	if row := summary.TimedOut[0]; row.Dir != "infra/prod/eks" || row.IssueNumber != 140 || row.FailedPhase != drift.PhasePlan {
+		t.Errorf("unexpected timed out row: %+v", row)
+	}
+	if len(summary.OtherIssues) != 0 {
+		t.Errorf("timed out project's issue should not be listed as other: %+v", summary.OtherIssues)
+	}
+
+	body, err := getSummaryIssueBody(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"⏱️ 1 timed out", "## ⏱️ Timed out (1)", "| `infra/prod/eks` | plan | [#140](../issues/140) |"} {
+		if !strings.Contains(*body, want) {
+			t.Errorf("body missing %q:\n%s", want, *body)
+		}
+	}
+}
+
+func TestBuildSummaryBlockedTable(t *testing.T) {
+	blocked := projectResult("live/app", false, false, false, "")
+	blocked.FailureReason = drift.ReasonBlocked
+	blocked.BlockedBy = "live/vpc"
+	result := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{blocked, projectResult("live/vpc", false, false, false, drift.PhasePlan)},
+		TotalProjects:  2,
+	}
+
+	summary := buildSummary(result, &types.GithubState{}, "", analysisTime)
+
+	if summary.NumBlocked != 1 || summary.NumErrored != 1 {
+		t.Fatalf("blocked = %d, errored = %d, want 1 and 1", summary.NumBlocked, summary.NumErrored)
+	}
+	body, err := getSummaryIssueBody(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"⛔ 1 blocked", "## ⛔ Blocked by upstream errors (1)", "| `live/app` | `live/vpc` |"} {
+		if !strings.Contains(*body, want) {
+			t.Errorf("body missing %q:\n%s", want, *body)
+		}
+	}
+}
+
 func TestBuildSummaryMovesUnmatchedIssuesToOtherIssues(t *testing.T) {
 	t.Run("project absent from the run", func(t *testing.T) {
 		result, state := fullRun()
@@ -23,11 +23,14 @@ type ProjectIssue struct {
 }
 
 type GithubIssue struct {
-	Title   string
-	Body    string
-	Labels  []string
-	Project models.TypedProject
-	Kind    string
+	Title  string
+	Body   string
+	Labels []string
+	// Assignees are the logins the issue is assigned to when created, e.g. the project's code
+	// owners. An existing issue keeps its assignees.
+	Assignees []string
+	Project   models.TypedProject
+	Kind      string
 }
 
 type GithubState struct {
@@ -37,13 +40,16 @@ type GithubState struct {
 	ErrorIssuesOpen     []ProjectIssue
 	ErrorIssuesResolved []ProjectIssue
 
+	// RateLimitedDrifts and RateLimitedErrors are the keys of projects whose issue was not
+	// created because the open issue cap was reached.
 	RateLimitedDrifts []string
 	RateLimitedErrors []string
 }
 
-// IssueNumbersByDir indexes the issues still open after this run by project dir, for the given
-// kind. Returns nil when there is no state, so callers can pass the result straight through.
-func (s *GithubState) IssueNumbersByDir(kind string) map[string]int {
+// IssueNumbersByProject indexes the issues still open after this run by project key (see
+// models.Project.Key), for the given kind. Returns nil when there is no state, so callers can
+// pass the result straight through.
+func (s *GithubState) IssueNumbersByProject(kind string) map[string]int {
 	if s == nil {
 		return nil
 	}
@@ -55,7 +61,7 @@ func (s *GithubState) IssueNumbersByDir(kind string) map[string]int {
 
 	numbers := make(map[string]int, len(issues))
 	for _, issue := range issues {
-		numbers[issue.Project.Dir] = issue.Issue.Number
+		numbers[issue.Project.Key()] = issue.Issue.Number
 	}
 	return numbers
 }
@@ -14,7 +14,7 @@ func filterIssues(issues []types.ProjectIssue, issuesToRemove []types.ProjectIss
 
 func containsIssue(issues []types.ProjectIssue, issue types.ProjectIssue) bool {
 	for _, i := range issues {
-		if i.Project.Dir == issue.Project.Dir && i.Kind == issue.Kind {
+		if i.Project.Key() == issue.Project.Key() && i.Kind == issue.Kind {
 			return true
 		}
 	}
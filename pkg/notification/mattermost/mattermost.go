@@ -0,0 +1,152 @@
+// Package mattermost posts drift reports to a Mattermost incoming webhook as a message
+// attachment.
+package mattermost
+
+import (
+	"context"
+	"driftive/pkg/drift"
+	"driftive/pkg/models/backend"
+	"driftive/pkg/notification/post"
+	"driftive/pkg/notification/report"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Attachment color constants, the same as Slack's
+const (
+	colorDanger  = "#E53E3E" // Red for drifts detected
+	colorWarning = "#ED8936" // Orange for errors without drift
+	colorSuccess = "#38A169" // Green for all resolved
+)
+
+// maxProjectListChars is the budget for one project list. Mattermost rejects posts over 16383
+// characters, so the up to four lists of the attachment text stay well under a quarter of it.
+const maxProjectListChars = 3000
+
+// Mattermost message attachment types
+type mattermostField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type mattermostAttachment struct {
+	Fallback  string            `json:"fallback"`
+	Color     string            `json:"color"`
+	Title     string            `json:"title"`
+	TitleLink string            `json:"title_link,omitempty"`
+	Text      string            `json:"text,omitempty"`
+	Fields    []mattermostField `json:"fields"`
+	Footer    string            `json:"footer"`
+}
+
+type mattermostMessage struct {
+	Username    string                 `json:"username"`
+	Attachments []mattermostAttachment `json:"attachments"`
+}
+
+type Mattermost struct {
+	Url          string
+	IssuesState  *backend.DriftIssuesState
+	DashboardURL string
+	// Repo is "owner/name" from the GitHub Actions context, used to identify the source
+	// repository and to build issue links. Empty outside GitHub Actions.
+	Repo string
+	// DriftIssues and ErrorIssues map a project key to its open GitHub issue number. Nil when
+	// GitHub issues are disabled, in which case rows render as plain text.
+	DriftIssues map[string]int
+	ErrorIssues map[string]int
+}
+
+func (mm Mattermost) Handle(ctx context.Context, driftResult drift.DriftDetectionResult) error {
+	summary := report.Classify(driftResult)
+
+	if !summary.ShouldNotify(mm.IssuesState) {
+		log.Info().Msg("No drifts or errors detected. Skipping mattermost notification")
+		return nil
+	}
+
+	return post.JSON(ctx, "mattermost", mm.Url, mm.buildMessage(summary))
+}
+
+func (mm Mattermost) buildMessage(summary report.Summary) mattermostMessage {
+	color, title := mm.headline(summary)
+
+	var sections []string
+	if report.IssuesResolved(mm.IssuesState) {
+		sections = append(sections, ":tada: "+report.Resolved(mm.IssuesState).Text(bold))
+	}
+	links := report.IssueLinks{Repo: mm.Repo, DriftIssues: mm.DriftIssues, ErrorIssues: mm.ErrorIssues}
+	for _, list := range summary.Lists(links) {
+		sections = append(sections, mm.renderProjectList("**"+list.Heading+"**", list.Lines, maxProjectListChars))
+	}
+	if mm.DashboardURL != "" {
+		sections = append(sections, fmt.Sprintf("[View in Dashboard](%s)", mm.DashboardURL))
+	}
+
+	return mattermostMessage{
+		Username: "Driftive",
+		Attachments: []mattermostAttachment{
+			{
+				Fallback:  summary.Synopsis(mm.Repo),
+				Color:     color,
+				Title:     title,
+				TitleLink: mm.DashboardURL,
+				Text:      strings.Join(sections, "\n\n"),
+				Fields:    mm.statsFields(summary),
+				Footer:    mm.footerText(),
+			},
+		},
+	}
+}
+
+func (mm Mattermost) headline(summary report.Summary) (color string, title string) {
+	switch summary.Headline(mm.IssuesState) {
+	case report.HeadlineDrift:
+		return colorDanger, ":warning: Drift Detected"
+	case report.HeadlineErrors:
+		return colorWarning, ":rotating_light: Analysis Errors"
+	case report.HeadlineResolved:
+		return colorSuccess, ":white_check_mark: All Drifts Resolved"
+	}
+	return "", ""
+}
+
+// statsFields are short, so Mattermost lays them out two per row.
+func (mm Mattermost) statsFields(summary report.Summary) []mattermostField {
+	stats := summary.Stats()
+	fields := make([]mattermostField, 0, len(stats))
+	for _, stat := range stats {
+		value := stat.Value
+		if stat.Detail != "" {
+			value += "\n" + stat.Detail
+		}
+		fields = append(fields, mattermostField{Title: stat.Name, Value: value, Short: true})
+	}
+	return fields
+}
+
+// renderProjectList builds a section of the attachment text: a bold heading followed by one
+// bullet per project, stopping before budget bytes and appending a "…and N more" suffix.
+func (mm Mattermost) renderProjectList(heading string, lines []report.Line, budget int) string {
+	format := report.ListFormat{Line: report.MarkdownLine, Truncated: mm.buildTruncationSuffix}
+	return strings.TrimSuffix(format.Render(heading+"\n", lines, budget), "\n")
+}
+
+func (mm Mattermost) buildTruncationSuffix(remaining int) string {
+	return "_" + report.TruncationText(remaining, mm.DashboardURL != "") + "_\n"
+}
+
+// footerText is plain text: Mattermost renders no markdown in attachment footers.
+func (mm Mattermost) footerText() string {
+	if mm.Repo == "" {
+		return "Detected by Driftive"
+	}
+	return mm.Repo + " · Detected by Driftive"
+}
+
+func bold(text string) string {
+	return "**" + text + "**"
+}
@@ -0,0 +1,149 @@
+package mattermost
+
+import (
+	"context"
+	"driftive/pkg/drift"
+	"driftive/pkg/models"
+	"driftive/pkg/models/backend"
+	"driftive/pkg/notification/report"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func drifted(dir string) drift.DriftProjectResult {
+	return drift.DriftProjectResult{Project: models.TypedProject{Dir: dir}, Drifted: true, Succeeded: true}
+}
+
+func clean(dir string) drift.DriftProjectResult {
+	return drift.DriftProjectResult{Project: models.TypedProject{Dir: dir}, Succeeded: true}
+}
+
+func errored(dir, phase string) drift.DriftProjectResult {
+	return drift.DriftProjectResult{Project: models.TypedProject{Dir: dir}, FailedPhase: phase}
+}
+
+func build(mm Mattermost, result drift.DriftDetectionResult) mattermostAttachment {
+	return mm.buildMessage(report.Classify(result)).Attachments[0]
+}
+
+func TestBuildMessage_WithDriftsAndErrors(t *testing.T) {
+	mm := Mattermost{
+		DashboardURL: "https://app.driftive.cloud/runs/1",
+		Repo:         "acme/infra",
+		ErrorIssues:  map[string]int{"terraform/db": 9},
+	}
+	attachment := build(mm, drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{
+			drifted("terraform/vpc"),
+			errored("terraform/db", drift.PhaseInit),
+			clean("terraform/iam"),
+		},
+		TotalProjects: 3,
+		Duration:      time.Minute,
+	})
+
+	if attachment.Color != colorDanger || attachment.Title != ":warning: Drift Detected" {
+		t.Errorf("unexpected headline %q (%s)", attachment.Title, attachment.Color)
+	}
+	if attachment.TitleLink != mm.DashboardURL {
+		t.Errorf("title link = %q", attachment.TitleLink)
+	}
+	wantText := "**Drifted Projects**\n- terraform/vpc\n\n" +
+		"**Failed Projects**\n- [terraform/db](https://github.com/acme/infra/issues/9) _(init)_\n\n" +
+		"[View in Dashboard](https://app.driftive.cloud/runs/1)"
+	if attachment.Text != wantText {
+		t.Errorf("text = %q\nwant %q", attachment.Text, wantText)
+	}
+	if len(attachment.Fields) != 3 || attachment.Fields[0].Value != "1 / 3 projects" || attachment.Fields[1].Value != "1" {
+		t.Errorf("unexpected fields: %+v", attachment.Fields)
+	}
+	if attachment.Fallback != "[acme/infra] Drift detected in 1 project(s), 1 failed to analyze" {
+		t.Errorf("fallback = %q", attachment.Fallback)
+	}
+	if attachment.Footer != "acme/infra · Detected by Driftive" {
+		t.Errorf("footer = %q", attachment.Footer)
+	}
+}
+
+func TestBuildMessage_AllResolved(t *testing.T) {
+	mm := Mattermost{IssuesState: &backend.DriftIssuesState{StateUpdated: true, NumResolvedErrorIssues: 1}}
+	attachment := build(mm, drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{clean("a")}, TotalProjects: 1})
+
+	if attachment.Color != colorSuccess || attachment.Text != ":tada: **1 error issue(s) resolved** since last analysis" {
+		t.Errorf("unexpected resolved attachment: %+v", attachment)
+	}
+}
+
+func TestBuildMessage_TruncatesLongLists(t *testing.T) {
+	result := drift.DriftDetectionResult{TotalProjects: 300}
+	for i := range 300 {
+		result.ProjectResults = append(result.ProjectResults, drifted(fmt.Sprintf("terraform/production/service-%03d", i)))
+	}
+
+	text := build(Mattermost{}, result).Text
+
+	if len(text) > maxProjectListChars {
+		t.Errorf("list is %d bytes, over the %d budget", len(text), maxProjectListChars)
+	}
+	shown := strings.Count(text, "- terraform/")
+	if !strings.HasSuffix(text, fmt.Sprintf("_...and %d more project(s)_", 300-shown)) {
+		t.Errorf("unexpected truncation suffix:\n%s", text[len(text)-100:])
+	}
+}
+
+func TestHandle_SkipsWhenOnlyClean(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("should not send request when nothing drifted, errored or resolved")
+	}))
+	defer server.Close()
+
+	mm := Mattermost{Url: server.URL}
+	result := drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{clean("a")}, TotalProjects: 1}
+	if err := mm.Handle(context.Background(), result); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHandle_SendsAttachment(t *testing.T) {
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	mm := Mattermost{Url: server.URL}
+	result := drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{errored("a", drift.PhasePlan)}, TotalProjects: 1}
+	if err := mm.Handle(context.Background(), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var message mattermostMessage
+	if err := json.Unmarshal(receivedBody, &message); err != nil {
+		t.Fatalf("failed to unmarshal sent message: %v", err)
+	}
+	if len(message.Attachments) != 1 || message.Username != "Driftive" {
+		t.Errorf("unexpected message: %+v", message)
+	}
+}
+
+func TestHandle_ReturnsErrorOnBadStatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"id":"web.incoming_webhook.text.app_error"}`))
+	}))
+	defer server.Close()
+
+	mm := Mattermost{Url: server.URL}
+	result := drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{drifted("a")}, TotalProjects: 1}
+	err := mm.Handle(context.Background(), result)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("expected an error with the status code, got: %v", err)
+	}
+}
@@ -7,10 +7,14 @@ import (
 	"driftive/pkg/drift"
 	"driftive/pkg/models/backend"
 	"driftive/pkg/notification/console"
+	"driftive/pkg/notification/discord"
 	"driftive/pkg/notification/driftive"
 	"driftive/pkg/notification/github"
 	"driftive/pkg/notification/github/types"
+	"driftive/pkg/notification/mattermost"
 	"driftive/pkg/notification/slack"
+	"driftive/pkg/notification/teams"
+	"driftive/pkg/notification/webhook"
 	"driftive/pkg/vcs"
 	"github.com/rs/zerolog/log"
 )
@@ -19,16 +23,19 @@ type NotificationHandler struct {
 	repoConfig     *repo.DriftiveRepoConfig
 	driftiveConfig *config.DriftiveConfig
 	vcs            vcs.VCS
+	// repoDir is the checkout the analysis ran on, read for its CODEOWNERS.
+	repoDir string
 	// runKey is the Idempotency-Key for this CLI run, shared with the live reporter so the
 	// terminal upload completes the run the reporter has been filling in.
 	runKey string
 }
 
-func NewNotificationHandler(driftiveConfig *config.DriftiveConfig, repoConfig *repo.DriftiveRepoConfig, vcs vcs.VCS, runKey string) *NotificationHandler {
+func NewNotificationHandler(driftiveConfig *config.DriftiveConfig, repoConfig *repo.DriftiveRepoConfig, vcs vcs.VCS, repoDir, runKey string) *NotificationHandler {
 	return &NotificationHandler{
 		repoConfig:     repoConfig,
 		driftiveConfig: driftiveConfig,
 		vcs:            vcs,
+		repoDir:        repoDir,
 		runKey:         runKey,
 	}
 }
@@ -79,6 +86,10 @@ func (h *NotificationHandler) HandleNotifications(ctx context.Context, analysisR
 	githubStatus := notifierSkipped
 	stdoutStatus := notifierSkipped
 	slackStatus := notifierSkipped
+	teamsStatus := notifierSkipped
+	discordStatus := notifierSkipped
+	mattermostStatus := notifierSkipped
+	webhookStatus := notifierSkipped
 
 	// Send to Driftive API first to get the dashboard URL for other notifications
 	var dashboardURL string
@@ -100,7 +111,7 @@ func (h *NotificationHandler) HandleNotifications(ctx context.Context, analysisR
 
 	if h.repoConfig.GitHub.Issues.Enabled && h.driftiveConfig.GithubToken != "" && h.driftiveConfig.GithubContext != nil {
 		log.Info().Msg("Updating Github issues...")
-		gh, err := github.NewGithubIssueNotification(h.driftiveConfig, h.repoConfig, h.vcs, dashboardURL)
+		gh, err := github.NewGithubIssueNotification(h.driftiveConfig, h.repoConfig, h.vcs, dashboardURL, github.LoadCodeOwners(h.repoDir))
 		if err != nil {
 			githubStatus = notifierFailed
 			log.Error().Err(err).Msg("Failed to construct github issues notifier")
@@ -135,8 +146,8 @@ func (h *NotificationHandler) HandleNotifications(ctx context.Context, analysisR
 			IssuesState:  issuesState,
 			DashboardURL: dashboardURL,
 			Repo:         repoSlug(h.driftiveConfig),
-			DriftIssues:  ghState.IssueNumbersByDir(types.DriftIssueKind),
-			ErrorIssues:  ghState.IssueNumbersByDir(types.ErrorIssueKind),
+			DriftIssues:  ghState.IssueNumbersByProject(types.DriftIssueKind),
+			ErrorIssues:  ghState.IssueNumbersByProject(types.ErrorIssueKind),
 		}
 		err := slackNotification.Handle(ctx, analysisResult)
 		if err != nil {
@@ -147,10 +158,86 @@ func (h *NotificationHandler) HandleNotifications(ctx context.Context, analysisR
 		}
 	}
 
+	if h.driftiveConfig.TeamsWebhookUrl != "" {
+		log.Info().Msg("Sending notification to teams...")
+		teamsNotification := teams.Teams{
+			Url:          h.driftiveConfig.TeamsWebhookUrl,
+			IssuesState:  issuesState,
+			DashboardURL: dashboardURL,
+			Repo:         repoSlug(h.driftiveConfig),
+			DriftIssues:  ghState.IssueNumbersByProject(types.DriftIssueKind),
+			ErrorIssues:  ghState.IssueNumbersByProject(types.ErrorIssueKind),
+		}
+		err := teamsNotification.Handle(ctx, analysisResult)
+		if err != nil {
+			teamsStatus = notifierFailed
+			log.Error().Msgf("Failed to send teams notification. %v", err)
+		} else {
+			teamsStatus = notifierOk
+		}
+	}
+
+	if h.driftiveConfig.DiscordWebhookUrl != "" {
+		log.Info().Msg("Sending notification to discord...")
+		discordNotification := discord.Discord{
+			Url:          h.driftiveConfig.DiscordWebhookUrl,
+			IssuesState:  issuesState,
+			DashboardURL: dashboardURL,
+			Repo:         repoSlug(h.driftiveConfig),
+			DriftIssues:  ghState.IssueNumbersByProject(types.DriftIssueKind),
+			ErrorIssues:  ghState.IssueNumbersByProject(types.ErrorIssueKind),
+		}
+		err := discordNotification.Handle(ctx, analysisResult)
+		if err != nil {
+			discordStatus = notifierFailed
+			log.Error().Msgf("Failed to send discord notification. %v", err)
+		} else {
+			discordStatus = notifierOk
+		}
+	}
+
+	if h.driftiveConfig.MattermostWebhookUrl != "" {
+		log.Info().Msg("Sending notification to mattermost...")
+		mattermostNotification := mattermost.Mattermost{
+			Url:          h.driftiveConfig.MattermostWebhookUrl,
+			IssuesState:  issuesState,
+			DashboardURL: dashboardURL,
+			Repo:         repoSlug(h.driftiveConfig),
+			DriftIssues:  ghState.IssueNumbersByProject(types.DriftIssueKind),
+			ErrorIssues:  ghState.IssueNumbersByProject(types.ErrorIssueKind),
+		}
+		err := mattermostNotification.Handle(ctx, analysisResult)
+		if err != nil {
+			mattermostStatus = notifierFailed
+			log.Error().Msgf("Failed to send mattermost notification. %v", err)
+		} else {
+			mattermostStatus = notifierOk
+		}
+	}
+
+	if len(h.driftiveConfig.WebhookUrls) > 0 {
+		log.Info().Msgf("Sending results to %d webhook(s)...", len(h.driftiveConfig.WebhookUrls))
+		if h.driftiveConfig.WebhookSecret == "" {
+			log.Warn().Msg("DRIFTIVE_WEBHOOK_SECRET is not set. Webhook requests are sent unsigned")
+		}
+		webhookNotification := webhook.NewWebhookNotification(h.driftiveConfig.WebhookUrls, h.driftiveConfig.WebhookSecret,
+			h.runKey, repoSlug(h.driftiveConfig), dashboardURL)
+		if err := webhookNotification.Handle(ctx, analysisResult); err != nil {
+			webhookStatus = notifierFailed
+			log.Error().Msgf("Failed to send webhook notifications. %v", err)
+		} else {
+			webhookStatus = notifierOk
+		}
+	}
+
 	log.Info().
 		Str("driftive_api", driftiveStatus).
 		Str("github", githubStatus).
 		Str("stdout", stdoutStatus).
 		Str("slack", slackStatus).
+		Str("teams", teamsStatus).
+		Str("discord", discordStatus).
+		Str("mattermost", mattermostStatus).
+		Str("webhook", webhookStatus).
 		Msg("notification summary")
 }
@@ -0,0 +1,76 @@
+// Package post sends the messages of the chat notifiers to their incoming webhooks, and builds
+// the retrying client the Driftive API and webhook notifiers post their results with.
+package post
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"resty.dev/v3"
+)
+
+// JSON marshals message and posts it to url. name identifies the notifier in logs and errors,
+// e.g. "slack". Any 2xx answer is a success: Slack answers 200 OK, Teams workflows 202 Accepted
+// and Discord 204 No Content.
+func JSON(ctx context.Context, name, url string, message any) error {
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		log.Error().Msgf("failed to marshal %s message. %v", name, err)
+		return fmt.Errorf("failed to marshal %s message. %w", name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return logged(fmt.Sprintf("failed to create %s request. %v", name, err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return logged(fmt.Sprintf("failed to send %s message. %v", name, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return logged(fmt.Sprintf("failed to read response body. %v", err))
+		}
+		return logged(fmt.Sprintf("failed to send %s request. %v. Body: %s", name, resp.Status, string(body)))
+	}
+
+	return nil
+}
+
+// NewRetryingClient returns a client that retries transient failures (network errors, 5xx, 429)
+// up to 3 times, with exponential backoff between waitTime and maxWaitTime. POSTs are retried
+// too, so callers must send a key that lets the receiver drop duplicates. Callers close it.
+func NewRetryingClient(waitTime, maxWaitTime time.Duration) *resty.Client {
+	return resty.New().
+		SetTimeout(30 * time.Second).
+		SetRetryCount(3).
+		// resty only retries idempotent methods unless told otherwise, so without this the
+		// retry count and conditions below never apply to a POST.
+		SetRetryAllowNonIdempotent(true).
+		SetRetryWaitTime(waitTime).
+		SetRetryMaxWaitTime(maxWaitTime).
+		AddRetryConditions(func(res *resty.Response, err error) bool {
+			if err != nil {
+				return true
+			}
+			sc := res.StatusCode()
+			return sc == 429 || sc >= 500
+		})
+}
+
+func logged(msg string) error {
+	log.Error().Msg(msg)
+	return errors.New(msg)
+}
@@ -0,0 +1,92 @@
+package post
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJSONPostsMessage(t *testing.T) {
+	var got map[string]string
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := JSON(context.Background(), "test", server.URL, map[string]string{"text": "hi"}); err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	if contentType != "application/json" || got["text"] != "hi" {
+		t.Errorf("Content-Type = %q, body = %v", contentType, got)
+	}
+}
+
+func TestJSONReturnsErrorOnBadStatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("invalid_payload"))
+	}))
+	defer server.Close()
+
+	err := JSON(context.Background(), "test", server.URL, map[string]string{})
+	if err == nil || !strings.Contains(err.Error(), "failed to send test request") || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Errorf("JSON() error = %v, want the status and body", err)
+	}
+}
+
+func TestJSONReturnsErrorOnConnectionFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	if err := JSON(context.Background(), "test", server.URL, map[string]string{}); err == nil {
+		t.Error("JSON() should fail when the webhook is unreachable")
+	}
+}
+
+func TestRetryingClientRetriesTransientFailuresOfPosts(t *testing.T) {
+	statuses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[attempts.Add(1)-1])
+	}))
+	defer server.Close()
+
+	client := NewRetryingClient(time.Millisecond, 5*time.Millisecond)
+	defer client.Close()
+	res, err := client.R().WithContext(context.Background()).Post(server.URL)
+	if err != nil || res.StatusCode() != http.StatusOK {
+		t.Fatalf("Post() = %v, %v, want the retry to succeed", res, err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("attempts = %d, want 3", got)
+	}
+}
+
+func TestRetryingClientDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := NewRetryingClient(time.Millisecond, 5*time.Millisecond)
+	defer client.Close()
+	res, err := client.R().WithContext(context.Background()).Post(server.URL)
+	if err != nil || res.StatusCode() != http.StatusBadRequest {
+		t.Fatalf("Post() = %v, %v, want the 400", res, err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
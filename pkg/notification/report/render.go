@@ -0,0 +1,272 @@
+package report
+
+import (
+	"driftive/pkg/models/backend"
+	"fmt"
+	"strings"
+)
+
+// Line is one project in a notifier's project list.
+type Line struct {
+	// Title is what the line shows: the project's name, or its key when it has none.
+	Title string
+	// URL links the title to the project's GitHub issue. Empty renders the title as plain text.
+	URL string
+	// Metadata is the project's owners, severity and tags, shown after the title.
+	Metadata string
+	// Note is an optional trailing parenthetical, such as the phase that failed or the
+	// resource breakdown of a drift.
+	Note string
+}
+
+// List is a bucket of projects as notifiers show it.
+type List struct {
+	Status Status
+	// Heading names the list, e.g. "Drifted Projects".
+	Heading string
+	Lines   []Line
+}
+
+// IssueLinks locates the GitHub issues of the projects.
+type IssueLinks struct {
+	// Repo is "owner/name". Empty outside GitHub Actions, in which case nothing is linked.
+	Repo string
+	// DriftIssues and ErrorIssues map a project key to its open GitHub issue number. Nil when
+	// GitHub issues are disabled.
+	DriftIssues map[string]int
+	ErrorIssues map[string]int
+}
+
+// IssueURL returns the URL of the issue of the project with the given key, or "" when it has
+// none.
+func (l IssueLinks) IssueURL(issues map[string]int, dir string) string {
+	if l.Repo == "" {
+		return ""
+	}
+	number, ok := issues[dir]
+	if !ok || number <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("https://github.com/%s/issues/%d", l.Repo, number)
+}
+
+// Lists returns the drifted, failed, timed out and blocked projects as lists, in that order,
+// leaving out the empty ones. Skipped and clean projects are only counted, never listed.
+func (s Summary) Lists(links IssueLinks) []List {
+	lists := make([]List, 0, 4)
+	add := func(status Status, heading string, lines []Line) {
+		if len(lines) > 0 {
+			lists = append(lists, List{Status: status, Heading: heading, Lines: lines})
+		}
+	}
+
+	drifted := make([]Line, 0, len(s.Drifted))
+	for _, p := range s.Drifted {
+		drifted = append(drifted, Line{Title: p.Title(), URL: links.IssueURL(links.DriftIssues, p.Dir),
+			Metadata: p.MetadataText(), Note: p.ChangeText()})
+	}
+	add(StatusDrifted, "Drifted Projects", drifted)
+
+	errored := make([]Line, 0, len(s.Errored))
+	for _, p := range s.Errored {
+		errored = append(errored, Line{Title: p.Title(), URL: links.IssueURL(links.ErrorIssues, p.Dir),
+			Metadata: p.MetadataText(), Note: p.FailedPhase})
+	}
+	add(StatusErrored, "Failed Projects", errored)
+
+	timedOut := make([]Line, 0, len(s.TimedOut))
+	for _, p := range s.TimedOut {
+		line := Line{Title: p.Title(), URL: links.IssueURL(links.ErrorIssues, p.Dir), Metadata: p.MetadataText()}
+		if p.FailedPhase != "" {
+			line.Note = "during " + p.FailedPhase
+		}
+		timedOut = append(timedOut, line)
+	}
+	add(StatusTimedOut, "Timed Out Projects", timedOut)
+
+	// Blocked projects have no issue link: they get no error issue of their own.
+	blocked := make([]Line, 0, len(s.Blocked))
+	for _, p := range s.Blocked {
+		blocked = append(blocked, Line{Title: p.Title(), Metadata: p.MetadataText(), Note: "blocked by " + p.BlockedBy})
+	}
+	add(StatusBlocked, "Blocked Projects", blocked)
+
+	return lists
+}
+
+// ListFormat renders project lists in a notifier's markup.
+type ListFormat struct {
+	// Line renders one project, trailing newline included.
+	Line func(Line) string
+	// Truncated renders what replaces the projects left out, trailing newline included.
+	Truncated func(remaining int) string
+}
+
+// Render renders heading followed by one line per project, stopping before budget bytes and
+// appending the Truncated suffix for the rest. heading is rendered as is.
+func (f ListFormat) Render(heading string, lines []Line, budget int) string {
+	var out strings.Builder
+	out.WriteString(heading)
+
+	for i, line := range lines {
+		rendered := f.Line(line)
+		suffix := f.Truncated(len(lines) - i)
+		if out.Len()+len(rendered)+len(suffix) > budget {
+			out.WriteString(suffix)
+			break
+		}
+		out.WriteString(rendered)
+	}
+
+	return out.String()
+}
+
+// MarkdownLine renders a line as a markdown list item, for the notifiers whose markup is plain
+// markdown, e.g. "- [prod](https://github.com/…/issues/4) (sev: high) _(2 updates)_".
+func MarkdownLine(line Line) string {
+	label := line.Title
+	if line.URL != "" {
+		label = fmt.Sprintf("[%s](%s)", line.Title, line.URL)
+	}
+	if line.Metadata != "" {
+		label += " (" + line.Metadata + ")"
+	}
+	if line.Note != "" {
+		return fmt.Sprintf("- %s _(%s)_\n", label, line.Note)
+	}
+	return fmt.Sprintf("- %s\n", label)
+}
+
+// TruncationText tells how many projects a list had no room for, pointing at the dashboard
+// when there is one, e.g. "...and 3 more project(s)".
+func TruncationText(remaining int, dashboard bool) string {
+	if dashboard {
+		return fmt.Sprintf("...and %d more project(s). View all in the dashboard.", remaining)
+	}
+	return fmt.Sprintf("...and %d more project(s)", remaining)
+}
+
+// Synopsis is a one-line plain text account of the run, for push notifications and clients that
+// cannot render rich messages, prefixed with "[repo]" when repo is set.
+func (s Summary) Synopsis(repo string) string {
+	var text string
+	failed := s.NumErrored() + s.NumTimedOut()
+	switch {
+	case s.NumDrifted() > 0 && failed > 0:
+		text = fmt.Sprintf("Drift detected in %d project(s), %d failed to analyze", s.NumDrifted(), failed)
+	case s.NumDrifted() > 0:
+		text = fmt.Sprintf("Drift detected in %d project(s)", s.NumDrifted())
+	case failed > 0:
+		text = fmt.Sprintf("%d project(s) failed to analyze", failed)
+	default:
+		text = "All drifts resolved"
+	}
+
+	if repo == "" {
+		return text
+	}
+	return fmt.Sprintf("[%s] %s", repo, text)
+}
+
+// Headline is what a notification leads with.
+type Headline int
+
+const (
+	// HeadlineNone is a run with nothing to report.
+	HeadlineNone Headline = iota
+	// HeadlineDrift is a run where projects drifted, whatever else happened.
+	HeadlineDrift
+	// HeadlineErrors is a run where projects failed or timed out, and none drifted.
+	HeadlineErrors
+	// HeadlineResolved is a run whose only news is issues resolved since the last one.
+	HeadlineResolved
+)
+
+// Headline picks what a notification of the run leads with, drift first, then errors, then
+// resolved issues.
+func (s Summary) Headline(state *backend.DriftIssuesState) Headline {
+	switch {
+	case s.NumDrifted() > 0:
+		return HeadlineDrift
+	case s.NumErrored() > 0 || s.NumTimedOut() > 0:
+		return HeadlineErrors
+	case IssuesResolved(state):
+		return HeadlineResolved
+	}
+	return HeadlineNone
+}
+
+// Stat is one figure of a notification's stats, e.g. Drifted "2 / 10 projects".
+type Stat struct {
+	Name  string
+	Value string
+	// Detail qualifies the value, e.g. the resource breakdown of the drift. Empty when there is
+	// nothing to add.
+	Detail string
+}
+
+// Stats are the figures of the run: drifted projects, then errored and skipped ones when there
+// are any, and the duration, always last so that notifiers laying them out in a grid keep a
+// stable shape. Timed out projects count as errored, keeping the grid within four figures.
+func (s Summary) Stats() []Stat {
+	stats := make([]Stat, 0, 4)
+	drifted := Stat{Name: "Drifted", Value: fmt.Sprintf("%d / %d projects", s.NumDrifted(), s.TotalProjects)}
+	if s.Changes.Total() > 0 {
+		drifted.Detail = s.Changes.String()
+	}
+	stats = append(stats, drifted)
+
+	if failed := s.NumErrored() + s.NumTimedOut(); failed > 0 {
+		errored := Stat{Name: "Errored", Value: fmt.Sprintf("%d", failed)}
+		if s.NumTimedOut() > 0 {
+			errored.Detail = fmt.Sprintf("%d timed out", s.NumTimedOut())
+		}
+		stats = append(stats, errored)
+	}
+	if s.NumSkipped() > 0 {
+		stats = append(stats, Stat{Name: "Skipped", Value: fmt.Sprintf("%d (open PR)", s.NumSkipped())})
+	}
+
+	return append(stats, Stat{Name: "Duration", Value: s.DurationText()})
+}
+
+// ResolvedCounts are the issues the GitHub notifier closed this run.
+type ResolvedCounts struct {
+	Drift int
+	Error int
+}
+
+// Resolved returns the issues closed this run. Zero for a nil state.
+func Resolved(state *backend.DriftIssuesState) ResolvedCounts {
+	if state == nil {
+		return ResolvedCounts{}
+	}
+	return ResolvedCounts{Drift: state.NumResolvedIssues, Error: state.NumResolvedErrorIssues}
+}
+
+// Text tells how many issues were resolved, passing the counts through emphasize for the
+// notifier's bold markup, e.g. "*5 issue(s)* and *2 error issue(s) resolved* since last
+// analysis".
+func (c ResolvedCounts) Text(emphasize func(string) string) string {
+	switch {
+	case c.Drift > 0 && c.Error > 0:
+		return fmt.Sprintf("%s and %s since last analysis", emphasize(fmt.Sprintf("%d issue(s)", c.Drift)),
+			emphasize(fmt.Sprintf("%d error issue(s) resolved", c.Error)))
+	case c.Error > 0:
+		return emphasize(fmt.Sprintf("%d error issue(s) resolved", c.Error)) + " since last analysis"
+	}
+	return emphasize(fmt.Sprintf("%d issue(s) resolved", c.Drift)) + " since last analysis"
+}
+
+// IssuesResolved reports whether the GitHub notifier closed any issue this run. A nil or stale
+// state never did.
+func IssuesResolved(state *backend.DriftIssuesState) bool {
+	return state != nil && state.StateUpdated &&
+		(state.NumResolvedIssues > 0 || state.NumResolvedErrorIssues > 0)
+}
+
+// ShouldNotify reports whether a chat notifier should post for the run: when it has findings or
+// resolved issues. Fully clean runs stay silent.
+func (s Summary) ShouldNotify(state *backend.DriftIssuesState) bool {
+	return s.HasFindings() || IssuesResolved(state)
+}
@@ -0,0 +1,231 @@
+package report
+
+import (
+	"driftive/pkg/drift"
+	"driftive/pkg/models"
+	"driftive/pkg/models/backend"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestIssuesResolved(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    *backend.DriftIssuesState
+		expected bool
+	}{
+		{
+			name:     "nil state",
+			state:    nil,
+			expected: false,
+		},
+		{
+			name:     "state not updated",
+			state:    &backend.DriftIssuesState{StateUpdated: false, NumResolvedIssues: 5},
+			expected: false,
+		},
+		{
+			name:     "no resolved issues",
+			state:    &backend.DriftIssuesState{StateUpdated: true, NumResolvedIssues: 0},
+			expected: false,
+		},
+		{
+			name:     "has resolved issues",
+			state:    &backend.DriftIssuesState{StateUpdated: true, NumResolvedIssues: 3},
+			expected: true,
+		},
+		{
+			name:     "only error issues resolved",
+			state:    &backend.DriftIssuesState{StateUpdated: true, NumResolvedErrorIssues: 2},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := IssuesResolved(tt.state)
+			if got != tt.expected {
+				t.Errorf("IssuesResolved() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShouldNotify(t *testing.T) {
+	resolved := &backend.DriftIssuesState{StateUpdated: true, NumResolvedIssues: 1}
+	clean := Classify(drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{
+		{Project: models.TypedProject{Dir: "a"}, Succeeded: true},
+	}})
+	failed := Classify(drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{
+		{Project: models.TypedProject{Dir: "a"}, FailedPhase: drift.PhasePlan},
+	}})
+
+	if clean.ShouldNotify(nil) {
+		t.Error("a clean run should stay silent")
+	}
+	if !clean.ShouldNotify(resolved) {
+		t.Error("a clean run that resolved issues should notify")
+	}
+	if !failed.ShouldNotify(nil) {
+		t.Error("a run with errors should notify")
+	}
+}
+
+func TestHeadline(t *testing.T) {
+	resolved := &backend.DriftIssuesState{StateUpdated: true, NumResolvedIssues: 1}
+	classify := func(results ...drift.DriftProjectResult) Summary {
+		return Classify(drift.DriftDetectionResult{ProjectResults: results})
+	}
+	driftedResult := drift.DriftProjectResult{Project: models.TypedProject{Dir: "a"}, Drifted: true, Succeeded: true}
+	failedResult := drift.DriftProjectResult{Project: models.TypedProject{Dir: "b"}, FailedPhase: drift.PhasePlan}
+	cleanResult := drift.DriftProjectResult{Project: models.TypedProject{Dir: "c"}, Succeeded: true}
+
+	tests := []struct {
+		name    string
+		summary Summary
+		state   *backend.DriftIssuesState
+		want    Headline
+	}{
+		{"drift wins over errors", classify(driftedResult, failedResult), resolved, HeadlineDrift},
+		{"errors", classify(failedResult, cleanResult), resolved, HeadlineErrors},
+		{"resolved", classify(cleanResult), resolved, HeadlineResolved},
+		{"nothing", classify(cleanResult), nil, HeadlineNone},
+	}
+	for _, tt := range tests {
+		if got := tt.summary.Headline(tt.state); got != tt.want {
+			t.Errorf("%s: Headline() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	summary := Classify(drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{
+			{Project: models.TypedProject{Dir: "a"}, Drifted: true, Succeeded: true,
+				DriftedResources: []drift.DriftedResource{{Address: "x.y", Action: drift.ActionUpdate}}},
+			{Project: models.TypedProject{Dir: "b"}, FailedPhase: drift.PhasePlan},
+			{Project: models.TypedProject{Dir: "c"}, FailedPhase: drift.PhasePlan, FailureReason: drift.ReasonTimeout},
+		},
+		TotalProjects: 3,
+	})
+
+	want := []Stat{
+		{Name: "Drifted", Value: "1 / 3 projects", Detail: "1 update"},
+		{Name: "Errored", Value: "2", Detail: "1 timed out"},
+		{Name: "Duration", Value: summary.DurationText()},
+	}
+	if got := summary.Stats(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Stats() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestResolvedCountsText(t *testing.T) {
+	bold := func(text string) string { return "*" + text + "*" }
+	tests := []struct {
+		state *backend.DriftIssuesState
+		want  string
+	}{
+		{&backend.DriftIssuesState{NumResolvedIssues: 5}, "*5 issue(s) resolved* since last analysis"},
+		{&backend.DriftIssuesState{NumResolvedErrorIssues: 2}, "*2 error issue(s) resolved* since last analysis"},
+		{&backend.DriftIssuesState{NumResolvedIssues: 5, NumResolvedErrorIssues: 2},
+			"*5 issue(s)* and *2 error issue(s) resolved* since last analysis"},
+	}
+	for _, tt := range tests {
+		if got := Resolved(tt.state).Text(bold); got != tt.want {
+			t.Errorf("Text() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLists(t *testing.T) {
+	summary := Classify(drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{
+		{Project: models.TypedProject{Dir: "infra/vpc", Name: "vpc"}, Drifted: true, Succeeded: true},
+		{Project: models.TypedProject{Dir: "infra/db"}, FailedPhase: drift.PhaseInit},
+		{Project: models.TypedProject{Dir: "infra/app"}, FailureReason: drift.ReasonBlocked, BlockedBy: "infra/db"},
+		{Project: models.TypedProject{Dir: "infra/dns"}, Succeeded: true},
+	}})
+	links := IssueLinks{
+		Repo:        "acme/infra",
+		DriftIssues: map[string]int{"infra/vpc": 4},
+		ErrorIssues: map[string]int{"infra/db": 5, "infra/app": 6},
+	}
+
+	lists := summary.Lists(links)
+
+	want := []List{
+		{Status: StatusDrifted, Heading: "Drifted Projects", Lines: []Line{
+			{Title: "vpc", URL: "https://github.com/acme/infra/issues/4"},
+		}},
+		{Status: StatusErrored, Heading: "Failed Projects", Lines: []Line{
+			{Title: "infra/db", URL: "https://github.com/acme/infra/issues/5", Note: drift.PhaseInit},
+		}},
+		{Status: StatusBlocked, Heading: "Blocked Projects", Lines: []Line{
+			{Title: "infra/app", Note: "blocked by infra/db"},
+		}},
+	}
+	if fmt.Sprint(lists) != fmt.Sprint(want) {
+		t.Errorf("Lists() = %+v\nwant %+v", lists, want)
+	}
+
+	for _, list := range summary.Lists(IssueLinks{DriftIssues: links.DriftIssues}) {
+		for _, line := range list.Lines {
+			if line.URL != "" {
+				t.Errorf("expected no links without a repo, got %s", line.URL)
+			}
+		}
+	}
+}
+
+func TestListFormatRender(t *testing.T) {
+	format := ListFormat{
+		Line:      func(l Line) string { return "- " + l.Title + "\n" },
+		Truncated: func(remaining int) string { return TruncationText(remaining, false) + "\n" },
+	}
+	lines := make([]Line, 0, 50)
+	for i := range 50 {
+		lines = append(lines, Line{Title: fmt.Sprintf("project-%02d", i)})
+	}
+
+	got := format.Render("Drifted\n", lines, 200)
+
+	if len(got) > 200 {
+		t.Errorf("rendered %d bytes, budget was 200", len(got))
+	}
+	shown := strings.Count(got, "- project-")
+	if !strings.HasSuffix(got, fmt.Sprintf("...and %d more project(s)\n", 50-shown)) {
+		t.Errorf("unexpected truncation:\n%s", got)
+	}
+	if all := format.Render("Drifted\n", lines[:3], 200); strings.Contains(all, "more project(s)") {
+		t.Errorf("expected no truncation for a short list:\n%s", all)
+	}
+}
+
+func TestSynopsis(t *testing.T) {
+	summary := Classify(drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{
+		{Project: models.TypedProject{Dir: "a"}, Drifted: true, Succeeded: true},
+		{Project: models.TypedProject{Dir: "b"}, FailureReason: drift.ReasonTimeout},
+	}})
+	if got := summary.Synopsis("acme/infra"); got != "[acme/infra] Drift detected in 1 project(s), 1 failed to analyze" {
+		t.Errorf("Synopsis() = %q", got)
+	}
+	if got := (Summary{}).Synopsis(""); got != "All drifts resolved" {
+		t.Errorf("Synopsis() = %q", got)
+	}
+}
+
+func TestMarkdownLine(t *testing.T) {
+	tests := []struct {
+		line Line
+		want string
+	}{
+		{Line{Title: "infra/vpc"}, "- infra/vpc\n"},
+		{Line{Title: "vpc", URL: "https://github.com/acme/infra/issues/4", Metadata: "sev: high", Note: "2 updates"},
+			"- [vpc](https://github.com/acme/infra/issues/4) (sev: high) _(2 updates)_\n"},
+	}
+	for _, tt := range tests {
+		if got := MarkdownLine(tt.line); got != tt.want {
+			t.Errorf("MarkdownLine(%+v) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
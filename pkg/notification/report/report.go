@@ -3,7 +3,10 @@ package report
 
 import (
 	"driftive/pkg/drift"
+	"fmt"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -13,16 +16,132 @@ type Status string
 const (
 	StatusDrifted Status = "drifted"
 	StatusErrored Status = "errored"
+	// StatusTimedOut is a failed project that was killed for running past its timeout. It is
+	// kept apart from StatusErrored, which the tool itself reported.
+	StatusTimedOut Status = "timed_out"
+	// StatusBlocked is a project that was not analyzed because a project it depends on failed.
+	StatusBlocked Status = "blocked"
 	StatusSkipped Status = "skipped"
 	StatusClean   Status = "clean"
 )
 
 // Project is one project's outcome, bucketed and ready to render.
 type Project struct {
-	Dir    string
-	Status Status
-	// FailedPhase is drift.PhaseInit or drift.PhasePlan when Status is StatusErrored.
-	FailedPhase string
+	// Dir is the project's key: its repo-relative dir, suffixed with @workspace when the project
+	// plans a workspace. It matches the keys of the GitHub issue state.
+	Dir    string `json:"dir"`
+	Status Status `json:"status"`
+	// FailedPhase is drift.PhaseInit or drift.PhasePlan when Status is StatusErrored or
+	// StatusTimedOut.
+	FailedPhase string `json:"failed_phase,omitempty"`
+	// BlockedBy is the key of the failed project that blocked this one when Status is
+	// StatusBlocked.
+	BlockedBy string `json:"blocked_by,omitempty"`
+	// Changes tallies the project's drifted resources by action.
+	Changes ActionCounts `json:"changes"`
+	// Modules are the distinct modules holding drifted resources, sorted. The root module is
+	// not listed.
+	Modules []string `json:"modules,omitempty"`
+	// Name is the project's display name, e.g. prod/payments. Empty when the project has none
+	// other than its key.
+	Name     string   `json:"name,omitempty"`
+	Owners   []string `json:"owners,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+}
+
+// Title is how people know the project: its name when it has one, its key otherwise.
+func (p Project) Title() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Dir
+}
+
+// MetadataText renders the project's owners, severity and tags, e.g.
+// "owner: @payments-team, sev: high". Empty when none is set.
+func (p Project) MetadataText() string {
+	parts := make([]string, 0, 3)
+	switch len(p.Owners) {
+	case 0:
+	case 1:
+		parts = append(parts, "owner: "+p.Owners[0])
+	default:
+		parts = append(parts, "owners: "+strings.Join(p.Owners, " "))
+	}
+	if p.Severity != "" {
+		parts = append(parts, "sev: "+p.Severity)
+	}
+	if len(p.Tags) > 0 {
+		parts = append(parts, "tags: "+strings.Join(p.Tags, " "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ChangeText renders the resource breakdown, e.g. "3 updates, 1 replace in module.vpc". Empty
+// when the result carried no per-resource detail.
+func (p Project) ChangeText() string {
+	text := p.Changes.String()
+	if text == "" || len(p.Modules) == 0 {
+		return text
+	}
+	return text + " in " + strings.Join(p.Modules, ", ")
+}
+
+// ActionCounts tallies drifted resources by action.
+type ActionCounts struct {
+	Create  int `json:"create"`
+	Update  int `json:"update"`
+	Replace int `json:"replace"`
+	Delete  int `json:"delete"`
+}
+
+func (c *ActionCounts) add(action string) {
+	switch action {
+	case drift.ActionCreate:
+		c.Create++
+	case drift.ActionUpdate:
+		c.Update++
+	case drift.ActionReplace:
+		c.Replace++
+	case drift.ActionDelete:
+		c.Delete++
+	}
+}
+
+func (c *ActionCounts) merge(other ActionCounts) {
+	c.Create += other.Create
+	c.Update += other.Update
+	c.Replace += other.Replace
+	c.Delete += other.Delete
+}
+
+func (c ActionCounts) Total() int {
+	return c.Create + c.Update + c.Replace + c.Delete
+}
+
+// String renders the non-zero counts in a fixed order, e.g. "3 updates, 1 replace".
+func (c ActionCounts) String() string {
+	parts := make([]string, 0, 4)
+	for _, count := range []struct {
+		n    int
+		noun string
+	}{
+		{c.Create, "create"},
+		{c.Update, "update"},
+		{c.Replace, "replace"},
+		{c.Delete, "delete"},
+	} {
+		if count.n == 0 {
+			continue
+		}
+		if count.n == 1 {
+			parts = append(parts, fmt.Sprintf("1 %s", count.noun))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %ss", count.n, count.noun))
+		}
+	}
+	return strings.Join(parts, ", ")
 }
 
 // Summary is a whole run's classification.
@@ -32,16 +151,20 @@ type Project struct {
 // enabled, there is no clean counter, and TotalProjects counts discovered projects, which
 // exceeds len(ProjectResults) when a run is cancelled.
 type Summary struct {
-	Drifted []Project
-	Errored []Project
-	Skipped []Project
-	Clean   []Project
+	Drifted  []Project
+	Errored  []Project
+	TimedOut []Project
+	Blocked  []Project
+	Skipped  []Project
+	Clean    []Project
 
 	// TotalProjects is how many projects were discovered.
 	TotalProjects int
 	// NotChecked is how many discovered projects the run never reached. Zero for a complete run.
 	NotChecked int
-	Duration   time.Duration
+	// Changes totals the drifted resources of the Drifted bucket by action.
+	Changes  ActionCounts
+	Duration time.Duration
 }
 
 // Classify buckets a run's results. Each bucket is sorted by Dir so message bodies stay stable
@@ -53,8 +176,19 @@ func Classify(result drift.DriftDetectionResult) Summary {
 	}
 
 	for _, r := range result.ProjectResults {
-		p := Project{Dir: r.Project.Dir}
+		p := Project{Dir: r.Project.Key(), Owners: r.Project.Owners, Tags: r.Project.Tags, Severity: r.Project.Severity}
+		if r.Project.Name != "" {
+			p.Name = r.Project.DisplayName()
+		}
 		switch {
+		case !r.Succeeded && r.FailureReason == drift.ReasonBlocked:
+			p.Status = StatusBlocked
+			p.BlockedBy = r.BlockedBy
+			sum.Blocked = append(sum.Blocked, p)
+		case !r.Succeeded && r.FailureReason == drift.ReasonTimeout:
+			p.Status = StatusTimedOut
+			p.FailedPhase = r.FailedPhase
+			sum.TimedOut = append(sum.TimedOut, p)
 		case !r.Succeeded:
 			p.Status = StatusErrored
 			p.FailedPhase = r.FailedPhase
@@ -64,6 +198,8 @@ func Classify(result drift.DriftDetectionResult) Summary {
 			sum.Skipped = append(sum.Skipped, p)
 		case r.Drifted:
 			p.Status = StatusDrifted
+			p.Changes, p.Modules = breakdown(r.DriftedResources)
+			sum.Changes.merge(p.Changes)
 			sum.Drifted = append(sum.Drifted, p)
 		default:
 			p.Status = StatusClean
@@ -75,26 +211,42 @@ func Classify(result drift.DriftDetectionResult) Summary {
 		sum.NotChecked = n
 	}
 
-	for _, bucket := range [][]Project{sum.Drifted, sum.Errored, sum.Skipped, sum.Clean} {
+	for _, bucket := range [][]Project{sum.Drifted, sum.Errored, sum.TimedOut, sum.Blocked, sum.Skipped, sum.Clean} {
 		sortByDir(bucket)
 	}
 
 	return sum
 }
 
+func breakdown(resources []drift.DriftedResource) (ActionCounts, []string) {
+	var counts ActionCounts
+	var modules []string
+	for _, r := range resources {
+		counts.add(r.Action)
+		if r.ModulePath != "" && !slices.Contains(modules, r.ModulePath) {
+			modules = append(modules, r.ModulePath)
+		}
+	}
+	sort.Strings(modules)
+	return counts, modules
+}
+
 func sortByDir(projects []Project) {
 	sort.Slice(projects, func(i, j int) bool { return projects[i].Dir < projects[j].Dir })
 }
 
-func (s Summary) NumDrifted() int { return len(s.Drifted) }
-func (s Summary) NumErrored() int { return len(s.Errored) }
-func (s Summary) NumSkipped() int { return len(s.Skipped) }
-func (s Summary) NumClean() int   { return len(s.Clean) }
+func (s Summary) NumDrifted() int  { return len(s.Drifted) }
+func (s Summary) NumErrored() int  { return len(s.Errored) }
+func (s Summary) NumTimedOut() int { return len(s.TimedOut) }
+func (s Summary) NumBlocked() int  { return len(s.Blocked) }
+func (s Summary) NumSkipped() int  { return len(s.Skipped) }
+func (s Summary) NumClean() int    { return len(s.Clean) }
 
 // HasFindings reports whether the run produced anything worth notifying about. Skipped-only and
-// fully clean runs are not findings.
+// fully clean runs are not findings. Blocked projects are not either: the upstream failure that
+// blocked them is.
 func (s Summary) HasFindings() bool {
-	return len(s.Drifted) > 0 || len(s.Errored) > 0
+	return len(s.Drifted) > 0 || len(s.Errored) > 0 || len(s.TimedOut) > 0
 }
 
 // DurationText formats the run duration for display, trading precision for readability once
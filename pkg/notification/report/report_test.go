@@ -157,6 +157,45 @@ func TestClassifyCarriesFailedPhase(t *testing.T) {
 	}
 }
 
+func TestClassifySeparatesTimeouts(t *testing.T) {
+	timedOut := project("infra/a", false, false, false)
+	timedOut.FailedPhase = drift.PhasePlan
+	timedOut.FailureReason = drift.ReasonTimeout
+
+	sum := Classify(drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{timedOut, project("infra/b", false, false, false)},
+		TotalProjects:  2,
+	})
+
+	if sum.NumTimedOut() != 1 || sum.NumErrored() != 1 {
+		t.Fatalf("NumTimedOut = %d, NumErrored = %d, want 1 each", sum.NumTimedOut(), sum.NumErrored())
+	}
+	if got := sum.TimedOut[0]; got.Dir != "infra/a" || got.FailedPhase != drift.PhasePlan || got.Status != StatusTimedOut {
+		t.Errorf("unexpected timed out project: %+v", got)
+	}
+	if !sum.HasFindings() {
+		t.Error("a timeout is a finding")
+	}
+}
+
+func TestClassifySeparatesBlockedProjects(t *testing.T) {
+	blocked := project("live/app", false, false, false)
+	blocked.FailureReason = drift.ReasonBlocked
+	blocked.BlockedBy = "live/vpc"
+
+	sum := Classify(drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{blocked, project("live/vpc", false, false, false)},
+		TotalProjects:  2,
+	})
+
+	if sum.NumBlocked() != 1 || sum.NumErrored() != 1 {
+		t.Fatalf("NumBlocked = %d, NumErrored = %d, want 1 each", sum.NumBlocked(), sum.NumErrored())
+	}
+	if got := sum.Blocked[0]; got.Dir != "live/app" || got.BlockedBy != "live/vpc" || got.Status != StatusBlocked {
+		t.Errorf("unexpected blocked project: %+v", got)
+	}
+}
+
 func TestClassifyCarriesTotals(t *testing.T) {
 	sum := Classify(drift.DriftDetectionResult{
 		ProjectResults: []drift.DriftProjectResult{project("infra/a", false, true, false)},
@@ -228,3 +267,81 @@ func TestDurationText(t *testing.T) {
 		})
 	}
 }
+
+func TestClassifyTotalsResourceChanges(t *testing.T) {
+	vpc := project("infra/vpc", true, true, false)
+	vpc.DriftedResources = []drift.DriftedResource{
+		{Address: "module.vpc.aws_subnet.a", ModulePath: "module.vpc", Action: drift.ActionUpdate},
+		{Address: "module.vpc.aws_subnet.b", ModulePath: "module.vpc", Action: drift.ActionUpdate},
+		{Address: "aws_eip.nat", Action: drift.ActionReplace},
+	}
+	rds := project("infra/rds", true, true, false)
+	rds.DriftedResources = []drift.DriftedResource{{Address: "aws_db_instance.main", Action: drift.ActionUpdate}}
+	// Skipped projects are not part of the drift totals.
+	skippedProject := project("infra/skipped", true, true, true)
+	skippedProject.DriftedResources = []drift.DriftedResource{{Address: "aws_s3_bucket.a", Action: drift.ActionDelete}}
+
+	sum := Classify(drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{vpc, rds, skippedProject},
+		TotalProjects:  3,
+	})
+
+	if got := sum.Changes.String(); got != "3 updates, 1 replace" {
+		t.Errorf("Changes = %q, want %q", got, "3 updates, 1 replace")
+	}
+	if got := sum.Drifted[1].ChangeText(); got != "2 updates, 1 replace in module.vpc" {
+		t.Errorf("ChangeText() = %q", got)
+	}
+	if got := sum.Drifted[0].ChangeText(); got != "1 update" {
+		t.Errorf("ChangeText() = %q, want root-module changes without a module suffix", got)
+	}
+}
+
+func TestActionCountsString(t *testing.T) {
+	tests := []struct {
+		counts ActionCounts
+		want   string
+	}{
+		{ActionCounts{}, ""},
+		{ActionCounts{Create: 1}, "1 create"},
+		{ActionCounts{Replace: 2, Delete: 1, Create: 3}, "3 creates, 2 replaces, 1 delete"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.counts.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestClassifyCarriesProjectMetadata(t *testing.T) {
+	payments := project("prod/payments", true, true, false)
+	payments.Project.Name = "payments"
+	payments.Project.Workspace = "eu"
+	payments.Project.Owners = []string{"@payments-team"}
+	payments.Project.Severity = "high"
+	result := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{payments, project("infra/dns", true, true, false)},
+		TotalProjects:  2,
+	}
+
+	sum := Classify(result)
+
+	dns, named := sum.Drifted[0], sum.Drifted[1]
+	if named.Title() != "payments@eu" || named.Dir != "prod/payments@eu" {
+		t.Errorf("Title() = %q, Dir = %q, want the name and the key", named.Title(), named.Dir)
+	}
+	if got := named.MetadataText(); got != "owner: @payments-team, sev: high" {
+		t.Errorf("MetadataText() = %q", got)
+	}
+	if dns.Title() != "infra/dns" || dns.MetadataText() != "" {
+		t.Errorf("expected an unnamed project to show its key alone, got %q (%q)", dns.Title(), dns.MetadataText())
+	}
+}
+
+func TestMetadataTextListsEveryOwnerAndTag(t *testing.T) {
+	p := Project{Owners: []string{"@a", "@b"}, Tags: []string{"pci", "eu"}}
+	if got := p.MetadataText(); got != "owners: @a @b, tags: pci eu" {
+		t.Errorf("MetadataText() = %q", got)
+	}
+}
@@ -1,17 +1,12 @@
 package slack
 
 import (
-	"bytes"
 	"context"
 	"driftive/pkg/drift"
 	"driftive/pkg/models/backend"
+	"driftive/pkg/notification/post"
 	"driftive/pkg/notification/report"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"strings"
 
 	"github.com/rs/zerolog/log"
 )
@@ -65,38 +60,21 @@ type Slack struct {
 	// Repo is "owner/name" from the GitHub Actions context, used to identify the source
 	// repository and to build issue links. Empty outside GitHub Actions.
 	Repo string
-	// DriftIssues and ErrorIssues map a project dir to its open GitHub issue number. Nil when
+	// DriftIssues and ErrorIssues map a project key to its open GitHub issue number. Nil when
 	// GitHub issues are disabled, in which case rows render as plain text.
 	DriftIssues map[string]int
 	ErrorIssues map[string]int
 }
 
-// projectLine is one entry in a Slack project list.
-type projectLine struct {
-	Dir string
-	// URL links the dir to its GitHub issue. Empty renders the dir as plain code text.
-	URL string
-	// Note is an optional trailing parenthetical, such as the phase that failed.
-	Note string
-}
-
 func (slack Slack) Handle(ctx context.Context, driftResult drift.DriftDetectionResult) error {
 	summary := report.Classify(driftResult)
 
-	if !summary.HasFindings() && !didResolveIssues(slack.IssuesState) {
+	if !summary.ShouldNotify(slack.IssuesState) {
 		log.Info().Msg("No drifts or errors detected. Skipping slack notification")
 		return nil
 	}
 
-	message := slack.buildBlockKitMessage(summary)
-
-	jsonData, err := json.Marshal(message)
-	if err != nil {
-		log.Error().Msgf("failed to marshal slack message. %v", err)
-		return fmt.Errorf("failed to marshal slack message. %w", err)
-	}
-
-	return slack.sendMessage(ctx, jsonData)
+	return post.JSON(ctx, "slack", slack.Url, slack.buildBlockKitMessage(summary))
 }
 
 func (slack Slack) buildBlockKitMessage(summary report.Summary) slackMessage {
@@ -111,10 +89,10 @@ func (slack Slack) buildBlockKitMessage(summary report.Summary) slackMessage {
 
 	blocks = append(blocks, slackBlock{Type: "section", Fields: slack.statsFields(summary)})
 
-	if didResolveIssues(slack.IssuesState) {
+	if report.IssuesResolved(slack.IssuesState) {
 		blocks = append(blocks, slackBlock{
 			Type: "section",
-			Text: &slackTextObject{Type: "mrkdwn", Text: resolvedText(slack.IssuesState)},
+			Text: &slackTextObject{Type: "mrkdwn", Text: ":tada: " + report.Resolved(slack.IssuesState).Text(bold)},
 		})
 	}
 
@@ -122,22 +100,12 @@ func (slack Slack) buildBlockKitMessage(summary report.Summary) slackMessage {
 		blocks = append(blocks, slackBlock{Type: "divider"})
 	}
 
-	if summary.NumDrifted() > 0 {
+	for _, list := range summary.Lists(slack.issueLinks()) {
 		blocks = append(blocks, slackBlock{
 			Type: "section",
 			Text: &slackTextObject{
 				Type: "mrkdwn",
-				Text: slack.renderProjectList("*Drifted Projects:*", slack.driftedLines(summary), maxProjectListChars),
-			},
-		})
-	}
-
-	if summary.NumErrored() > 0 {
-		blocks = append(blocks, slackBlock{
-			Type: "section",
-			Text: &slackTextObject{
-				Type: "mrkdwn",
-				Text: slack.renderProjectList("*Failed Projects:*", slack.erroredLines(summary), maxProjectListChars),
+				Text: slack.renderProjectList("*"+list.Heading+":*", list.Lines, maxProjectListChars),
 			},
 		})
 	}
@@ -167,105 +135,60 @@ func (slack Slack) buildBlockKitMessage(summary report.Summary) slackMessage {
 			{
 				Color:    color,
 				Blocks:   blocks,
-				Fallback: slack.fallbackText(summary),
+				Fallback: summary.Synopsis(slack.Repo),
 			},
 		},
 	}
 }
 
 func (slack Slack) headline(summary report.Summary) (color string, header string) {
-	switch {
-	case summary.NumDrifted() > 0:
+	switch summary.Headline(slack.IssuesState) {
+	case report.HeadlineDrift:
 		return colorDanger, ":warning: Drift Detected"
-	case summary.NumErrored() > 0:
+	case report.HeadlineErrors:
 		return colorWarning, ":rotating_light: Analysis Errors"
-	case didResolveIssues(slack.IssuesState):
+	case report.HeadlineResolved:
 		return colorSuccess, ":white_check_mark: All Drifts Resolved"
 	}
 	return "", ""
 }
 
-// statsFields emits at most 4 fields, which Slack lays out two per row. Duration is always
-// last so the grid keeps a stable shape.
+// statsFields emits at most 4 fields, which Slack lays out two per row.
 func (slack Slack) statsFields(summary report.Summary) []slackTextObject {
-	fields := []slackTextObject{{
-		Type: "mrkdwn",
-		Text: fmt.Sprintf("*Drifted*\n%d / %d projects", summary.NumDrifted(), summary.TotalProjects),
-	}}
-
-	if summary.NumErrored() > 0 {
-		fields = append(fields, slackTextObject{
-			Type: "mrkdwn",
-			Text: fmt.Sprintf("*Errored*\n%d", summary.NumErrored()),
-		})
-	}
-	if summary.NumSkipped() > 0 {
-		fields = append(fields, slackTextObject{
-			Type: "mrkdwn",
-			Text: fmt.Sprintf("*Skipped*\n%d (open PR)", summary.NumSkipped()),
-		})
-	}
-
-	return append(fields, slackTextObject{
-		Type: "mrkdwn",
-		Text: fmt.Sprintf("*Duration*\n%s", summary.DurationText()),
-	})
-}
-
-func (slack Slack) driftedLines(summary report.Summary) []projectLine {
-	lines := make([]projectLine, 0, summary.NumDrifted())
-	for _, p := range summary.Drifted {
-		lines = append(lines, projectLine{Dir: p.Dir, URL: slack.issueURL(slack.DriftIssues, p.Dir)})
+	stats := summary.Stats()
+	fields := make([]slackTextObject, 0, len(stats))
+	for _, stat := range stats {
+		text := fmt.Sprintf("*%s*\n%s", stat.Name, stat.Value)
+		if stat.Detail != "" {
+			text += "\n" + stat.Detail
+		}
+		fields = append(fields, slackTextObject{Type: "mrkdwn", Text: text})
 	}
-	return lines
+	return fields
 }
 
-func (slack Slack) erroredLines(summary report.Summary) []projectLine {
-	lines := make([]projectLine, 0, summary.NumErrored())
-	for _, p := range summary.Errored {
-		line := projectLine{Dir: p.Dir, URL: slack.issueURL(slack.ErrorIssues, p.Dir)}
-		if p.FailedPhase != "" {
-			line.Note = p.FailedPhase
-		}
-		lines = append(lines, line)
-	}
-	return lines
+func bold(text string) string {
+	return "*" + text + "*"
 }
 
-func (slack Slack) issueURL(issues map[string]int, dir string) string {
-	if slack.Repo == "" {
-		return ""
-	}
-	number, ok := issues[dir]
-	if !ok || number <= 0 {
-		return ""
-	}
-	return fmt.Sprintf("https://github.com/%s/issues/%d", slack.Repo, number)
+func (slack Slack) issueLinks() report.IssueLinks {
+	return report.IssueLinks{Repo: slack.Repo, DriftIssues: slack.DriftIssues, ErrorIssues: slack.ErrorIssues}
 }
 
 // renderProjectList builds a section's text: a bold heading followed by one bullet per project,
 // stopping before budget bytes and appending a "…and N more" suffix.
-func (slack Slack) renderProjectList(heading string, lines []projectLine, budget int) string {
-	var out strings.Builder
-	out.WriteString(heading + "\n")
-
-	for i, line := range lines {
-		rendered := line.render()
-		suffix := slack.buildTruncationSuffix(len(lines) - i)
-		if out.Len()+len(rendered)+len(suffix) > budget {
-			out.WriteString(suffix)
-			break
-		}
-		out.WriteString(rendered)
-	}
-
-	return out.String()
+func (slack Slack) renderProjectList(heading string, lines []report.Line, budget int) string {
+	format := report.ListFormat{Line: renderLine, Truncated: slack.buildTruncationSuffix}
+	return format.Render(heading+"\n", lines, budget)
 }
 
-func (line projectLine) render() string {
-	label := "`" + line.Dir + "`"
+func renderLine(line report.Line) string {
+	label := "`" + line.Title + "`"
 	if line.URL != "" {
-		label = fmt.Sprintf("<%s|%s>", line.URL, line.Dir)
+		label = fmt.Sprintf("<%s|%s>", line.URL, line.Title)
+	}
+	if line.Metadata != "" {
+		label += " (" + line.Metadata + ")"
 	}
 	if line.Note != "" {
 		return fmt.Sprintf("• %s _(%s)_\n", label, line.Note)
@@ -280,84 +203,9 @@ func (slack Slack) contextText() string {
 	return fmt.Sprintf("<https://github.com/%s|%s> · Detected by Driftive", slack.Repo, slack.Repo)
 }
 
-// fallbackText is what push notifications and Block Kit-less clients show, so it carries the
-// repository as well as the counts.
-func (slack Slack) fallbackText(summary report.Summary) string {
-	var text string
-	switch {
-	case summary.NumDrifted() > 0 && summary.NumErrored() > 0:
-		text = fmt.Sprintf("Drift detected in %d project(s), %d failed to analyze",
-			summary.NumDrifted(), summary.NumErrored())
-	case summary.NumDrifted() > 0:
-		text = fmt.Sprintf("Drift detected in %d project(s)", summary.NumDrifted())
-	case summary.NumErrored() > 0:
-		text = fmt.Sprintf("%d project(s) failed to analyze", summary.NumErrored())
-	default:
-		text = "All drifts resolved"
-	}
-
-	if slack.Repo == "" {
-		return text
-	}
-	return fmt.Sprintf("[%s] %s", slack.Repo, text)
-}
-
-func (slack Slack) sendMessage(ctx context.Context, jsonData []byte) error {
-	httpClient := &http.Client{}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", slack.Url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		msg := fmt.Sprintf("failed to create slack request. %v", err)
-		log.Error().Msg(msg)
-		return errors.New(msg)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		msg := fmt.Sprintf("failed to send slack message. %v", err)
-		log.Error().Msg(msg)
-		return errors.New(msg)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			msg := fmt.Sprintf("failed to read response body. %v", err)
-			log.Error().Msg(msg)
-			return errors.New(msg)
-		}
-		msg := fmt.Sprintf("failed to send slack request. %v. Body: %s", resp.Status, string(body))
-		log.Error().Msg(msg)
-		return errors.New(msg)
-	}
-
-	return nil
-}
-
-func didResolveIssues(state *backend.DriftIssuesState) bool {
-	return state != nil && state.StateUpdated &&
-		(state.NumResolvedIssues > 0 || state.NumResolvedErrorIssues > 0)
-}
-
-func resolvedText(state *backend.DriftIssuesState) string {
-	drifts, errored := state.NumResolvedIssues, state.NumResolvedErrorIssues
-	switch {
-	case drifts > 0 && errored > 0:
-		return fmt.Sprintf(":tada: *%d issue(s)* and *%d error issue(s) resolved* since last analysis", drifts, errored)
-	case errored > 0:
-		return fmt.Sprintf(":tada: *%d error issue(s) resolved* since last analysis", errored)
-	}
-	return fmt.Sprintf(":tada: *%d issue(s) resolved* since last analysis", drifts)
-}
-
 func (slack Slack) buildTruncationSuffix(remaining int) string {
 	if remaining <= 0 {
 		return ""
 	}
-	if slack.DashboardURL != "" {
-		return fmt.Sprintf("_...and %d more project(s). View all in the dashboard._\n", remaining)
-	}
-	return fmt.Sprintf("_...and %d more project(s)_\n", remaining)
+	return "_" + report.TruncationText(remaining, slack.DashboardURL != "") + "_\n"
 }
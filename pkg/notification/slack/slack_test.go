@@ -47,49 +47,6 @@ func sectionContaining(message slackMessage, needle string) string {
 	return ""
 }
 
-func TestDidResolveIssues(t *testing.T) {
-	tests := []struct {
-		name     string
-		state    *backend.DriftIssuesState
-		expected bool
-	}{
-		{
-			name:     "nil state",
-			state:    nil,
-			expected: false,
-		},
-		{
-			name:     "state not updated",
-			state:    &backend.DriftIssuesState{StateUpdated: false, NumResolvedIssues: 5},
-			expected: false,
-		},
-		{
-			name:     "no resolved issues",
-			state:    &backend.DriftIssuesState{StateUpdated: true, NumResolvedIssues: 0},
-			expected: false,
-		},
-		{
-			name:     "has resolved issues",
-			state:    &backend.DriftIssuesState{StateUpdated: true, NumResolvedIssues: 3},
-			expected: true,
-		},
-		{
-			name:     "only error issues resolved",
-			state:    &backend.DriftIssuesState{StateUpdated: true, NumResolvedErrorIssues: 2},
-			expected: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := didResolveIssues(tt.state)
-			if got != tt.expected {
-				t.Errorf("didResolveIssues() = %v, want %v", got, tt.expected)
-			}
-		})
-	}
-}
-
 func TestBuildBlockKitMessage_WithDrifts(t *testing.T) {
 	slack := Slack{}
 	driftResult := drift.DriftDetectionResult{
@@ -239,6 +196,53 @@ func TestBuildBlockKitMessage_DriftsAndErrors(t *testing.T) {
 	}
 }
 
+func TestBuildBlockKitMessage_TimedOutProjects(t *testing.T) {
+	slack := Slack{}
+	timedOut := errored("infra/prod/eks", drift.PhasePlan)
+	timedOut.FailureReason = drift.ReasonTimeout
+	driftResult := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{timedOut, errored("modules/net", drift.PhaseInit)},
+		TotalProjects:  2,
+		Duration:       time.Minute,
+	}
+
+	message := build(slack, driftResult)
+
+	section := sectionContaining(message, "Timed Out Projects")
+	if !strings.Contains(section, "infra/prod/eks") || !strings.Contains(section, "_(during plan)_") {
+		t.Errorf("expected the timed out project with its phase:\n%s", section)
+	}
+	if failed := sectionContaining(message, "Failed Projects"); strings.Contains(failed, "infra/prod/eks") {
+		t.Errorf("timed out project should not be listed as failed:\n%s", failed)
+	}
+	stats := message.Attachments[0].Blocks[1].Fields[1].Text
+	if !strings.Contains(stats, "*Errored*\n2") || !strings.Contains(stats, "1 timed out") {
+		t.Errorf("unexpected errored field: %q", stats)
+	}
+}
+
+func TestBuildBlockKitMessage_BlockedProjects(t *testing.T) {
+	slack := Slack{}
+	blocked := errored("live/app", "")
+	blocked.FailureReason = drift.ReasonBlocked
+	blocked.BlockedBy = "live/vpc"
+	driftResult := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{blocked, errored("live/vpc", drift.PhasePlan)},
+		TotalProjects:  2,
+		Duration:       time.Minute,
+	}
+
+	message := build(slack, driftResult)
+
+	section := sectionContaining(message, "Blocked Projects")
+	if !strings.Contains(section, "`live/app` _(blocked by live/vpc)_") {
+		t.Errorf("expected the blocked project with its upstream:\n%s", section)
+	}
+	if failed := sectionContaining(message, "Failed Projects"); strings.Contains(failed, "live/app") {
+		t.Errorf("blocked project should not be listed as failed:\n%s", failed)
+	}
+}
+
 func TestBuildBlockKitMessage_ErroredProjectShowsPhase(t *testing.T) {
 	slack := Slack{}
 	driftResult := drift.DriftDetectionResult{
@@ -260,6 +264,31 @@ func TestBuildBlockKitMessage_ErroredProjectShowsPhase(t *testing.T) {
 	}
 }
 
+func TestBuildBlockKitMessage_DriftedProjectShowsResourceBreakdown(t *testing.T) {
+	slack := Slack{}
+	vpc := drifted("infra/prod/vpc")
+	vpc.DriftedResources = []drift.DriftedResource{
+		{Address: "module.vpc.aws_subnet.a", ModulePath: "module.vpc", Action: drift.ActionUpdate},
+		{Address: "module.vpc.aws_eip.nat", ModulePath: "module.vpc", Action: drift.ActionReplace},
+	}
+	driftResult := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{vpc},
+		TotalProjects:  1,
+		Duration:       time.Minute,
+	}
+
+	message := build(slack, driftResult)
+
+	list := sectionContaining(message, "Drifted Projects")
+	if !strings.Contains(list, "_(1 update, 1 replace in module.vpc)_") {
+		t.Errorf("expected the resource breakdown on the project line:\n%s", list)
+	}
+	stats := message.Attachments[0].Blocks[1].Fields[0].Text
+	if !strings.Contains(stats, "1 update, 1 replace") {
+		t.Errorf("expected the totals in the Drifted field, got %q", stats)
+	}
+}
+
 func TestBuildBlockKitMessage_ErroredProjectWithoutPhase(t *testing.T) {
 	slack := Slack{}
 	driftResult := drift.DriftDetectionResult{
@@ -416,8 +445,9 @@ func TestBuildBlockKitMessage_ResolvedWording(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := resolvedText(tt.state); got != tt.want {
-				t.Errorf("resolvedText() = %q, want %q", got, tt.want)
+			message := build(Slack{IssuesState: tt.state}, drift.DriftDetectionResult{TotalProjects: 1})
+			if got := sectionContaining(message, ":tada:"); got != tt.want {
+				t.Errorf("resolved section = %q, want %q", got, tt.want)
 			}
 		})
 	}
@@ -908,9 +938,9 @@ func TestRenderProjectList_IndependentBudgets(t *testing.T) {
 }
 
 func TestRenderProjectList_HonorsBudget(t *testing.T) {
-	lines := make([]projectLine, 0, 100)
+	lines := make([]report.Line, 0, 100)
 	for i := 0; i < 100; i++ {
-		lines = append(lines, projectLine{Dir: fmt.Sprintf("terraform/production/service-%03d", i)})
+		lines = append(lines, report.Line{Title: fmt.Sprintf("terraform/production/service-%03d", i)})
 	}
 
 	got := Slack{}.renderProjectList("*Drifted Projects:*", lines, 300)
@@ -935,3 +965,22 @@ func extractTruncatedCount(text string) string {
 	}
 	return rest[:spaceIdx]
 }
+
+func TestBuildBlockKitMessage_ShowsProjectNameAndMetadata(t *testing.T) {
+	slack := Slack{Repo: "acme/infra", DriftIssues: map[string]int{"prod/payments": 7}}
+	payments := drifted("prod/payments")
+	payments.Project.Name = "payments"
+	payments.Project.Owners = []string{"@payments-team"}
+	payments.Project.Severity = "high"
+	driftResult := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{payments},
+		TotalProjects:  1,
+		Duration:       time.Minute,
+	}
+
+	list := sectionContaining(build(slack, driftResult), "Drifted Projects")
+
+	if !strings.Contains(list, "<https://github.com/acme/infra/issues/7|payments> (owner: @payments-team, sev: high)") {
+		t.Errorf("expected the linked name followed by the metadata:\n%s", list)
+	}
+}
@@ -0,0 +1,200 @@
+// Package teams posts drift reports to a Microsoft Teams channel as an Adaptive Card.
+package teams
+
+import (
+	"context"
+	"driftive/pkg/drift"
+	"driftive/pkg/models/backend"
+	"driftive/pkg/notification/post"
+	"driftive/pkg/notification/report"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Adaptive Card text colors
+const (
+	colorAttention = "attention" // Red for drifts detected
+	colorWarning   = "warning"   // Orange for errors without drift
+	colorGood      = "good"      // Green for all resolved
+)
+
+// maxProjectListChars is the character budget for one project list. Teams rejects messages over
+// about 28KB, so each of the up to four lists is kept well under a quarter of that, leaving
+// headroom for the links and the rest of the card.
+const maxProjectListChars = 4000
+
+// Adaptive Card types
+type teamsElement struct {
+	Type      string      `json:"type"`
+	Text      string      `json:"text,omitempty"`
+	Size      string      `json:"size,omitempty"`
+	Weight    string      `json:"weight,omitempty"`
+	Color     string      `json:"color,omitempty"`
+	IsSubtle  bool        `json:"isSubtle,omitempty"`
+	Wrap      bool        `json:"wrap,omitempty"`
+	Separator bool        `json:"separator,omitempty"`
+	Spacing   string      `json:"spacing,omitempty"`
+	Facts     []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type teamsAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type teamsCard struct {
+	Schema       string         `json:"$schema"`
+	Type         string         `json:"type"`
+	Version      string         `json:"version"`
+	FallbackText string         `json:"fallbackText,omitempty"`
+	Body         []teamsElement `json:"body"`
+	Actions      []teamsAction  `json:"actions,omitempty"`
+	MSTeams      teamsCardWidth `json:"msteams"`
+}
+
+type teamsCardWidth struct {
+	Width string `json:"width"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type Teams struct {
+	Url          string
+	IssuesState  *backend.DriftIssuesState
+	DashboardURL string
+	// Repo is "owner/name" from the GitHub Actions context, used to identify the source
+	// repository and to build issue links. Empty outside GitHub Actions.
+	Repo string
+	// DriftIssues and ErrorIssues map a project key to its open GitHub issue number. Nil when
+	// GitHub issues are disabled, in which case rows render as plain text.
+	DriftIssues map[string]int
+	ErrorIssues map[string]int
+}
+
+func (teams Teams) Handle(ctx context.Context, driftResult drift.DriftDetectionResult) error {
+	summary := report.Classify(driftResult)
+
+	if !summary.ShouldNotify(teams.IssuesState) {
+		log.Info().Msg("No drifts or errors detected. Skipping teams notification")
+		return nil
+	}
+
+	return post.JSON(ctx, "teams", teams.Url, teams.buildMessage(summary))
+}
+
+func (teams Teams) buildMessage(summary report.Summary) teamsMessage {
+	color, headerText := teams.headline(summary)
+
+	body := []teamsElement{
+		{Type: "TextBlock", Text: headerText, Size: "Large", Weight: "Bolder", Color: color, Wrap: true},
+		{Type: "FactSet", Facts: teams.statsFacts(summary)},
+	}
+
+	if report.IssuesResolved(teams.IssuesState) {
+		body = append(body, teamsElement{Type: "TextBlock", Text: report.Resolved(teams.IssuesState).Text(bold), Wrap: true})
+	}
+
+	for _, list := range summary.Lists(teams.issueLinks()) {
+		body = append(body,
+			teamsElement{Type: "TextBlock", Text: list.Heading, Weight: "Bolder", Separator: true, Spacing: "Medium"},
+			teamsElement{Type: "TextBlock", Text: teams.renderProjectList(list.Lines, maxProjectListChars), Wrap: true, Spacing: "Small"},
+		)
+	}
+
+	body = append(body, teamsElement{Type: "TextBlock", Text: teams.contextText(), Size: "Small", IsSubtle: true,
+		Wrap: true, Separator: true})
+
+	var actions []teamsAction
+	if teams.DashboardURL != "" {
+		actions = append(actions, teamsAction{Type: "Action.OpenUrl", Title: "View in Dashboard", URL: teams.DashboardURL})
+	}
+
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content: teamsCard{
+					Schema:       "http://adaptivecards.io/schemas/adaptive-card.json",
+					Type:         "AdaptiveCard",
+					Version:      "1.4",
+					FallbackText: summary.Synopsis(teams.Repo),
+					Body:         body,
+					Actions:      actions,
+					MSTeams:      teamsCardWidth{Width: "Full"},
+				},
+			},
+		},
+	}
+}
+
+func (teams Teams) headline(summary report.Summary) (color string, header string) {
+	switch summary.Headline(teams.IssuesState) {
+	case report.HeadlineDrift:
+		return colorAttention, "Drift Detected"
+	case report.HeadlineErrors:
+		return colorWarning, "Analysis Errors"
+	case report.HeadlineResolved:
+		return colorGood, "All Drifts Resolved"
+	}
+	return "", ""
+}
+
+// statsFacts are one per row, so details go in parentheses on the same line.
+func (teams Teams) statsFacts(summary report.Summary) []teamsFact {
+	stats := summary.Stats()
+	facts := make([]teamsFact, 0, len(stats))
+	for _, stat := range stats {
+		value := stat.Value
+		if stat.Detail != "" {
+			value += " (" + stat.Detail + ")"
+		}
+		facts = append(facts, teamsFact{Title: stat.Name, Value: value})
+	}
+	return facts
+}
+
+func (teams Teams) issueLinks() report.IssueLinks {
+	return report.IssueLinks{Repo: teams.Repo, DriftIssues: teams.DriftIssues, ErrorIssues: teams.ErrorIssues}
+}
+
+// renderProjectList builds a markdown list with one bullet per project, stopping before budget
+// bytes and appending a "…and N more" suffix.
+func (teams Teams) renderProjectList(lines []report.Line, budget int) string {
+	format := report.ListFormat{Line: report.MarkdownLine, Truncated: teams.buildTruncationSuffix}
+	return strings.TrimSuffix(format.Render("", lines, budget), "\n")
+}
+
+func (teams Teams) contextText() string {
+	if teams.Repo == "" {
+		return "Detected by Driftive"
+	}
+	return fmt.Sprintf("[%s](https://github.com/%s) · Detected by Driftive", teams.Repo, teams.Repo)
+}
+
+func (teams Teams) buildTruncationSuffix(remaining int) string {
+	if remaining <= 0 {
+		return ""
+	}
+	return "_" + report.TruncationText(remaining, teams.DashboardURL != "") + "_\n"
+}
+
+func bold(text string) string {
+	return "**" + text + "**"
+}
@@ -0,0 +1,238 @@
+package teams
+
+import (
+	"context"
+	"driftive/pkg/drift"
+	"driftive/pkg/models"
+	"driftive/pkg/models/backend"
+	"driftive/pkg/notification/report"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func drifted(dir string) drift.DriftProjectResult {
+	return drift.DriftProjectResult{Project: models.TypedProject{Dir: dir}, Drifted: true, Succeeded: true}
+}
+
+func skipped(dir string) drift.DriftProjectResult {
+	return drift.DriftProjectResult{
+		Project: models.TypedProject{Dir: dir}, Drifted: true, Succeeded: true, SkippedDueToPR: true,
+	}
+}
+
+func clean(dir string) drift.DriftProjectResult {
+	return drift.DriftProjectResult{Project: models.TypedProject{Dir: dir}, Succeeded: true}
+}
+
+func errored(dir, phase string) drift.DriftProjectResult {
+	return drift.DriftProjectResult{Project: models.TypedProject{Dir: dir}, FailedPhase: phase}
+}
+
+func build(teams Teams, result drift.DriftDetectionResult) teamsCard {
+	return teams.buildMessage(report.Classify(result)).Attachments[0].Content
+}
+
+// textAfter returns the text of the block following the heading, i.e. a section's project list.
+func textAfter(card teamsCard, heading string) string {
+	for i, element := range card.Body {
+		if element.Text == heading && i+1 < len(card.Body) {
+			return card.Body[i+1].Text
+		}
+	}
+	return ""
+}
+
+func fact(card teamsCard, title string) string {
+	for _, element := range card.Body {
+		for _, f := range element.Facts {
+			if f.Title == title {
+				return f.Value
+			}
+		}
+	}
+	return ""
+}
+
+func TestBuildMessage_WithDriftsAndErrors(t *testing.T) {
+	teams := Teams{DashboardURL: "https://app.driftive.cloud/runs/1"}
+	card := build(teams, drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{
+			drifted("terraform/vpc"),
+			errored("terraform/db", drift.PhaseInit),
+			skipped("terraform/dns"),
+			clean("terraform/iam"),
+		},
+		TotalProjects: 4,
+		Duration:      90 * time.Second,
+	})
+
+	if card.Body[0].Text != "Drift Detected" || card.Body[0].Color != colorAttention {
+		t.Errorf("unexpected header %q (%s)", card.Body[0].Text, card.Body[0].Color)
+	}
+	if got := fact(card, "Drifted"); got != "1 / 4 projects" {
+		t.Errorf("Drifted fact = %q", got)
+	}
+	if got := fact(card, "Errored"); got != "1" {
+		t.Errorf("Errored fact = %q", got)
+	}
+	if got := fact(card, "Skipped"); got != "1 (open PR)" {
+		t.Errorf("Skipped fact = %q", got)
+	}
+	if got := fact(card, "Duration"); got != "1m30s" {
+		t.Errorf("Duration fact = %q", got)
+	}
+	if got := textAfter(card, "Drifted Projects"); got != "- terraform/vpc" {
+		t.Errorf("drifted list = %q", got)
+	}
+	if got := textAfter(card, "Failed Projects"); got != "- terraform/db _(init)_" {
+		t.Errorf("failed list = %q", got)
+	}
+	if len(card.Actions) != 1 || card.Actions[0].URL != teams.DashboardURL {
+		t.Errorf("expected a dashboard action, got %+v", card.Actions)
+	}
+	if card.FallbackText != "Drift detected in 1 project(s), 1 failed to analyze" {
+		t.Errorf("fallback = %q", card.FallbackText)
+	}
+}
+
+func TestBuildMessage_ErrorsOnly(t *testing.T) {
+	card := build(Teams{}, drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{errored("terraform/db", drift.PhasePlan)},
+		TotalProjects:  1,
+	})
+
+	if card.Body[0].Text != "Analysis Errors" || card.Body[0].Color != colorWarning {
+		t.Errorf("unexpected header %q (%s)", card.Body[0].Text, card.Body[0].Color)
+	}
+	if textAfter(card, "Drifted Projects") != "" {
+		t.Error("expected no drifted section")
+	}
+	if len(card.Actions) != 0 {
+		t.Errorf("expected no actions without a dashboard, got %+v", card.Actions)
+	}
+}
+
+func TestBuildMessage_AllResolved(t *testing.T) {
+	teams := Teams{IssuesState: &backend.DriftIssuesState{StateUpdated: true, NumResolvedIssues: 2, NumResolvedErrorIssues: 1}}
+	card := build(teams, drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{clean("a")}, TotalProjects: 1})
+
+	if card.Body[0].Text != "All Drifts Resolved" || card.Body[0].Color != colorGood {
+		t.Errorf("unexpected header %q (%s)", card.Body[0].Text, card.Body[0].Color)
+	}
+	if card.Body[2].Text != "**2 issue(s)** and **1 error issue(s) resolved** since last analysis" {
+		t.Errorf("unexpected resolved text %q", card.Body[2].Text)
+	}
+}
+
+func TestBuildMessage_LinksToIssuesAndRepo(t *testing.T) {
+	teams := Teams{
+		Repo:        "acme/infra",
+		DriftIssues: map[string]int{"terraform/vpc": 12},
+		ErrorIssues: map[string]int{"terraform/db": 13},
+	}
+	result := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{drifted("terraform/vpc"), errored("terraform/db", "")},
+		TotalProjects:  2,
+	}
+	result.ProjectResults[0].Project.Name = "vpc"
+	result.ProjectResults[0].Project.Owners = []string{"@net"}
+	card := build(teams, result)
+
+	if got := textAfter(card, "Drifted Projects"); got != "- [vpc](https://github.com/acme/infra/issues/12) (owner: @net)" {
+		t.Errorf("drifted list = %q", got)
+	}
+	if got := textAfter(card, "Failed Projects"); got != "- [terraform/db](https://github.com/acme/infra/issues/13)" {
+		t.Errorf("failed list = %q", got)
+	}
+	footer := card.Body[len(card.Body)-1].Text
+	if footer != "[acme/infra](https://github.com/acme/infra) · Detected by Driftive" {
+		t.Errorf("footer = %q", footer)
+	}
+	if !strings.HasPrefix(card.FallbackText, "[acme/infra] ") {
+		t.Errorf("fallback = %q", card.FallbackText)
+	}
+}
+
+func TestRenderProjectList_Truncates(t *testing.T) {
+	lines := make([]report.Line, 0, 200)
+	for i := range 200 {
+		lines = append(lines, report.Line{Title: fmt.Sprintf("terraform/project-%03d", i)})
+	}
+
+	text := Teams{DashboardURL: "https://app.driftive.cloud"}.renderProjectList(lines, 500)
+	if len(text) > 500 {
+		t.Errorf("list is %d bytes, over the 500 budget", len(text))
+	}
+	shown := strings.Count(text, "- terraform/")
+	if !strings.HasSuffix(text, fmt.Sprintf("_...and %d more project(s). View all in the dashboard._", 200-shown)) {
+		t.Errorf("unexpected truncation suffix:\n%s", text)
+	}
+}
+
+func TestHandle_SkipsWhenNothingToReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("should not send request when nothing drifted, errored or resolved")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	teams := Teams{Url: server.URL}
+	result := drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{clean("a"), skipped("b")},
+		TotalProjects:  2,
+	}
+	if err := teams.Handle(context.Background(), result); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHandle_SendsAdaptiveCard(t *testing.T) {
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	teams := Teams{Url: server.URL}
+	result := drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{drifted("terraform/vpc")}, TotalProjects: 1}
+	if err := teams.Handle(context.Background(), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var message map[string]any
+	if err := json.Unmarshal(receivedBody, &message); err != nil {
+		t.Fatalf("failed to unmarshal sent message: %v", err)
+	}
+	attachment := message["attachments"].([]any)[0].(map[string]any)
+	if attachment["contentType"] != "application/vnd.microsoft.card.adaptive" {
+		t.Errorf("contentType = %v", attachment["contentType"])
+	}
+	if card := attachment["content"].(map[string]any); card["type"] != "AdaptiveCard" {
+		t.Errorf("content type = %v", card["type"])
+	}
+}
+
+func TestHandle_ReturnsErrorOnBadStatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Bad payload received by generic incoming webhook."))
+	}))
+	defer server.Close()
+
+	teams := Teams{Url: server.URL}
+	result := drift.DriftDetectionResult{ProjectResults: []drift.DriftProjectResult{drifted("a")}, TotalProjects: 1}
+	err := teams.Handle(context.Background(), result)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("expected an error with the status code, got: %v", err)
+	}
+}
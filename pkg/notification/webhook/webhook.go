@@ -0,0 +1,201 @@
+// Package webhook posts every run's results to arbitrary HTTP endpoints, signed so receivers can
+// tell the requests come from driftive.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"driftive/pkg/drift"
+	"driftive/pkg/notification/post"
+	"driftive/pkg/notification/report"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"resty.dev/v3"
+)
+
+const (
+	// PayloadVersion is bumped when a field of Payload is removed or changes meaning. Adding a
+	// field does not bump it, so receivers should ignore fields they do not know.
+	PayloadVersion = 1
+	// EventAnalysisCompleted is the event of the payload sent once a run is over.
+	EventAnalysisCompleted = "drift_analysis.completed"
+
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of the body, keyed with
+	// the webhook secret. Absent when no secret is configured.
+	SignatureHeader = "X-Driftive-Signature-256"
+	EventHeader     = "X-Driftive-Event"
+	// DeliveryHeader identifies the run. It is the same on every URL and every retry, so
+	// receivers can drop duplicates.
+	DeliveryHeader = "X-Driftive-Delivery"
+)
+
+// Payload is the JSON document posted to each webhook.
+type Payload struct {
+	Version int                        `json:"version"`
+	Event   string                     `json:"event"`
+	Run     Run                        `json:"run"`
+	Result  drift.DriftDetectionResult `json:"result"`
+	// Summary buckets the results the way the Slack and Teams messages do.
+	Summary Summary `json:"summary"`
+}
+
+// Run describes the run the payload reports on.
+type Run struct {
+	ID string `json:"id"`
+	// Repository is "owner/name" from the GitHub Actions context. Empty outside GitHub Actions.
+	Repository   string    `json:"repository,omitempty"`
+	DashboardURL string    `json:"dashboard_url,omitempty"`
+	SentAt       time.Time `json:"sent_at"`
+}
+
+// Summary is report.Summary as sent to webhooks. Buckets are never null.
+type Summary struct {
+	TotalProjects int                 `json:"total_projects"`
+	NotChecked    int                 `json:"not_checked"`
+	Changes       report.ActionCounts `json:"changes"`
+	Drifted       []report.Project    `json:"drifted"`
+	Errored       []report.Project    `json:"errored"`
+	TimedOut      []report.Project    `json:"timed_out"`
+	Blocked       []report.Project    `json:"blocked"`
+	Skipped       []report.Project    `json:"skipped"`
+	Clean         []report.Project    `json:"clean"`
+}
+
+type Webhook struct {
+	URLs []string
+	// Secret keys the HMAC signature. Requests are sent unsigned when it is empty.
+	Secret string
+	// RunKey identifies this CLI run, sent as the delivery ID.
+	RunKey       string
+	Repo         string
+	DashboardURL string
+
+	// Retry waits, defaulted by NewWebhookNotification. Tests shorten them.
+	retryWaitTime    time.Duration
+	retryMaxWaitTime time.Duration
+}
+
+func NewWebhookNotification(urls []string, secret, runKey, repo, dashboardURL string) Webhook {
+	return Webhook{
+		URLs:             urls,
+		Secret:           secret,
+		RunKey:           runKey,
+		Repo:             repo,
+		DashboardURL:     dashboardURL,
+		retryWaitTime:    2 * time.Second,
+		retryMaxWaitTime: 15 * time.Second,
+	}
+}
+
+// Handle posts the run's payload to every URL, including for runs without drift, so receivers
+// get a complete history. Transient failures (network errors, 5xx, 429) are retried with
+// exponential backoff. A failing URL does not keep the others from being sent to; the returned
+// error joins the failures of all of them.
+func (w Webhook) Handle(ctx context.Context, driftResult drift.DriftDetectionResult) error {
+	body, err := json.Marshal(w.payload(driftResult))
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload. %w", err)
+	}
+
+	deliveryID := w.RunKey
+	if deliveryID == "" {
+		deliveryID = uuid.NewString()
+	}
+
+	// The delivery ID sent on every attempt is what makes retrying this POST safe.
+	client := post.NewRetryingClient(w.retryWaitTime, w.retryMaxWaitTime)
+	defer client.Close()
+
+	var errs []error
+	for _, target := range w.URLs {
+		if err := w.send(ctx, client, target, deliveryID, body); err != nil {
+			log.Error().Msgf("Failed to send webhook to %s. %v", redactURL(target), err)
+			errs = append(errs, fmt.Errorf("%s: %w", redactURL(target), err))
+			continue
+		}
+		log.Info().Msgf("Sent webhook to %s", redactURL(target))
+	}
+	return errors.Join(errs...)
+}
+
+func (w Webhook) send(ctx context.Context, client *resty.Client, target, deliveryID string, body []byte) error {
+	req := client.R().
+		WithContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeader(EventHeader, EventAnalysisCompleted).
+		SetHeader(DeliveryHeader, deliveryID).
+		SetHeader("Idempotency-Key", deliveryID).
+		SetBody(body)
+	if w.Secret != "" {
+		req.SetHeader(SignatureHeader, Sign(w.Secret, body))
+	}
+
+	res, err := req.Post(target)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode() < 200 || res.StatusCode() >= 300 {
+		return fmt.Errorf("status %d. Response: %s", res.StatusCode(), res.String())
+	}
+	return nil
+}
+
+func (w Webhook) payload(driftResult drift.DriftDetectionResult) Payload {
+	summary := report.Classify(driftResult)
+	return Payload{
+		Version: PayloadVersion,
+		Event:   EventAnalysisCompleted,
+		Run: Run{
+			ID:           w.RunKey,
+			Repository:   w.Repo,
+			DashboardURL: w.DashboardURL,
+			SentAt:       time.Now().UTC(),
+		},
+		Result: driftResult,
+		Summary: Summary{
+			TotalProjects: summary.TotalProjects,
+			NotChecked:    summary.NotChecked,
+			Changes:       summary.Changes,
+			Drifted:       nonNil(summary.Drifted),
+			Errored:       nonNil(summary.Errored),
+			TimedOut:      nonNil(summary.TimedOut),
+			Blocked:       nonNil(summary.Blocked),
+			Skipped:       nonNil(summary.Skipped),
+			Clean:         nonNil(summary.Clean),
+		},
+	}
+}
+
+// Sign returns the SignatureHeader value of body: "sha256=" followed by the hex HMAC-SHA256 of
+// body keyed with secret. Receivers compute the same over the raw request body and compare the
+// two in constant time.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func nonNil(projects []report.Project) []report.Project {
+	if projects == nil {
+		return []report.Project{}
+	}
+	return projects
+}
+
+// redactURL keeps the scheme and host of a webhook URL for logs. Its path and query often embed
+// a token.
+func redactURL(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return "webhook"
+	}
+	return u.Scheme + "://" + u.Host
+}
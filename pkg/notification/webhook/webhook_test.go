@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"driftive/pkg/drift"
+	"driftive/pkg/models"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func sampleResult() drift.DriftDetectionResult {
+	return drift.DriftDetectionResult{
+		ProjectResults: []drift.DriftProjectResult{
+			{Project: models.TypedProject{Dir: "infra/vpc"}, Drifted: true, Succeeded: true},
+			{Project: models.TypedProject{Dir: "infra/db"}, FailedPhase: drift.PhasePlan},
+		},
+		TotalProjects: 2,
+		Duration:      time.Minute,
+	}
+}
+
+// newTestWebhook keeps the retry backoff short so the retry tests do not sleep for seconds.
+func newTestWebhook(urls ...string) Webhook {
+	w := NewWebhookNotification(urls, "s3cret", "run-key", "acme/infra", "https://app.driftive.cloud/runs/1")
+	w.retryWaitTime = time.Millisecond
+	w.retryMaxWaitTime = 5 * time.Millisecond
+	return w
+}
+
+func TestHandle_SendsSignedPayload(t *testing.T) {
+	var (
+		headers http.Header
+		body    []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := newTestWebhook(server.URL).Handle(context.Background(), sampleResult()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got, want := headers.Get(SignatureHeader), Sign("s3cret", body); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if !strings.HasPrefix(headers.Get(SignatureHeader), "sha256=") {
+		t.Errorf("signature %q lacks the sha256= prefix", headers.Get(SignatureHeader))
+	}
+	if headers.Get(EventHeader) != EventAnalysisCompleted || headers.Get(DeliveryHeader) != "run-key" {
+		t.Errorf("unexpected event/delivery headers: %v", headers)
+	}
+	if headers.Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type = %q", headers.Get("Content-Type"))
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if payload.Version != PayloadVersion || payload.Event != EventAnalysisCompleted {
+		t.Errorf("unexpected version/event: %d %s", payload.Version, payload.Event)
+	}
+	if payload.Run.ID != "run-key" || payload.Run.Repository != "acme/infra" || payload.Run.DashboardURL == "" {
+		t.Errorf("unexpected run: %+v", payload.Run)
+	}
+	if len(payload.Result.ProjectResults) != 2 {
+		t.Errorf("expected the 2 project results, got %d", len(payload.Result.ProjectResults))
+	}
+	if len(payload.Summary.Drifted) != 1 || payload.Summary.Drifted[0].Dir != "infra/vpc" {
+		t.Errorf("unexpected drifted bucket: %+v", payload.Summary.Drifted)
+	}
+	if len(payload.Summary.Errored) != 1 || payload.Summary.Errored[0].FailedPhase != drift.PhasePlan {
+		t.Errorf("unexpected errored bucket: %+v", payload.Summary.Errored)
+	}
+}
+
+func TestHandle_EmptyBucketsAreArrays(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	if err := newTestWebhook(server.URL).Handle(context.Background(), drift.DriftDetectionResult{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var payload struct {
+		Summary map[string]any `json:"summary"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	for _, bucket := range []string{"drifted", "errored", "timed_out", "blocked", "skipped", "clean"} {
+		if _, ok := payload.Summary[bucket].([]any); !ok {
+			t.Errorf("expected summary.%s to be an array, got %v", bucket, payload.Summary[bucket])
+		}
+	}
+}
+
+func TestHandle_UnsignedWithoutSecret(t *testing.T) {
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(SignatureHeader)
+	}))
+	defer server.Close()
+
+	w := newTestWebhook(server.URL)
+	w.Secret = ""
+	if err := w.Handle(context.Background(), sampleResult()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if signature != "" {
+		t.Errorf("expected no signature, got %q", signature)
+	}
+}
+
+func TestHandle_RetriesWithSameDelivery(t *testing.T) {
+	var mu sync.Mutex
+	var deliveries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		deliveries = append(deliveries, r.Header.Get(DeliveryHeader))
+		if len(deliveries) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	if err := newTestWebhook(server.URL).Handle(context.Background(), sampleResult()); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if len(deliveries) != 2 || deliveries[0] != deliveries[1] {
+		t.Errorf("expected one retry with the same delivery ID, got %v", deliveries)
+	}
+}
+
+func TestHandle_FailingURLDoesNotStopOthers(t *testing.T) {
+	var requests int
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("bad payload"))
+	}))
+	defer failing.Close()
+	var received bool
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer working.Close()
+
+	err := newTestWebhook(failing.URL+"/hooks/token", working.URL).Handle(context.Background(), sampleResult())
+	if err == nil || !strings.Contains(err.Error(), "bad payload") {
+		t.Errorf("expected the failing URL's error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "/hooks/token") {
+		t.Errorf("expected the URL path to be redacted, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected a 400 not to be retried, got %d requests", requests)
+	}
+	if !received {
+		t.Error("expected the second URL to still be sent to")
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Known HMAC-SHA256 of "hello" keyed with "key".
+	want := "sha256=9307b3b915efb5171ff14d8cb55fbcc798c6c0ef1456d66ded1a6aa723a58b7b"
+	if got := Sign("key", []byte("hello")); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
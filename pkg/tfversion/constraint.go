@@ -0,0 +1,118 @@
+package tfversion
+
+import (
+	"fmt"
+	"strings"
+)
+
+// operators in the order they are tried, so ">=" is not read as ">".
+var operators = []string{"~>", ">=", "<=", "!=", "=", ">", "<"}
+
+type condition struct {
+	op      string
+	version Version
+}
+
+// Constraint is a terraform version constraint such as ">= 1.3, < 2.0" or "~> 1.9". A version
+// must satisfy every condition.
+type Constraint struct {
+	conditions []condition
+	text       string
+}
+
+// ParseConstraint parses the comma-separated conditions of a required_version string. A bare
+// version means "= version".
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{text: strings.TrimSpace(s)}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return Constraint{}, fmt.Errorf("invalid version constraint %q", s)
+		}
+		op := "="
+		for _, candidate := range operators {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(strings.TrimPrefix(part, candidate))
+				break
+			}
+		}
+		v, err := ParseVersion(part)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		c.conditions = append(c.conditions, condition{op: op, version: v})
+	}
+	return c, nil
+}
+
+// And combines two constraints, e.g. the required_version of several files.
+func (c Constraint) And(o Constraint) Constraint {
+	text := c.text
+	if text != "" && o.text != "" {
+		text += ", "
+	}
+	return Constraint{
+		conditions: append(append([]condition{}, c.conditions...), o.conditions...),
+		text:       text + o.text,
+	}
+}
+
+// Check reports whether v satisfies the constraint. As in terraform, a prerelease only
+// satisfies a constraint that names it exactly.
+func (c Constraint) Check(v Version) bool {
+	if v.prerelease != "" && !c.namesExactly(v) {
+		return false
+	}
+	for _, cond := range c.conditions {
+		if !cond.check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Constraint) namesExactly(v Version) bool {
+	for _, cond := range c.conditions {
+		if cond.op == "=" && cond.version.Compare(v) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (cond condition) check(v Version) bool {
+	cmp := v.Compare(cond.version)
+	switch cond.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case "~>":
+		return cmp >= 0 && v.Compare(cond.version.pessimisticBound()) < 0
+	default:
+		return false
+	}
+}
+
+// pessimisticBound is the exclusive upper bound of ~>: the second-to-last written segment is
+// bumped, so ~> 1.3 stops at 2.0 and ~> 1.3.0 stops at 1.4.0.
+func (v Version) pessimisticBound() Version {
+	bound := Version{specified: v.specified}
+	idx := max(0, v.specified-2)
+	copy(bound.segments[:idx], v.segments[:idx])
+	bound.segments[idx] = v.segments[idx] + 1
+	return bound
+}
+
+func (c Constraint) String() string {
+	return c.text
+}
@@ -0,0 +1,61 @@
+package tfversion
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Installation is one installed binary.
+type Installation struct {
+	Version Version
+	Path    string
+}
+
+// Installed lists the versions of tool under binariesDir, laid out as
+// <binariesDir>/<tool>/<version>/<tool>, e.g. binaries/terraform/1.3.9/terraform. Entries that
+// are not a version or lack the binary are skipped.
+func Installed(binariesDir string, tool Tool) ([]Installation, error) {
+	toolDir := filepath.Join(binariesDir, string(tool))
+	entries, err := os.ReadDir(toolDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	binary := string(tool)
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+
+	installed := make([]Installation, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		version, err := ParseVersion(entry.Name())
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(toolDir, entry.Name(), binary)
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		installed = append(installed, Installation{Version: version, Path: path})
+	}
+	return installed, nil
+}
+
+// Select returns the highest installed version satisfying c.
+func Select(installed []Installation, c Constraint) (Installation, bool) {
+	var best Installation
+	found := false
+	for _, candidate := range installed {
+		if c.Check(candidate.Version) && (!found || candidate.Version.Compare(best.Version) > 0) {
+			best, found = candidate, true
+		}
+	}
+	return best, found
+}
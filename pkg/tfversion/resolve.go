@@ -0,0 +1,179 @@
+package tfversion
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Tool is a binary a project can pin the version of.
+type Tool string
+
+const (
+	Terraform Tool = "terraform"
+	Tofu      Tool = "tofu"
+)
+
+// versionFiles are the tfenv/tofuenv style files holding one version.
+var versionFiles = map[Tool]string{
+	Terraform: ".terraform-version",
+	Tofu:      ".opentofu-version",
+}
+
+// toolVersionsNames are the tools' names in an asdf .tool-versions file.
+var toolVersionsNames = map[Tool]string{
+	Terraform: "terraform",
+	Tofu:      "opentofu",
+}
+
+// requiredVersionPattern matches required_version = "..." inside a terraform block. A regexp
+// rather than an HCL parser: the attribute is always a plain string literal.
+var requiredVersionPattern = regexp.MustCompile(`(?m)^\s*required_version\s*=\s*"([^"]*)"`)
+
+// errMinRequired is the tfenv keyword deferring to required_version.
+var errMinRequired = errors.New("min-required")
+
+// Requirement is the version a project asks for.
+type Requirement struct {
+	Tool       Tool
+	Constraint Constraint
+	// Source is the file the requirement was read from.
+	Source string
+}
+
+// Find returns the version the project in dir asks for, looking for, in order:
+//   - a version file (.terraform-version, .opentofu-version) or a .tool-versions entry, in dir
+//     and then in each parent up to root, the nearest one winning
+//   - the required_version of the *.tf files in dir
+//
+// tools lists the tools the project may run, preferred first; terragrunt projects pass both.
+// found is false when the project pins nothing.
+func Find(dir, root string, tools ...Tool) (req Requirement, found bool, err error) {
+	req, found, err = findVersionFile(dir, root, tools)
+	if found || (err != nil && !errors.Is(err, errMinRequired)) {
+		return req, found, err
+	}
+	return findRequiredVersion(dir, tools[0])
+}
+
+func findVersionFile(dir, root string, tools []Tool) (Requirement, bool, error) {
+	dir, root = filepath.Clean(dir), filepath.Clean(root)
+	for {
+		if !within(root, dir) {
+			return Requirement{}, false, nil
+		}
+		for _, tool := range tools {
+			path := filepath.Join(dir, versionFiles[tool])
+			content, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			req, err := parseVersionSpec(tool, firstLine(string(content)), path)
+			return req, err == nil, err
+		}
+		if req, found, err := findToolVersions(filepath.Join(dir, ".tool-versions"), tools); found || err != nil {
+			return req, found, err
+		}
+
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir {
+			return Requirement{}, false, nil
+		}
+		dir = parent
+	}
+}
+
+// within reports whether dir is root or below it. A sibling sharing root's name as a prefix,
+// such as /work/repo2 for /work/repo, is not.
+func within(root, dir string) bool {
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// findToolVersions reads the first version of the first of tools listed in an asdf
+// .tool-versions file.
+func findToolVersions(path string, tools []Tool) (Requirement, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Requirement{}, false, nil
+	}
+	defer file.Close()
+
+	entries := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) >= 2 {
+			entries[fields[0]] = fields[1]
+		}
+	}
+	for _, tool := range tools {
+		if spec, ok := entries[toolVersionsNames[tool]]; ok {
+			req, err := parseVersionSpec(tool, spec, path)
+			return req, err == nil, err
+		}
+	}
+	return Requirement{}, false, nil
+}
+
+// parseVersionSpec reads a version file entry: a version, a constraint, or the tfenv keywords
+// latest and min-required.
+func parseVersionSpec(tool Tool, spec, source string) (Requirement, error) {
+	switch {
+	case spec == "" || spec == "latest":
+		return Requirement{Tool: tool, Source: source}, nil
+	case spec == "min-required":
+		return Requirement{}, errMinRequired
+	case strings.HasPrefix(spec, "latest:"):
+		return Requirement{}, fmt.Errorf("%s: unsupported version %q", source, spec)
+	}
+	constraint, err := ParseConstraint(spec)
+	if err != nil {
+		return Requirement{}, fmt.Errorf("%s: %w", source, err)
+	}
+	return Requirement{Tool: tool, Constraint: constraint, Source: source}, nil
+}
+
+// findRequiredVersion combines the required_version constraints of the *.tf files in dir.
+func findRequiredVersion(dir string, tool Tool) (Requirement, bool, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil || len(files) == 0 {
+		return Requirement{}, false, nil
+	}
+
+	req := Requirement{Tool: tool}
+	found := false
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, match := range requiredVersionPattern.FindAllStringSubmatch(string(content), -1) {
+			constraint, err := ParseConstraint(match[1])
+			if err != nil {
+				return Requirement{}, false, fmt.Errorf("%s: %w", file, err)
+			}
+			req.Constraint = req.Constraint.And(constraint)
+			if !found {
+				req.Source = file
+			}
+			found = true
+		}
+	}
+	return req, found, nil
+}
+
+func firstLine(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,185 @@
+package tfversion
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		files    map[string]string
+		tools    []Tool
+		wantTool Tool
+		want     string
+		found    bool
+		// dir is the project dir relative to the repository root, stacks/app when empty.
+		dir string
+	}{
+		{
+			name:     "version file in the project",
+			files:    map[string]string{"stacks/app/.terraform-version": "1.3.9\n"},
+			tools:    []Tool{Terraform},
+			wantTool: Terraform, want: "1.3.9", found: true,
+		},
+		{
+			name:     "nearest version file wins",
+			files:    map[string]string{".terraform-version": "1.9.0", "stacks/.terraform-version": "1.5.7"},
+			tools:    []Tool{Terraform},
+			wantTool: Terraform, want: "1.5.7", found: true,
+		},
+		{
+			name:     "tool-versions",
+			files:    map[string]string{".tool-versions": "nodejs 20.1.0\nopentofu 1.6.2 1.6.1\n"},
+			tools:    []Tool{Tofu},
+			wantTool: Tofu, want: "1.6.2", found: true,
+		},
+		{
+			name:     "version file beats required_version",
+			files:    map[string]string{"stacks/app/.terraform-version": "1.3.9", "stacks/app/main.tf": `terraform { required_version = "~> 1.9" }`},
+			tools:    []Tool{Terraform},
+			wantTool: Terraform, want: "1.3.9", found: true,
+		},
+		{
+			name: "required_version of every file",
+			files: map[string]string{
+				"stacks/app/main.tf":     "terraform {\n  required_version = \">= 1.3\"\n}\n",
+				"stacks/app/versions.tf": "terraform {\n  required_version = \"< 1.6\"\n}\n",
+			},
+			tools:    []Tool{Terraform},
+			wantTool: Terraform, want: ">= 1.3, < 1.6", found: true,
+		},
+		{
+			name:     "min-required defers to required_version",
+			files:    map[string]string{"stacks/app/.terraform-version": "min-required", "stacks/app/main.tf": "terraform {\n  required_version = \"~> 1.9\"\n}\n"},
+			tools:    []Tool{Terraform},
+			wantTool: Terraform, want: "~> 1.9", found: true,
+		},
+		{
+			name:     "terragrunt follows the opentofu version file",
+			files:    map[string]string{"stacks/.opentofu-version": "1.8.0"},
+			tools:    []Tool{Terraform, Tofu},
+			wantTool: Tofu, want: "1.8.0", found: true,
+		},
+		{
+			name:  "files outside the repository are ignored",
+			files: map[string]string{"../.terraform-version": "1.3.9"},
+			tools: []Tool{Terraform},
+		},
+		{
+			name:  "sibling directories sharing the root's prefix are outside it",
+			files: map[string]string{"../repo2/.terraform-version": "1.3.9"},
+			dir:   "../repo2/stacks/app",
+			tools: []Tool{Terraform},
+		},
+		{
+			name:  "nothing pinned",
+			files: map[string]string{"stacks/app/main.tf": `resource "null_resource" "a" {}`},
+			tools: []Tool{Terraform},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			root := filepath.Join(t.TempDir(), "repo")
+			for path, content := range tt.files {
+				writeFile(t, filepath.Join(root, path), content)
+			}
+			dir := filepath.Join(root, "stacks", "app")
+			if tt.dir != "" {
+				dir = filepath.Join(root, tt.dir)
+			}
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			req, found, err := Find(dir, root, tt.tools...)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if found != tt.found {
+				t.Fatalf("found = %v, want %v", found, tt.found)
+			}
+			if !found {
+				return
+			}
+			if req.Tool != tt.wantTool || req.Constraint.String() != tt.want {
+				t.Errorf("Find() = %s %q, want %s %q", req.Tool, req.Constraint, tt.wantTool, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindInvalidVersionFile(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".terraform-version"), "latest:^1.3")
+
+	if _, _, err := Find(root, root, Terraform); err == nil {
+		t.Error("Find() should fail on an unsupported version")
+	}
+}
+
+func TestInstalledAndSelect(t *testing.T) {
+	binaries := t.TempDir()
+	binary := "terraform"
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	for _, version := range []string{"1.3.9", "1.5.7", "1.9.8", "1.10.0-rc1"} {
+		writeFile(t, filepath.Join(binaries, "terraform", version, binary), "")
+	}
+	// Not a version, and a version without its binary.
+	writeFile(t, filepath.Join(binaries, "terraform", "latest", binary), "")
+	writeFile(t, filepath.Join(binaries, "terraform", "1.11.0", "README"), "")
+
+	installed, err := Installed(binaries, Terraform)
+	if err != nil {
+		t.Fatalf("Installed() error = %v", err)
+	}
+	if len(installed) != 4 {
+		t.Fatalf("Installed() = %v, want 4 versions", installed)
+	}
+
+	for _, tt := range []struct {
+		constraint, want string
+	}{
+		{"~> 1.3.0", "1.3.9"},
+		{"< 1.9", "1.5.7"},
+		{">= 1.3", "1.9.8"},
+		{"", "1.9.8"},
+		{"1.10.0-rc1", "1.10.0-rc1"},
+		{"~> 1.4.0", ""},
+	} {
+		var c Constraint
+		if tt.constraint != "" {
+			c, _ = ParseConstraint(tt.constraint)
+		}
+		got, ok := Select(installed, c)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("Select(%q) = %s, want no match", tt.constraint, got.Version)
+			}
+			continue
+		}
+		if !ok || got.Version.String() != tt.want {
+			t.Errorf("Select(%q) = %s, want %s", tt.constraint, got.Version, tt.want)
+		}
+		if filepath.Base(got.Path) != binary {
+			t.Errorf("Select(%q).Path = %s", tt.constraint, got.Path)
+		}
+	}
+
+	if none, err := Installed(binaries, Tofu); err != nil || len(none) != 0 {
+		t.Errorf("Installed(tofu) = %v, %v, want none", none, err)
+	}
+}
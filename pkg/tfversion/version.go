@@ -0,0 +1,69 @@
+// Package tfversion finds the terraform or tofu version a project asks for and picks a matching
+// binary among the installed ones.
+package tfversion
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a major.minor.patch version with an optional prerelease, e.g. 1.9.0-beta1.
+type Version struct {
+	segments [3]int
+	// specified is how many segments were written, which ~> needs: ~> 1.3 allows 1.x while
+	// ~> 1.3.0 only allows 1.3.x.
+	specified  int
+	prerelease string
+}
+
+// ParseVersion parses "1.3", "1.3.9", "v1.3.9" or "1.9.0-beta1".
+func ParseVersion(s string) (Version, error) {
+	raw := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	core, prerelease, _ := strings.Cut(raw, "-")
+	parts := strings.Split(core, ".")
+	if core == "" || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	v := Version{specified: len(parts), prerelease: prerelease}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		v.segments[i] = n
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 as v is lower than, equal to or higher than o. A prerelease is
+// lower than its release.
+func (v Version) Compare(o Version) int {
+	for i := range v.segments {
+		if v.segments[i] != o.segments[i] {
+			if v.segments[i] < o.segments[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case v.prerelease == o.prerelease:
+		return 0
+	case v.prerelease == "":
+		return 1
+	case o.prerelease == "":
+		return -1
+	default:
+		return strings.Compare(v.prerelease, o.prerelease)
+	}
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.segments[0], v.segments[1], v.segments[2])
+	if v.prerelease != "" {
+		s += "-" + v.prerelease
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package tfversion
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "1.3.9", want: "1.3.9"},
+		{in: "v1.9.0", want: "1.9.0"},
+		{in: "1.3", want: "1.3.0"},
+		{in: "1.9.0-beta1", want: "1.9.0-beta1"},
+		{in: "", wantErr: true},
+		{in: "1.x", wantErr: true},
+		{in: "1.2.3.4", wantErr: true},
+	} {
+		got, err := ParseVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseVersion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got.String() != tt.want {
+			t.Errorf("ParseVersion(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.3.9", "1.3.9", 0},
+		{"1.3.9", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.9.0-beta1", "1.9.0", -1},
+		{"1.9.0-beta2", "1.9.0-beta1", 1},
+	} {
+		a, _ := ParseVersion(tt.a)
+		b, _ := ParseVersion(tt.b)
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestConstraintCheck(t *testing.T) {
+	for _, tt := range []struct {
+		constraint, version string
+		want                bool
+	}{
+		{"1.3.9", "1.3.9", true},
+		{"= 1.3.9", "1.3.10", false},
+		{">= 1.3, < 2.0", "1.9.8", true},
+		{">= 1.3, < 2.0", "2.0.0", false},
+		{"~> 1.3", "1.9.0", true},
+		{"~> 1.3", "2.0.0", false},
+		{"~> 1.3.0", "1.3.7", true},
+		{"~> 1.3.0", "1.4.0", false},
+		{"~> 1", "1.12.0", true},
+		{"!= 1.5.0", "1.5.0", false},
+		{">1.5.0", "1.5.1", true},
+		{">= 1.6", "1.7.0-rc1", false},
+		{"1.7.0-rc1", "1.7.0-rc1", true},
+	} {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q) error = %v", tt.constraint, err)
+		}
+		v, _ := ParseVersion(tt.version)
+		if got := c.Check(v); got != tt.want {
+			t.Errorf("%q.Check(%s) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseConstraintRejectsGarbage(t *testing.T) {
+	for _, in := range []string{">= one", "1.3,", "~>"} {
+		if _, err := ParseConstraint(in); err == nil {
+			t.Errorf("ParseConstraint(%q) should fail", in)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package utils
+
+import "strings"
+
+// IsPartOfCacheFolder reports whether path is in a terraform or terragrunt cache folder, whose
+// copies of the code are never projects of their own.
+func IsPartOfCacheFolder(path string) bool {
+	return strings.Contains(path, ".terragrunt-cache") || strings.Contains(path, ".terraform")
+}
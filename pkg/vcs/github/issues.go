@@ -97,7 +97,7 @@ func (g *GHOps) CreateOrUpdateIssue(
 			if issue.Body == driftiveIssue.Body {
 				log.Info().Msgf("Issue [%s] already exists for project %s (repo: %s/%s)",
 					driftiveIssue.Kind,
-					driftiveIssue.Project.Dir,
+					driftiveIssue.Project.Key(),
 					ownerRepo[0],
 					ownerRepo[1])
 				return vcstypes.CreateOrUpdateResult{
@@ -126,7 +126,7 @@ func (g *GHOps) CreateOrUpdateIssue(
 
 				log.Info().Msgf("Updated issue [%s] for project %s (repo: %s/%s)",
 					driftiveIssue.Kind,
-					driftiveIssue.Project.Dir,
+					driftiveIssue.Project.Key(),
 					ownerRepo[0],
 					ownerRepo[1])
 
@@ -142,7 +142,7 @@ func (g *GHOps) CreateOrUpdateIssue(
 	if updateOnly {
 		log.Warn().Msgf("Max number of open issues reached. Skipping issue [%s] creation for project %s (repo: %s/%s)",
 			driftiveIssue.Kind,
-			driftiveIssue.Project.Dir,
+			driftiveIssue.Project.Key(),
 			ownerRepo[0],
 			ownerRepo[1])
 		return vcstypes.CreateOrUpdateResult{
@@ -162,10 +162,14 @@ func (g *GHOps) CreateOrUpdateIssue(
 		Body:   &driftiveIssue.Body,
 		Labels: &ghLabels,
 	}
+	if len(driftiveIssue.Assignees) > 0 {
+		assignees := driftiveIssue.Assignees
+		issue.Assignees = &assignees
+	}
 
 	log.Info().Msgf("Creating issue [%s] for project %s (repo: %s/%s)",
 		driftiveIssue.Kind,
-		driftiveIssue.Project.Dir,
+		driftiveIssue.Project.Key(),
 		ownerRepo[0],
 		ownerRepo[1])
 
@@ -175,6 +179,17 @@ func (g *GHOps) CreateOrUpdateIssue(
 		ownerRepo[1],
 		issue)
 
+	// GitHub rejects the whole issue when an assignee cannot be assigned, e.g. a CODEOWNERS user
+	// without access to the repository, so the issue is created unassigned instead.
+	if err != nil && issue.Assignees != nil {
+		log.Warn().Msgf("Failed to create issue assigned to %v, retrying unassigned. %v", driftiveIssue.Assignees, err)
+		issue.Assignees = nil
+		createdIssue, _, err = g.ghClient.Issues.Create(
+			ctx,
+			ownerRepo[0],
+			ownerRepo[1],
+			issue)
+	}
 	if err != nil {
 		log.Error().Msgf("Failed to create issue. %v", err)
 	}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"driftive/pkg/config"
+	"driftive/pkg/config/repo"
+	"fmt"
+	"io"
+	"os"
+)
+
+// runValidate checks the repository config, the files it extends and the driftive.yml fragments
+// of the repository's subdirectories, and prints every problem in them, exiting with 1 when there
+// is any.
+func runValidate(cfg *config.ValidateConfig) {
+	if cfg.PrintSchema {
+		schema, err := repo.JSONSchema()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "driftive validate: %v\n", err)
+			os.Exit(1)
+		}
+		_, _ = os.Stdout.Write(schema)
+		return
+	}
+
+	valid, err := validateRepository(os.Stdout, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "driftive validate: %v\n", err)
+		os.Exit(1)
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// validateRepository validates the repository config and the fragments, writing the problems to
+// w, and reports whether there are none. Without a root config, the fragments are checked on
+// their own, as a run applies them over the default config; an error is only returned when
+// there are no fragments either.
+func validateRepository(w io.Writer, cfg *config.ValidateConfig) (bool, error) {
+	path := cfg.ConfigFile
+	if path == "" {
+		path = repo.FindRepoConfig(cfg.RepositoryPath)
+	}
+	var problems []repo.Problem
+	if path != "" {
+		var err error
+		if problems, err = repo.ValidateFile(path); err != nil {
+			return false, err
+		}
+	}
+	fragments, fragmentProblems := repo.ValidateDirectories(cfg.RepositoryPath, path)
+	if path == "" {
+		if fragments == 0 {
+			return false, fmt.Errorf("no driftive.yml or driftive.yaml in %s", cfg.RepositoryPath)
+		}
+		// Every problem is in a fragment, so the repository only names what was validated.
+		path = cfg.RepositoryPath
+	}
+	problems = append(problems, fragmentProblems...)
+	writeProblems(w, path, problems)
+	return len(problems) == 0, nil
+}
+
+// writeProblems prints problems as <file>:<line>:<column>: <path>: <message>, the position
+// being omitted when unknown, or a single line saying path is valid. A problem without a file is
+// in path.
+func writeProblems(w io.Writer, path string, problems []repo.Problem) {
+	if len(problems) == 0 {
+		fmt.Fprintf(w, "%s is valid\n", path)
+		return
+	}
+	for _, p := range problems {
+		position := p.File
+		if position == "" {
+			position = path
+		}
+		if p.Line > 0 {
+			position += fmt.Sprintf(":%d", p.Line)
+			if p.Column > 0 {
+				position += fmt.Sprintf(":%d", p.Column)
+			}
+		}
+		if p.Path != "" {
+			fmt.Fprintf(w, "%s: %s: %s\n", position, p.Path, p.Message)
+		} else {
+			fmt.Fprintf(w, "%s: %s\n", position, p.Message)
+		}
+	}
+	fmt.Fprintf(w, "%d problem(s) found\n", len(problems))
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"driftive/pkg/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateRepositoryWithOnlyFragments(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "infra", "driftive.yml"), "executable: tofu\n")
+
+	var out bytes.Buffer
+	valid, err := validateRepository(&out, &config.ValidateConfig{RepositoryPath: dir})
+	if err != nil || !valid {
+		t.Fatalf("validateRepository() = %v, %v, want a valid repository\n%s", valid, err, out.String())
+	}
+
+	writeTestFile(t, filepath.Join(dir, "apps", "driftive.yml"), "executable: terrafrom\n")
+	out.Reset()
+	valid, err = validateRepository(&out, &config.ValidateConfig{RepositoryPath: dir})
+	if err != nil || valid {
+		t.Fatalf("validateRepository() = %v, %v, want the invalid fragment reported", valid, err)
+	}
+	if !strings.Contains(out.String(), filepath.Join("apps", "driftive.yml")+":1:1: executable") {
+		t.Errorf("output = %q, want the fragment's problem", out.String())
+	}
+}
+
+func TestValidateRepositoryWithoutAnyConfig(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := validateRepository(&out, &config.ValidateConfig{RepositoryPath: t.TempDir()}); err == nil {
+		t.Error("validateRepository() should fail without a driftive.yml or fragments")
+	}
+}